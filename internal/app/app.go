@@ -3,19 +3,34 @@ package app
 import (
 	"context"
 	"fmt"
+	"html/template"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"time"
 
 	"shorty/internal/config"
 	"shorty/internal/repository"
 	"shorty/internal/service"
+	"shorty/pkg/assets"
 	"shorty/pkg/db"
 	"shorty/pkg/event"
 	"shorty/pkg/jwt"
 	"shorty/pkg/logger"
 	"shorty/pkg/middleware"
+	"shorty/pkg/req"
+	"shorty/pkg/shortcode"
+	"shorty/pkg/urlpolicy"
+	"shorty/pkg/view"
+	"shorty/web"
 )
 
 type App struct {
 	Server *Server
+	Views  *view.Renderer
+	// ReloadViews включает перечитывание шаблонов при изменении (режим разработки).
+	ReloadViews bool
 }
 
 func NewApp(cfg *config.Config) (*App, error) {
@@ -36,26 +51,208 @@ func NewApp(cfg *config.Config) (*App, error) {
 	linkRepository := repository.NewLinkRepository(db)
 	userRepository := repository.NewUserRepository(db)
 	statRepository := repository.NewStatRepository(db)
+	lockoutRepository := repository.NewLockoutRepository(db)
+	sessionRepository := repository.NewSessionRepository(db)
+	auditRepository := repository.NewAuditRepository(db)
+	reportRepository := repository.NewReportRepository(db)
+	campaignRepository := repository.NewCampaignRepository(db)
+	folderRepository := repository.NewFolderRepository(db)
+	tagRepository := repository.NewTagRepository(db)
+	urlRejectionRepository := repository.NewURLRejectionRepository(db)
+
+	// Политика адресов назначения.
+	policy, err := urlpolicy.New(urlpolicy.Config{
+		AllowedSchemes: cfg.URLPolicy.AllowedSchemes,
+		BlockPrivate:   cfg.URLPolicy.BlockPrivate,
+		ResolveHosts:   cfg.URLPolicy.ResolveHosts,
+		ResolveTimeout: cfg.URLPolicy.ResolveTimeout,
+		BlocklistPath:  cfg.URLPolicy.BlocklistFile,
+		AllowlistPath:  cfg.URLPolicy.AllowlistFile,
+		SelfHosts:      cfg.URLPolicy.SelfHosts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the URL policy: %w", err)
+	}
+	// Правило urlpolicy в теле запроса проверяет схему и синтаксис адреса по политике;
+	// полная проверка с DNS и списками доменов выполняется в URLPolicyService.
+	if err := req.RegisterValidation("urlpolicy", func(value string) bool {
+		return policy.CheckSyntax(value) == nil
+	}); err != nil {
+		return nil, fmt.Errorf("Failed to register the urlpolicy validator: %w", err)
+	}
+	req.MaxBodyBytes = cfg.MaxBodyBytes
+
+	var resolver *urlpolicy.Resolver
+	if cfg.URLPolicy.FollowRedirects {
+		resolver = urlpolicy.NewResolver(urlpolicy.ResolverConfig{
+			MaxHops:      cfg.URLPolicy.MaxRedirects,
+			Timeout:      cfg.URLPolicy.RedirectTimeout,
+			AllowPrivate: cfg.URLPolicy.AllowPrivate,
+			SelfHosts:    cfg.URLPolicy.SelfHosts,
+			UserAgent:    "shorty-link-resolver",
+		})
+	}
+
+	// Генератор коротких кодов ссылок.
+	codes, err := newCodePool(cfg.ShortCode, linkRepository)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to set up short code generation: %w", err)
+	}
 
 	// Сервисы.
-	linkService := service.NewLinkService(linkRepository)
-	userService := service.NewUserService(userRepository)
+	auditService := service.NewAuditService(auditRepository)
+	urlPolicyService := service.NewURLPolicyService(&service.URLPolicyServiceDeps{
+		Policy:   policy,
+		Resolver: resolver,
+		Repo:     urlRejectionRepository,
+		Audit:    auditService,
+	})
+	linkService := service.NewLinkService(&service.LinkServiceDeps{
+		Repo:        linkRepository,
+		Audit:       auditService,
+		Policy:      urlPolicyService,
+		Codes:       codes,
+		MaxAttempts: cfg.ShortCode.MaxAttempts,
+		Campaigns:   campaignRepository,
+		Folders:     folderRepository,
+		Tags:        tagRepository,
+
+		Dedupe:          cfg.Links.Dedupe,
+		StripTracking:   cfg.Links.StripTracking,
+		SubstringSearch: cfg.Links.SubstringSearch,
+	})
+	campaignService := service.NewCampaignService(campaignRepository)
+	folderService := service.NewFolderService(folderRepository, tagRepository)
+	bulkService := service.NewBulkService(&service.BulkServiceDeps{
+		Links:    linkService,
+		SyncRows: cfg.Bulk.SyncRows,
+		JobTTL:   cfg.Bulk.JobTTL,
+		MaxJobs:  cfg.Bulk.MaxJobs,
+	})
+	userService := service.NewUserService(userRepository, auditService)
 	authService := service.NewAuthService(userRepository)
-	statService := service.NewStatService(&service.StatServiceDeps{EventBus: eventBus, Repo: statRepository})
+	reportService := service.NewReportService(&service.ReportServiceDeps{
+		Config:      cfg,
+		Repo:        reportRepository,
+		LinkService: linkService,
+		UserService: userService,
+	})
+	statService := service.NewStatService(&service.StatServiceDeps{
+		EventBus:          eventBus,
+		Repo:              statRepository,
+		AttributionWindow: cfg.Tracking.AttributionWindow,
+	})
+	loginGuard := service.NewLoginGuardService(lockoutRepository, cfg.LoginGuard)
+	sessionService := service.NewSessionService(sessionRepository, userRepository, cfg.Session)
 	jwtService := jwt.NewJWT(cfg.Auth.Secret)
+	qrLogo, err := loadImage(cfg.QR.LogoPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the QR code logo: %w", err)
+	}
+	qrService := service.NewQRService(&service.QRServiceDeps{Logo: qrLogo, CacheSize: cfg.QR.CacheSize})
+
+	// Шаблоны и статика: в режиме разработки с диска, иначе встроенные в бинарник.
+	templatesFS, staticFS := web.Templates(), web.Static()
+	if cfg.Web.Dev {
+		templatesFS, staticFS = os.DirFS("web/templates"), os.DirFS("web/static")
+	}
+	staticAssets, err := assets.New(staticFS, assets.Options{Prefix: "/static/", Dev: cfg.Web.Dev})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load static files: %w", err)
+	}
+	views, err := view.New(templatesFS, view.Options{
+		Layouts: []string{"layout.html", "header.html", "links_table.html"},
+		Funcs:   template.FuncMap{"asset": staticAssets.Path},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse templates: %w", err)
+	}
 
 	// Промежуточное ПО.
 	stack := middleware.Chain(
+		logger.RequestIDMiddleware,
+		middleware.ClientIP(cfg),
 		middleware.CORS,
 		middleware.Logging,
+		middleware.Session(cfg, sessionService),
+		middleware.Locale(cfg),
+		middleware.CSRF,
 	)
 
 	// Создаём сервер с обработчиками.
-	server := NewServer(cfg, stack, authService, eventBus, linkService, statService, userService, jwtService)
+	server := NewServer(cfg, stack, ServerDeps{
+		AuthService: authService,
+		EventBus:    eventBus,
+		LinkService: linkService,
+		StatService: statService,
+		UserService: userService,
+		LoginGuard:  loginGuard,
+		Sessions:    sessionService,
+		Audit:       auditService,
+		Reports:     reportService,
+		Campaigns:   campaignService,
+		Folders:     folderService,
+		Bulk:        bulkService,
+		URLPolicy:   urlPolicyService,
+		QRService:   qrService,
+		JWTService:  jwtService,
+		RateLimits:  middleware.NewMemoryStore(),
+		Views:       views,
+		Assets:      staticAssets,
+	})
+	// Короткая ссылка не может занять путь, который обрабатывает сам сервер.
+	if err := req.ReserveAliases(server.Prefixes...); err != nil {
+		return nil, fmt.Errorf("Failed to register the alias validator: %w", err)
+	}
 
-	return &App{Server: server}, nil
+	return &App{Server: server, Views: views, ReloadViews: cfg.Web.Dev}, nil
 }
 
 func (a *App) Run(ctx context.Context) error {
+	if a.ReloadViews {
+		go a.Views.Watch(ctx, time.Second)
+	}
 	return a.Server.Start(ctx)
 }
+
+// loadImage читает картинку PNG или JPEG; без пути картинки нет.
+func loadImage(path string) (image.Image, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// newCodePool собирает пул коротких кодов по настройкам: случайные коды
+// или номера из последовательности в базе, перемешанные с солью.
+func newCodePool(cfg config.ShortCodeConfig, links *repository.LinkRepository) (*shortcode.Pool, error) {
+	alphabet, err := shortcode.Alphabet(cfg.Alphabet)
+	if err != nil {
+		return nil, err
+	}
+	var generator shortcode.Generator
+	switch cfg.Generator {
+	case "", "random":
+		generator, err = shortcode.NewRandom(alphabet)
+	case "sequence":
+		generator, err = shortcode.NewHashids(shortcode.SequenceFunc(links.NextCodeNumber), alphabet, cfg.Salt)
+	default:
+		err = fmt.Errorf("unknown short code generator %q", cfg.Generator)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return shortcode.NewPool(shortcode.PoolConfig{
+		Generator: generator,
+		Counter:   links,
+		Length:    cfg.Length,
+		MaxLength: cfg.MaxLength,
+		Threshold: cfg.GrowThreshold,
+	}), nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// routes регистрирует маршруты в ServeMux и запоминает их первые сегменты
+// пути: короткая ссылка с таким кодом перекрывалась бы маршрутом.
+type routes struct {
+	*http.ServeMux
+	prefixes map[string]struct{}
+}
+
+func newRoutes() *routes {
+	return &routes{ServeMux: http.NewServeMux(), prefixes: make(map[string]struct{})}
+}
+
+func (r *routes) Handle(pattern string, handler http.Handler) {
+	r.record(pattern)
+	r.ServeMux.Handle(pattern, handler)
+}
+
+func (r *routes) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.record(pattern)
+	r.ServeMux.HandleFunc(pattern, handler)
+}
+
+// record выделяет первый сегмент из шаблона вида "[METHOD ][HOST]/path".
+// Корень и сегменты-переменные ничего не резервируют.
+func (r *routes) record(pattern string) {
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(rest, " \t")
+	}
+	i := strings.IndexByte(pattern, '/')
+	if i < 0 {
+		return
+	}
+	segment, _, _ := strings.Cut(pattern[i+1:], "/")
+	if segment == "" || strings.HasPrefix(segment, "{") {
+		return
+	}
+	r.prefixes[segment] = struct{}{}
+}
+
+// Prefixes возвращает первые сегменты всех зарегистрированных маршрутов.
+func (r *routes) Prefixes() []string {
+	prefixes := make([]string, 0, len(r.prefixes))
+	for p := range r.prefixes {
+		prefixes = append(prefixes, p)
+	}
+	slices.Sort(prefixes)
+	return prefixes
+}
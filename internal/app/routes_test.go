@@ -0,0 +1,39 @@
+package app
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"shorty/internal/config"
+)
+
+func TestRoutesPrefixes(t *testing.T) {
+	r := newRoutes()
+	noop := func(http.ResponseWriter, *http.Request) {}
+	for _, pattern := range []string{
+		"/",
+		"/{$}",
+		"/signin",
+		"/static/",
+		"POST /logout",
+		"GET /report/{hash}",
+		"PATCH  /users/me/links/{id}",
+		"GET example.com/admin/dashboard",
+	} {
+		r.HandleFunc(pattern, noop)
+	}
+	want := []string{"admin", "logout", "report", "signin", "static", "users"}
+	if got := r.Prefixes(); !slices.Equal(got, want) {
+		t.Errorf("Prefixes() = %v, want %v", got, want)
+	}
+}
+
+func TestServerReservesRoutePrefixes(t *testing.T) {
+	s := NewServer(&config.Config{}, func(h http.Handler) http.Handler { return h }, ServerDeps{})
+	for _, prefix := range []string{"signin", "signup", "stats", "settings", "links", "logout", "static", "admin", "t"} {
+		if !slices.Contains(s.Prefixes, prefix) {
+			t.Errorf("route prefix %q is not reserved; prefixes %v", prefix, s.Prefixes)
+		}
+	}
+}
@@ -6,66 +6,155 @@ import (
 	"net/http"
 	"time"
 
+	"shorty/internal/common"
 	"shorty/internal/config"
 	"shorty/internal/handler"
 	"shorty/internal/service"
 	"shorty/pkg/event"
 	"shorty/pkg/jwt"
 	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
 )
 
 type Server struct {
 	httpServer *http.Server
+	// Prefixes - первые сегменты путей всех маршрутов сервера.
+	Prefixes []string
 }
 
-func NewServer(
-	cfg *config.Config,
-	middleware func(http.Handler) http.Handler,
-	authService *service.AuthService,
-	eventBus *event.EventBus,
-	linkService *service.LinkService,
-	statService *service.StatService,
-	userService service.UserServ,
-	jwtService *jwt.JWT,
-) *Server {
-	router := http.NewServeMux()
+// ServerDeps - сервисы, необходимые обработчикам сервера.
+type ServerDeps struct {
+	AuthService *service.AuthService
+	EventBus    *event.EventBus
+	LinkService *service.LinkService
+	StatService *service.StatService
+	UserService service.UserServ
+	LoginGuard  service.LoginGuardServ
+	Sessions    service.SessionServ
+	Audit       service.AuditServ
+	Reports     service.ReportServ
+	Campaigns   service.CampaignServ
+	Folders     service.FolderServ
+	Bulk        service.BulkServ
+	URLPolicy   service.URLPolicyServ
+	QRService   service.QRServ
+	JWTService  *jwt.JWT
+	RateLimits  middleware.RateLimitStore
+	Views       handler.Renderer
+	Assets      http.Handler
+}
+
+func NewServer(cfg *config.Config, stack func(http.Handler) http.Handler, deps ServerDeps) *Server {
+	router := newRoutes()
+
+	// Страницы, в том числе страницы ошибок для других обработчиков.
+	pageH := handler.NewPageHandler(handler.PageHandlerDeps{
+		Config:      cfg,
+		Views:       deps.Views,
+		LinkService: deps.LinkService,
+		StatService: deps.StatService,
+		UserService: deps.UserService,
+		Sessions:    deps.Sessions,
+		EventBus:    deps.EventBus,
+	})
 
 	// Обработчики.
 	handler.NewAdminHandler(router, handler.AdminHandlerDeps{
 		Config:      cfg,
-		UserService: userService,
-		LinkService: linkService,
-		StatService: statService,
-		JWTService:  jwtService,
+		UserService: deps.UserService,
+		LinkService: deps.LinkService,
+		StatService: deps.StatService,
+		LoginGuard:  deps.LoginGuard,
+		Audit:       deps.Audit,
+		Reports:     deps.Reports,
+		URLPolicy:   deps.URLPolicy,
+		JWTService:  deps.JWTService,
 	})
 	handler.NewAuthHandler(router, handler.AuthHandlerDeps{
 		Config:      cfg,
-		AuthService: authService,
+		AuthService: deps.AuthService,
+		LoginGuard:  deps.LoginGuard,
+		Sessions:    deps.Sessions,
 	})
 	handler.NewUserHandler(router, handler.UserHandlerDeps{
 		Config:      cfg,
-		UserService: userService,
-		LinkService: linkService,
-		EventBus:    eventBus,
+		UserService: deps.UserService,
+		LinkService: deps.LinkService,
+		StatService: deps.StatService,
+		QRService:   deps.QRService,
+		EventBus:    deps.EventBus,
+		ErrorPages:  pageH,
+	})
+
+	handler.NewCampaignHandler(router, handler.CampaignHandlerDeps{
+		Config:          cfg,
+		CampaignService: deps.Campaigns,
+		StatService:     deps.StatService,
+	})
+
+	handler.NewFolderHandler(router, handler.FolderHandlerDeps{
+		Config:        cfg,
+		FolderService: deps.Folders,
+	})
+
+	handler.NewBulkHandler(router, handler.BulkHandlerDeps{
+		Config:      cfg,
+		BulkService: deps.Bulk,
+	})
+
+	handler.NewReportHandler(router, handler.ReportHandlerDeps{
+		Config:        cfg,
+		ReportService: deps.Reports,
+	})
+
+	handler.NewTrackingHandler(router, handler.TrackingHandlerDeps{
+		Config:      cfg,
+		LinkService: deps.LinkService,
+		StatService: deps.StatService,
 	})
 
 	// Статика
-	router.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
+	router.Handle("/static/", http.StripPrefix("/static/", deps.Assets))
 
 	// Обработчики страниц
-	pageH := handler.NewPageHandler(jwtService, linkService)
 	router.HandleFunc("/", pageH.HomePage)
 	router.HandleFunc("/signin", pageH.LoginPage)
 	router.HandleFunc("/signup", pageH.RegisterPage)
 	router.HandleFunc("/stats", pageH.StatsPage)
 	router.HandleFunc("/settings", pageH.SettingsPage)
+	router.HandleFunc("POST /logout", pageH.Logout)
+	router.HandleFunc("GET /report/{hash}", pageH.ReportPage)
+
+	// Управление ссылками в веб-интерфейсе
+	router.HandleFunc("GET /links", pageH.MyLinksPage)
+	router.HandleFunc("POST /links/{id}/update", pageH.UpdateLinkForm)
+	router.HandleFunc("POST /links/{id}/block", pageH.BlockLinkForm)
+	router.HandleFunc("POST /links/{id}/unblock", pageH.UnblockLinkForm)
+	router.HandleFunc("POST /links/{id}/delete", pageH.DeleteLinkForm)
+	router.HandleFunc("GET /admin/dashboard", pageH.AdminDashboardPage)
+	router.HandleFunc("POST /admin/dashboard/users/{id}/block", pageH.BlockUserForm)
+	router.HandleFunc("POST /admin/dashboard/users/{id}/unblock", pageH.UnblockUserForm)
+
+	// Ограничение частоты запросов: политика выбирается по маршруту, который обработает запрос.
+	rateLimit := middleware.RateLimit(middleware.RateLimitDeps{
+		Config: cfg,
+		Store:  deps.RateLimits,
+		JWT:    deps.JWTService,
+		Route: func(r *http.Request) string {
+			_, pattern := router.Handler(r)
+			return pattern
+		},
+		OnLimit: func(w http.ResponseWriter, r *http.Request) {
+			pageH.Error(w, r, common.ErrTooManyRequests)
+		},
+	})
 
 	server := &http.Server{
 		Addr:    ":8080",
-		Handler: middleware(router),
+		Handler: stack(rateLimit(router)),
 	}
 
-	return &Server{httpServer: server}
+	return &Server{httpServer: server, Prefixes: router.Prefixes()}
 }
 
 func (s *Server) Start(ctx context.Context) error {
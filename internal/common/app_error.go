@@ -0,0 +1,129 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+
+	"shorty/pkg/i18n"
+)
+
+// Kind - класс ошибки приложения, по нему выбирается HTTP-статус ответа.
+type Kind uint8
+
+const (
+	KindInternal        Kind = iota // непредвиденный сбой, детали не раскрываются клиенту
+	KindBadRequest                  // запрос не удалось разобрать
+	KindValidation                  // запрос разобран, но данные некорректны
+	KindUnauthorized                // нужна аутентификация
+	KindForbidden                   // действие запрещено
+	KindNotFound                    // объект не найден
+	KindConflict                    // состояние объекта не допускает действие
+	KindGone                        // объект больше недоступен
+	KindTooLarge                    // тело запроса больше допустимого
+	KindTooManyRequests             // превышен лимит запросов
+)
+
+// kindStatus - HTTP-статус для каждого класса ошибок.
+var kindStatus = map[Kind]int{
+	KindInternal:        http.StatusInternalServerError,
+	KindBadRequest:      http.StatusBadRequest,
+	KindValidation:      http.StatusUnprocessableEntity,
+	KindUnauthorized:    http.StatusUnauthorized,
+	KindForbidden:       http.StatusForbidden,
+	KindNotFound:        http.StatusNotFound,
+	KindConflict:        http.StatusConflict,
+	KindGone:            http.StatusGone,
+	KindTooLarge:        http.StatusRequestEntityTooLarge,
+	KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// Status возвращает HTTP-статус для класса ошибки.
+func (k Kind) Status() int {
+	if status, ok := kindStatus[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError - ошибка конкретного поля запроса. Code - ключ каталога
+// "field.<code>" с пояснением для клиента.
+type FieldError struct {
+	Field string
+	Code  string
+	Param string // параметр правила, например минимальная длина
+}
+
+// AppError - типизированная ошибка приложения: класс, стабильный код
+// (он же ключ перевода в pkg/i18n), ошибки полей и исходная причина.
+// Сервисы возвращают AppError, обработчики передают её в res.ERROR.
+type AppError struct {
+	Kind   Kind
+	Code   string
+	Fields []FieldError
+	Err    error // причина для журнала, клиенту не показывается
+}
+
+// NewError создаёт ошибку приложения заданного класса.
+func NewError(kind Kind, code string) *AppError {
+	return &AppError{Kind: kind, Code: code}
+}
+
+// Error возвращает сообщение на языке по умолчанию вместе с причиной.
+func (e *AppError) Error() string {
+	msg := i18n.T(i18n.DefaultLocale, e.Code)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap возвращает причину ошибки.
+func (e *AppError) Unwrap() error { return e.Err }
+
+// Is сравнивает ошибки по коду, поэтому копии из Wrap и WithField
+// совпадают с исходной ошибкой в errors.Is.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap возвращает копию ошибки с причиной err.
+func (e *AppError) Wrap(err error) *AppError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithField возвращает копию ошибки с добавленной ошибкой поля.
+func (e *AppError) WithField(field, code, param string) *AppError {
+	c := *e
+	c.Fields = append(append([]FieldError(nil), e.Fields...), FieldError{Field: field, Code: code, Param: param})
+	return &c
+}
+
+// WithFieldPrefix возвращает копию ошибки, в которой пути полей начинаются
+// с prefix: ошибка поля "url" вложенного объекта становится "rules[0].url".
+func (e *AppError) WithFieldPrefix(prefix string) *AppError {
+	c := *e
+	c.Fields = make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		f.Field = prefix + "." + f.Field
+		c.Fields[i] = f
+	}
+	return &c
+}
+
+// AsAppError достаёт ошибку приложения из цепочки. Любая другая ошибка
+// считается внутренней и оборачивается в ErrInternal.
+func AsAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// KindOf возвращает класс ошибки; для ошибок без класса - KindInternal.
+func KindOf(err error) Kind {
+	return AsAppError(err).Kind
+}
@@ -0,0 +1,18 @@
+package common
+
+import "context"
+
+type contextKey string
+
+const clientIPContextKey contextKey = "clientIP"
+
+// WithClientIP сохраняет IP-адрес клиента в контексте запроса.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey, ip)
+}
+
+// ClientIPFromContext возвращает IP-адрес клиента из контекста запроса.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey).(string)
+	return ip
+}
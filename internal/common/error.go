@@ -2,42 +2,94 @@ package common
 
 import "errors"
 
+// Ошибки API несут класс и стабильный код; текст сообщения берётся из
+// каталога pkg/i18n на языке запроса.
 var (
 	// Общие ошибки
-	ErrInvalidID        = errors.New("некорректный идентификатор")
-	ErrInvalidLimit     = errors.New("Неверный лимит ввода")
-	ErrInvalidOffset    = errors.New("Неверное смещение ввода")
-	ErrURLNotFound      = errors.New("URL-адрес не найден")
-	ErrInvalidParam     = errors.New("неверный параметр")
-	ErrRequestBodyParse = errors.New("не удалось обработать тело запроса")
-	ErrMethodNotAllowed = errors.New("метод не поддерживается")
-	ErrInvalidRequest   = errors.New("некорректный запрос")
-	ErrNotFound         = errors.New("ошибка поиска")
+	ErrInvalidID        = NewError(KindBadRequest, "invalid_id")
+	ErrInvalidLimit     = NewError(KindBadRequest, "invalid_limit")
+	ErrInvalidOffset    = NewError(KindBadRequest, "invalid_offset")
+	ErrURLNotFound      = NewError(KindNotFound, "url_not_found")
+	ErrInvalidParam     = NewError(KindBadRequest, "invalid_param")
+	ErrRequestBodyParse = NewError(KindBadRequest, "invalid_body")
+	ErrBodyTooLarge     = NewError(KindTooLarge, "body_too_large")
+	ErrInvalidRequest   = NewError(KindValidation, "invalid_request")
+	ErrNotFound         = NewError(KindNotFound, "not_found")
+	ErrTooManyRequests  = NewError(KindTooManyRequests, "too_many_requests")
+	ErrInternal         = NewError(KindInternal, "internal_error")
 
 	// Ошибки авторизации
-	ErrBadRequest             = errors.New("неверный формат URL")
-	ErrUserRegistrationFailed = errors.New("ошибка регистрации пользователя")
-	ErrAuthFailed             = errors.New("ошибка при авторизации")
-	ErrUnauthorized           = errors.New("ощибка нет авторизации")
-	ErrInvalidToken           = errors.New("ощибка не валидный токен")
-	ErrForbidden              = errors.New("ощибка доступ запрещён")
+	ErrBadRequest             = NewError(KindBadRequest, "invalid_url")
+	ErrUserRegistrationFailed = NewError(KindInternal, "registration_failed")
+	ErrEmailTaken             = NewError(KindConflict, "email_taken")
+	ErrAuthFailed             = NewError(KindInternal, "auth_failed")
+	ErrUnauthorized           = NewError(KindUnauthorized, "unauthorized")
+	ErrInvalidToken           = NewError(KindUnauthorized, "invalid_token")
+	ErrForbidden              = NewError(KindForbidden, "forbidden")
 	UserContextKey            = errors.New("ощибка используй ключ")
+	ErrInvalidCredentials     = NewError(KindUnauthorized, "invalid_credentials")
+	ErrTooManyLoginAttempts   = NewError(KindTooManyRequests, "too_many_login_attempts")
+	ErrLockoutNotFound        = NewError(KindNotFound, "lockout_not_found")
+	ErrInvalidCSRFToken       = NewError(KindForbidden, "invalid_csrf_token")
+	ErrSessionNotFound        = NewError(KindUnauthorized, "session_not_found")
+	ErrSessionExpired         = NewError(KindUnauthorized, "session_expired")
 
 	// Ошибки пользователя.
-	ErrorGetUsers       = errors.New("не удалось получить список пользователей")
-	ErrUserNotFound     = errors.New("пользователь не найден")
-	ErrUserUpdateFailed = errors.New("не удалось обновить пользователя")
-	ErrUserDeleteFailed = errors.New("не удалось удалить пользователя")
-	ErrInternal         = errors.New("ошибка при получении количества заблокированных пользователей")
+	ErrorGetUsers         = NewError(KindInternal, "users_list_failed")
+	ErrUserNotFound       = NewError(KindNotFound, "user_not_found")
+	ErrInvalidUserData    = NewError(KindValidation, "invalid_user_data")
+	ErrUserUpdateFailed   = NewError(KindInternal, "user_update_failed")
+	ErrUserDeleteFailed   = NewError(KindInternal, "user_delete_failed")
+	ErrUserBlockFailed    = NewError(KindInternal, "user_block_failed")
+	ErrUserUnblockFailed  = NewError(KindInternal, "user_unblock_failed")
+	ErrUserAlreadyBlocked = NewError(KindConflict, "user_already_blocked")
+	ErrUserNotBlocked     = NewError(KindConflict, "user_not_blocked")
 
 	// Ошибки ссылок.
-	ErrLinkCreateUR         = errors.New("не удалось создать сокращённый URL")
-	ErrLinkNotFound         = errors.New("ссылка с таким идентификатором не найдена")
-	ErrLinkHashNotProvided  = errors.New("hash не указан")
-	ErrLinkUpdateLinkFailed = errors.New("ошибка при обновлении ссылки")
-	ErrLinkDeleteFailed     = errors.New("ошибка при удаления ссылки")
-	ErrLinkBlockFailed      = errors.New("ошибка при попытке заблокировать ссылку")
-	ErrUnBlockFailed        = errors.New("ошибка при попытке разблокировать ссылку")
-
-	ErrClickWriteFailed = errors.New("ошибка записи при клике")
+	ErrLinkCreateUR         = NewError(KindInternal, "link_create_failed")
+	ErrLinkNotFound         = NewError(KindNotFound, "link_not_found")
+	ErrLinkHashNotProvided  = NewError(KindBadRequest, "link_hash_missing")
+	ErrLinkUpdateLinkFailed = NewError(KindInternal, "link_update_failed")
+	ErrLinkHashTaken        = NewError(KindConflict, "link_hash_taken")
+	ErrLinkDeleteFailed     = NewError(KindInternal, "link_delete_failed")
+	ErrLinkBlockFailed      = NewError(KindInternal, "link_block_failed")
+	ErrUnBlockFailed        = NewError(KindInternal, "link_unblock_failed")
+	ErrLinkBlocked          = NewError(KindForbidden, "link_blocked")
+	ErrLinkExpired          = NewError(KindGone, "link_expired")
+	ErrURLRejected          = NewError(KindValidation, "url_rejected")
+	ErrInvalidDomain        = NewError(KindValidation, "invalid_domain")
+	ErrUnknownDomainList    = NewError(KindNotFound, "unknown_domain_list")
+
+	ErrClickWriteFailed = NewError(KindInternal, "click_write_failed")
+	ErrConversionFailed = NewError(KindInternal, "conversion_failed")
+	ErrClickNotFound    = NewError(KindNotFound, "click_not_found")
+	ErrQRFailed         = NewError(KindInternal, "qr_failed")
+	ErrQRLogoMissing    = NewError(KindBadRequest, "qr_logo_missing")
+
+	// Ошибки кампаний.
+	ErrCampaignNotFound     = NewError(KindNotFound, "campaign_not_found")
+	ErrCampaignNameTaken    = NewError(KindConflict, "campaign_name_taken")
+	ErrCampaignCreateFailed = NewError(KindInternal, "campaign_create_failed")
+	ErrCampaignDeleteFailed = NewError(KindInternal, "campaign_delete_failed")
+	ErrCampaignListFailed   = NewError(KindInternal, "campaign_list_failed")
+	ErrStatsFailed          = NewError(KindInternal, "stats_failed")
+
+	// Ошибки папок и тегов.
+	ErrFolderNotFound     = NewError(KindNotFound, "folder_not_found")
+	ErrFolderNameTaken    = NewError(KindConflict, "folder_name_taken")
+	ErrFolderCreateFailed = NewError(KindInternal, "folder_create_failed")
+	ErrFolderDeleteFailed = NewError(KindInternal, "folder_delete_failed")
+	ErrFolderListFailed   = NewError(KindInternal, "folder_list_failed")
+	ErrTagsFailed         = NewError(KindInternal, "tags_failed")
+
+	// Ошибки массового создания ссылок.
+	ErrBulkEmpty       = NewError(KindValidation, "bulk_empty")
+	ErrBulkTooManyRows = NewError(KindTooLarge, "bulk_too_many_rows")
+	ErrBulkRolledBack  = NewError(KindValidation, "bulk_rolled_back")
+	ErrBulkJobNotFound = NewError(KindNotFound, "bulk_job_not_found")
+	ErrBulkTooManyJobs = NewError(KindTooManyRequests, "bulk_too_many_jobs")
+
+	// Ошибки жалоб.
+	ErrReportFailed   = NewError(KindInternal, "report_failed")
+	ErrLinkHasNoOwner = NewError(KindConflict, "link_has_no_owner")
 )
@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,11 +21,151 @@ type AuthConfig struct {
 	Secret string
 }
 
+// LoginGuardConfig представляет настройки защиты входа от перебора паролей.
+type LoginGuardConfig struct {
+	MaxAccountAttempts int           // неудачных попыток на аккаунт до блокировки
+	MaxIPAttempts      int           // неудачных попыток с одного IP до блокировки
+	Window             time.Duration // окно, в котором копятся неудачные попытки
+	BaseLockout        time.Duration // длительность первой блокировки
+	MaxLockout         time.Duration // верхняя граница экспоненциальной блокировки
+	ResetAfter         time.Duration // через сколько без ошибок сбрасывается эскалация
+}
+
+// SessionConfig представляет настройки cookie-сессий веб-интерфейса.
+type SessionConfig struct {
+	CookieName      string
+	CookieSecure    bool
+	IdleTimeout     time.Duration // сессия истекает после такого простоя
+	AbsoluteTimeout time.Duration // максимальное время жизни сессии
+}
+
+// ReportConfig представляет настройки жалоб на ссылки.
+type ReportConfig struct {
+	// AutoBlockThreshold - сколько разных пользователей должны пожаловаться,
+	// чтобы ссылка была заблокирована автоматически. 0 отключает автоблокировку.
+	AutoBlockThreshold int
+}
+
+// URLPolicyConfig представляет настройки проверки адресов назначения.
+type URLPolicyConfig struct {
+	AllowedSchemes []string
+	BlockPrivate   bool          // запрещать локальные и внутренние адреса
+	ResolveHosts   bool          // проверять адреса, в которые резолвится домен
+	ResolveTimeout time.Duration // таймаут DNS-запроса при проверке
+	BlocklistFile  string        // файл с запрещёнными доменами, по одному на строку
+	AllowlistFile  string        // файл с доверенными доменами, по одному на строку
+
+	FollowRedirects bool          // проходить по редиректам адреса при создании ссылки
+	MaxRedirects    int           // сколько редиректов проходить до отказа
+	RedirectTimeout time.Duration // таймаут на всю цепочку редиректов
+	AllowPrivate    bool          // разрешить резолверу ходить на внутренние адреса, только для тестов
+	SelfHosts       []string      // хосты этого сервиса, по умолчанию хост PUBLIC_URL; адрес или редирект на них - петля
+}
+
+// ShortCodeConfig представляет настройки генерации коротких кодов ссылок.
+type ShortCodeConfig struct {
+	Generator     string  // "random" или "sequence"
+	Alphabet      string  // "base62" или "readable" (строчные без похожих символов, для печати)
+	Salt          string  // соль для перемешивания кодов генератора "sequence"
+	Length        int     // начальная длина кода
+	MaxLength     int     // предельная длина кода
+	GrowThreshold float64 // доля занятых кодов текущей длины, после которой длина растёт
+	MaxAttempts   int     // сколько раз пробовать новый код при коллизии
+}
+
+// LinkConfig представляет настройки создания ссылок.
+type LinkConfig struct {
+	// Dedupe - вместо новой ссылки возвращать существующую ссылку владельца
+	// на тот же канонический адрес.
+	Dedupe bool
+	// StripTracking - не учитывать параметры отслеживания (utm_*, fbclid...) при сравнении адресов.
+	StripTracking bool
+	// SubstringSearch - искать ссылки и по подстроке адреса, кода и заголовка.
+	// Без триграммных индексов (go run ./migrations/search) такой поиск читает всю таблицу.
+	SubstringSearch bool
+}
+
+// BulkConfig представляет настройки массового создания ссылок.
+type BulkConfig struct {
+	MaxRows  int           // строк в одном запросе
+	SyncRows int           // запрос с большим числом строк выполняется фоновой задачей
+	MaxBytes int64         // предельный размер тела запроса, JSON или CSV
+	JobTTL   time.Duration // сколько хранится результат фоновой задачи
+	MaxJobs  int           // незавершённых фоновых задач одного пользователя, 0 - без ограничения
+}
+
+// RedirectConfig представляет настройки перехода по ссылкам.
+type RedirectConfig struct {
+	// CountryHeader - заголовок с кодом страны посетителя, который выставляет
+	// CDN или прокси (например, CF-IPCountry), для правил таргетинга по стране.
+	CountryHeader string
+}
+
+// TrackingConfig представляет настройки учёта конверсий.
+type TrackingConfig struct {
+	// ClickIDParam - параметр, в котором ID перехода добавляется к адресу
+	// назначения; пустое значение отключает добавление.
+	ClickIDParam string
+	// AttributionWindow - сколько после перехода конверсия засчитывается ему.
+	AttributionWindow time.Duration
+}
+
+// QRConfig представляет настройки QR-кодов ссылок.
+type QRConfig struct {
+	LogoPath  string // PNG или JPEG логотипа для центра QR-кода, без него логотип недоступен
+	CacheSize int    // сколько готовых картинок держать в памяти
+}
+
+// WebConfig представляет настройки веб-интерфейса.
+type WebConfig struct {
+	// Dev - режим разработки: шаблоны и статика читаются с диска,
+	// шаблоны перечитываются при изменении. Иначе используются встроенные в бинарник.
+	Dev bool
+}
+
+// BrandingConfig представляет настройки оформления страниц.
+type BrandingConfig struct {
+	Name         string // название сервиса в шапке страниц
+	LogoURL      string // адрес логотипа, без него выводится название
+	SupportEmail string // адрес поддержки на страницах ошибок
+}
+
+// RateLimitPolicy описывает ограничение частоты запросов для одного маршрута.
+// Limit запросов разрешено за Period, ключ ограничения задаётся KeyBy.
+type RateLimitPolicy struct {
+	Route  string // шаблон маршрута ServeMux, например "POST /users/links"
+	Limit  int
+	Period time.Duration
+	KeyBy  string // "ip" или "user"
+}
+
+// RateLimitConfig представляет настройки ограничения частоты запросов.
+type RateLimitConfig struct {
+	Enabled  bool
+	Policies []RateLimitPolicy
+}
+
 // Config представляет конфигурацию приложения.
 type Config struct {
 	Db           DbConfig
 	Auth         AuthConfig
+	LoginGuard   LoginGuardConfig
+	RateLimit    RateLimitConfig
+	Session      SessionConfig
+	Report       ReportConfig
+	URLPolicy    URLPolicyConfig
+	ShortCode    ShortCodeConfig
+	Links        LinkConfig
+	Bulk         BulkConfig
+	Redirect     RedirectConfig
+	Tracking     TrackingConfig
+	QR           QRConfig
+	Branding     BrandingConfig
+	Web          WebConfig
 	Env          string
+	PublicURL    string // адрес сервиса для посетителей, например https://sho.rt
+	TrustProxy   bool
+	MaxBodyBytes int64 // предельный размер JSON-тела запроса
 	DefaultPage  int
 	MaxLimit     int
 	DateFormat   string
@@ -30,6 +174,11 @@ type Config struct {
 	SchemeHTTPS  string
 }
 
+// defaultRateLimits - политики по умолчанию: создание ссылок, в том числе массовое, редиректы,
+// жалобы и анонимные конверсии с сайтов назначения.
+const defaultRateLimits = "POST /users/links=10/1m@ip;POST /users/links/bulk=10/1h@user;/=120/1m@ip;POST /report/{hash}=5/1h@ip;" +
+	"POST /t/conversion=60/1m@ip;GET /t/pixel.gif=60/1m@ip"
+
 // NewConfig создаёт новый экземпляр конфигурации.
 func NewConfig() *Config {
 	// Загружаем .env (если есть)
@@ -37,6 +186,8 @@ func NewConfig() *Config {
 	if err != nil {
 		fmt.Println("Warning: The .env file was not found. Default values are being used.")
 	}
+	env := getEnv("APP_ENV", "development")
+	publicURL := getEnv("PUBLIC_URL", "http://localhost:8080")
 	return &Config{
 		Db: DbConfig{
 			Dsn: os.Getenv("DSN"),
@@ -44,7 +195,85 @@ func NewConfig() *Config {
 		Auth: AuthConfig{
 			Secret: os.Getenv("SECRET"),
 		},
-		Env: getEnv("APP_ENV", "development"),
+		LoginGuard: LoginGuardConfig{
+			MaxAccountAttempts: getEnvInt("LOGIN_MAX_ACCOUNT_ATTEMPTS", 5),
+			MaxIPAttempts:      getEnvInt("LOGIN_MAX_IP_ATTEMPTS", 20),
+			Window:             getEnvDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+			BaseLockout:        getEnvDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+			MaxLockout:         getEnvDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+			ResetAfter:         getEnvDuration("LOGIN_LOCKOUT_RESET", 24*time.Hour),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:  getEnvBool("RATE_LIMIT_ENABLED", true),
+			Policies: parseRateLimitPolicies(getEnv("RATE_LIMITS", defaultRateLimits)),
+		},
+		Session: SessionConfig{
+			CookieName:      getEnv("SESSION_COOKIE_NAME", "shorty_session"),
+			CookieSecure:    getEnvBool("SESSION_COOKIE_SECURE", true),
+			IdleTimeout:     getEnvDuration("SESSION_IDLE_TIMEOUT", 30*time.Minute),
+			AbsoluteTimeout: getEnvDuration("SESSION_ABSOLUTE_TIMEOUT", 7*24*time.Hour),
+		},
+		Report: ReportConfig{
+			AutoBlockThreshold: getEnvInt("REPORT_AUTO_BLOCK_THRESHOLD", 5),
+		},
+		URLPolicy: URLPolicyConfig{
+			AllowedSchemes: getEnvList("URL_ALLOWED_SCHEMES", []string{"http", "https"}),
+			BlockPrivate:   getEnvBool("URL_BLOCK_PRIVATE", true),
+			ResolveHosts:   getEnvBool("URL_RESOLVE_HOSTS", true),
+			ResolveTimeout: getEnvDuration("URL_RESOLVE_TIMEOUT", 2*time.Second),
+			BlocklistFile:  getEnv("URL_BLOCKLIST_FILE", "data/url_blocklist.txt"),
+			AllowlistFile:  getEnv("URL_ALLOWLIST_FILE", "data/url_allowlist.txt"),
+
+			FollowRedirects: getEnvBool("URL_FOLLOW_REDIRECTS", false),
+			MaxRedirects:    getEnvInt("URL_MAX_REDIRECTS", 5),
+			RedirectTimeout: getEnvDuration("URL_REDIRECT_TIMEOUT", 5*time.Second),
+			AllowPrivate:    getEnvBool("URL_RESOLVER_ALLOW_PRIVATE", false),
+			SelfHosts:       getEnvList("SELF_HOSTS", hostsOf(publicURL)),
+		},
+		ShortCode: ShortCodeConfig{
+			Generator:     getEnv("SHORTCODE_GENERATOR", "random"),
+			Alphabet:      getEnv("SHORTCODE_ALPHABET", "base62"),
+			Salt:          getEnv("SHORTCODE_SALT", ""),
+			Length:        getEnvInt("SHORTCODE_LENGTH", 7),
+			MaxLength:     getEnvInt("SHORTCODE_MAX_LENGTH", 16),
+			GrowThreshold: getEnvFloat("SHORTCODE_GROW_THRESHOLD", 0.1),
+			MaxAttempts:   getEnvInt("SHORTCODE_MAX_ATTEMPTS", 5),
+		},
+		Links: LinkConfig{
+			Dedupe:          getEnvBool("LINK_DEDUPE", false),
+			StripTracking:   getEnvBool("LINK_DEDUPE_STRIP_TRACKING", true),
+			SubstringSearch: getEnvBool("LINK_SEARCH_SUBSTRING", false),
+		},
+		Bulk: BulkConfig{
+			MaxRows:  getEnvInt("BULK_MAX_ROWS", 5000),
+			SyncRows: getEnvInt("BULK_SYNC_ROWS", 100),
+			MaxBytes: int64(getEnvInt("BULK_MAX_BYTES", 8<<20)),
+			JobTTL:   getEnvDuration("BULK_JOB_TTL", 24*time.Hour),
+			MaxJobs:  getEnvInt("BULK_MAX_JOBS", 2),
+		},
+		Redirect: RedirectConfig{
+			CountryHeader: getEnv("GEO_COUNTRY_HEADER", "CF-IPCountry"),
+		},
+		Tracking: TrackingConfig{
+			ClickIDParam:      getEnv("CLICK_ID_PARAM", "sclid"),
+			AttributionWindow: getEnvDuration("ATTRIBUTION_WINDOW", 30*24*time.Hour),
+		},
+		QR: QRConfig{
+			LogoPath:  getEnv("QR_LOGO_PATH", ""),
+			CacheSize: getEnvInt("QR_CACHE_SIZE", 256),
+		},
+		Branding: BrandingConfig{
+			Name:         getEnv("BRAND_NAME", "Коротышка"),
+			LogoURL:      getEnv("BRAND_LOGO_URL", ""),
+			SupportEmail: getEnv("BRAND_SUPPORT_EMAIL", ""),
+		},
+		Web: WebConfig{
+			Dev: getEnvBool("WEB_DEV", env == "development"),
+		},
+		Env:          env,
+		PublicURL:    publicURL,
+		TrustProxy:   getEnvBool("TRUST_PROXY", false),
+		MaxBodyBytes: int64(getEnvInt("MAX_BODY_BYTES", 1<<20)),
 	}
 }
 
@@ -55,3 +284,128 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvInt возвращает целочисленное значение переменной окружения или дефолтное значение
+func getEnvInt(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+		fmt.Printf("Warning: invalid value %q for %s, using default %d\n", value, key, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvFloat возвращает дробное значение переменной окружения или дефолтное значение
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, exists := os.LookupEnv(key); exists {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+		fmt.Printf("Warning: invalid value %q for %s, using default %g\n", value, key, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvList возвращает список значений переменной окружения, разделённых запятыми,
+// или дефолтное значение
+func getEnvList(key string, defaultValue []string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// hostsOf возвращает хост адреса списком из одного элемента, для значения по
+// умолчанию SELF_HOSTS. Адрес без хоста даёт пустой список.
+func hostsOf(rawURL string) []string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	return []string{strings.ToLower(u.Hostname())}
+}
+
+// getEnvBool возвращает логическое значение переменной окружения или дефолтное значение
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+		fmt.Printf("Warning: invalid value %q for %s, using default %t\n", value, key, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvDuration возвращает длительность из переменной окружения (формат time.ParseDuration) или дефолтное значение
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+		fmt.Printf("Warning: invalid value %q for %s, using default %s\n", value, key, defaultValue)
+	}
+	return defaultValue
+}
+
+// parseRateLimitPolicies разбирает политики вида "<маршрут>=<лимит>/<период>@<ключ>",
+// разделённые ";". Ключ можно опустить, по умолчанию используется "ip".
+// Некорректные политики пропускаются с предупреждением.
+func parseRateLimitPolicies(value string) []RateLimitPolicy {
+	var policies []RateLimitPolicy
+	for _, raw := range strings.Split(value, ";") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		policy, err := parseRateLimitPolicy(raw)
+		if err != nil {
+			fmt.Printf("Warning: skipping rate limit policy %q: %v\n", raw, err)
+			continue
+		}
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// parseRateLimitPolicy разбирает одну политику ограничения частоты запросов.
+func parseRateLimitPolicy(raw string) (RateLimitPolicy, error) {
+	route, rule, ok := strings.Cut(raw, "=")
+	if !ok || strings.TrimSpace(route) == "" {
+		return RateLimitPolicy{}, fmt.Errorf("expected <route>=<limit>/<period>[@<key>]")
+	}
+	rule, keyBy, ok := strings.Cut(rule, "@")
+	if !ok {
+		keyBy = "ip"
+	}
+	switch keyBy {
+	case "ip", "user":
+	default:
+		return RateLimitPolicy{}, fmt.Errorf("unknown key %q", keyBy)
+	}
+	limitStr, periodStr, ok := strings.Cut(rule, "/")
+	if !ok {
+		return RateLimitPolicy{}, fmt.Errorf("expected <limit>/<period>")
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid limit %q", limitStr)
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid period %q", periodStr)
+	}
+	return RateLimitPolicy{
+		Route:  strings.TrimSpace(route),
+		Limit:  limit,
+		Period: period,
+		KeyBy:  keyBy,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitPolicies(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []RateLimitPolicy
+	}{
+		{
+			name:  "valid",
+			value: "POST /links=10/1m@user; POST /auth/login=5/30s",
+			want: []RateLimitPolicy{
+				{Route: "POST /links", Limit: 10, Period: time.Minute, KeyBy: "user"},
+				{Route: "POST /auth/login", Limit: 5, Period: 30 * time.Second, KeyBy: "ip"},
+			},
+		},
+		{name: "empty", value: " ; ", want: nil},
+		{name: "api_key is not a key", value: "POST /links=10/1m@api_key", want: nil},
+		{name: "unknown key", value: "POST /links=10/1m@header", want: nil},
+		{name: "missing route", value: "=10/1m", want: nil},
+		{name: "missing equals", value: "POST /links 10/1m", want: nil},
+		{name: "missing period", value: "POST /links=10", want: nil},
+		{name: "bad limit", value: "POST /links=ten/1m", want: nil},
+		{name: "zero limit", value: "POST /links=0/1m", want: nil},
+		{name: "bad period", value: "POST /links=10/soon", want: nil},
+		{name: "negative period", value: "POST /links=10/-1m", want: nil},
+		{
+			name:  "bad entries are skipped",
+			value: "POST /links=ten/1m;GET /links=100/1h;POST /report=1/1m@api_key",
+			want: []RateLimitPolicy{
+				{Route: "GET /links", Limit: 100, Period: time.Hour, KeyBy: "ip"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRateLimitPolicies(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRateLimitPolicies(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,20 +13,30 @@ import (
 	"shorty/internal/common"
 	"shorty/internal/config"
 	"shorty/internal/models"
+	"shorty/internal/payload"
 	"shorty/internal/service"
 	"shorty/pkg/jwt"
 	"shorty/pkg/logger"
 	"shorty/pkg/middleware"
+	"shorty/pkg/parse"
 	"shorty/pkg/req"
 	"shorty/pkg/res"
+	"shorty/pkg/urlpolicy"
 )
 
+// maxAuditExport limits the number of records in a single CSV export.
+const maxAuditExport = 10000
+
 // AdminHandlerDeps holds the dependencies required to initialize an AdminHandler.
 type AdminHandlerDeps struct {
 	Config      *config.Config
 	UserService service.UserServ
 	LinkService service.LinkServ
 	StatService service.StatServ
+	LoginGuard  service.LoginGuardServ
+	Audit       service.AuditServ
+	Reports     service.ReportServ
+	URLPolicy   service.URLPolicyServ
 	JWTService  *jwt.JWT
 }
 
@@ -34,16 +46,24 @@ type AdminHandler struct {
 	UserService service.UserServ
 	LinkService service.LinkServ
 	StatService service.StatServ
+	LoginGuard  service.LoginGuardServ
+	Audit       service.AuditServ
+	Reports     service.ReportServ
+	URLPolicy   service.URLPolicyServ
 	JWTService  *jwt.JWT
 }
 
 // NewAdminHandler registers admin-related routes and attaches them to AdminHandler methods.
-func NewAdminHandler(router *http.ServeMux, deps AdminHandlerDeps) {
+func NewAdminHandler(router Router, deps AdminHandlerDeps) {
 	handler := &AdminHandler{
 		Config:      deps.Config,
 		UserService: deps.UserService,
 		LinkService: deps.LinkService,
 		StatService: deps.StatService,
+		LoginGuard:  deps.LoginGuard,
+		Audit:       deps.Audit,
+		Reports:     deps.Reports,
+		URLPolicy:   deps.URLPolicy,
 		JWTService:  deps.JWTService,
 	}
 
@@ -69,6 +89,26 @@ func NewAdminHandler(router *http.ServeMux, deps AdminHandlerDeps) {
 	// Statistics
 	router.Handle("GET /admin/stats", adminMiddleware(handler.GetClickedLinkStats()))
 	router.Handle("GET /admin/stats/links", adminMiddleware(handler.GetAllLinksStats()))
+	router.Handle("GET /admin/stats/campaigns", adminMiddleware(handler.GetCampaignStats()))
+
+	// Sign-in lockouts
+	router.Handle("GET /admin/lockouts", adminMiddleware(handler.GetLockouts()))
+	router.Handle("DELETE /admin/lockouts/{id}", adminMiddleware(handler.ClearLockout()))
+
+	// Audit log
+	router.Handle("GET /admin/audit", adminMiddleware(handler.GetAuditLog()))
+
+	// Abuse reports moderation
+	router.Handle("GET /admin/reports", adminMiddleware(handler.GetReports()))
+	router.Handle("POST /admin/reports/{id}/dismiss", adminMiddleware(handler.DismissReports()))
+	router.Handle("POST /admin/reports/{id}/block-link", adminMiddleware(handler.BlockReportedLink()))
+	router.Handle("POST /admin/reports/{id}/block-owner", adminMiddleware(handler.BlockReportedOwner()))
+
+	// URL policy
+	router.Handle("GET /admin/url-policy/rejections", adminMiddleware(handler.GetURLRejections()))
+	router.Handle("GET /admin/url-policy/{list}", adminMiddleware(handler.GetPolicyDomains()))
+	router.Handle("POST /admin/url-policy/{list}", adminMiddleware(handler.AddPolicyDomain()))
+	router.Handle("DELETE /admin/url-policy/{list}/{domain}", adminMiddleware(handler.RemovePolicyDomain()))
 }
 
 // GetUsers method to retrieve the list of users.
@@ -79,55 +119,21 @@ func (h *AdminHandler) GetUsers() http.HandlerFunc {
 			return
 		}
 
-		limit := h.Config.DefaultLimit
-		if limit <= 0 {
-			limit = 5
-		}
-		if lStr := r.URL.Query().Get("limit"); lStr != "" {
-			if l, err := strconv.Atoi(lStr); err == nil && l > 0 {
-				limit = l
-			}
-		}
-
-		page := 1
-		if pStr := r.URL.Query().Get("page"); pStr != "" {
-			if p, err := strconv.Atoi(pStr); err == nil && p > 0 {
-				page = p
-			}
-		}
-		offset := (page - 1) * limit
+		limit, page, offset := h.parsePagination(r)
 
 		total, err := h.UserService.Count(r.Context())
 		if err != nil {
-			res.ERROR(w, common.ErrorGetUsers, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		users, err := h.UserService.GetAll(r.Context(), limit, offset)
 		if err != nil {
-			res.ERROR(w, common.ErrorGetUsers, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
-		totalPages := 1
-		if limit > 0 {
-			totalPages = int((total + int64(limit) - 1) / int64(limit))
-		}
-
-		var next, prev interface{}
-		if page < totalPages {
-			next = makePageURL(r, page+1, totalPages)
-		}
-		if page > 1 {
-			prev = makePageURL(r, page-1, totalPages)
-		}
-
 		resp := map[string]interface{}{
-			"info": map[string]interface{}{
-				"count": total,
-				"pages": totalPages,
-				"next":  next,
-				"prev":  prev,
-			},
+			"info":    paginationInfo(r, total, limit, page),
 			"results": users,
 		}
 		res.JSON(w, resp, http.StatusOK)
@@ -141,13 +147,13 @@ func (h *AdminHandler) GetUser() http.HandlerFunc {
 		userID, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Invalid user ID", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		user, err := h.UserService.GetByID(ctx, uint(userID))
 		if err != nil {
 			logger.Error("Error searching for user", zap.Uint("userID", userID), zap.Error(err))
-			res.ERROR(w, common.ErrUserNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("User found", zap.Uint("id", userID))
@@ -162,20 +168,19 @@ func (h *AdminHandler) UpdateUser() http.HandlerFunc {
 		userID, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Invalid user ID", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		body, err := req.HandleBody[models.User](&w, r)
 		if err != nil {
 			logger.Error("Error processing request body", zap.Error(err))
-			res.ERROR(w, common.ErrRequestBodyParse, http.StatusBadRequest)
 			return
 		}
 		body.ID = uint(userID)
 		updatedUser, err := h.UserService.Update(ctx, body)
 		if err != nil {
 			logger.Error("Error updating user", zap.Uint("userID", userID), zap.Error(err))
-			res.ERROR(w, common.ErrUserUpdateFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("User successfully updated", zap.Uint("userID", userID))
@@ -190,17 +195,17 @@ func (h *AdminHandler) DeleteUser() http.HandlerFunc {
 		userID, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Invalid user ID", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		err = h.UserService.Delete(ctx, uint(userID))
 		if err != nil {
 			logger.Error("Error deleting user", zap.Uint("userID", userID), zap.Error(err))
-			res.ERROR(w, common.ErrUserDeleteFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("User successfully deleted", zap.Uint("userID", userID))
-		res.JSON(w, map[string]string{"message": "User deleted"}, http.StatusOK)
+		res.MESSAGE(w, r, "msg.user_deleted", http.StatusOK)
 	}
 }
 
@@ -211,20 +216,20 @@ func (h *AdminHandler) BlockUser() http.HandlerFunc {
 		id, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("User ID parsing error", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		user, err := h.UserService.GetByID(ctx, uint(id))
 		if err != nil {
 			logger.Error("Error when searching for a user", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 
 		updateUser, err := h.UserService.Block(ctx, user.ID)
 		if err != nil {
 			logger.Error("Error when blocking the user", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkBlockFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -240,20 +245,20 @@ func (h *AdminHandler) UnblockUser() http.HandlerFunc {
 		id, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("User ID parsing error", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 
 		user, err := h.UserService.GetByID(ctx, uint(id))
 		if err != nil {
 			logger.Error("Error when searching for a user", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 		updatedUser, err := h.UserService.UnBlock(ctx, user.ID)
 		if err != nil {
 			logger.Error("Error when unblocking the user", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrUnBlockFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -269,7 +274,7 @@ func (h *AdminHandler) BlockLink() http.HandlerFunc {
 		id, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Link ID parsing error", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 
@@ -277,7 +282,7 @@ func (h *AdminHandler) BlockLink() http.HandlerFunc {
 		link, err := h.LinkService.FindByID(ctx, uint(id))
 		if err != nil {
 			logger.Error("Error when searching for a link", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -285,7 +290,7 @@ func (h *AdminHandler) BlockLink() http.HandlerFunc {
 		updatedLink, err := h.LinkService.Block(ctx, link.ID)
 		if err != nil {
 			logger.Error("Error when blocking the link", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkBlockFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -301,7 +306,7 @@ func (h *AdminHandler) UnblockLink() http.HandlerFunc {
 		id, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Link ID parsing error", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 
@@ -309,7 +314,7 @@ func (h *AdminHandler) UnblockLink() http.HandlerFunc {
 		link, err := h.LinkService.FindByID(ctx, uint(id))
 		if err != nil {
 			logger.Error("Error when searching for a link", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -317,7 +322,7 @@ func (h *AdminHandler) UnblockLink() http.HandlerFunc {
 		updatedLink, err := h.LinkService.UnBlock(ctx, link.ID)
 		if err != nil {
 			logger.Error("Error when unblocking the link", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrUnBlockFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
@@ -333,26 +338,26 @@ func (h *AdminHandler) DeleteLink() http.HandlerFunc {
 		id, err := h.parseIDFromPath(r)
 		if err != nil {
 			logger.Error("Invalid ID for deleting a link", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 
 		_, err = h.LinkService.FindByID(ctx, uint(id))
 		if err != nil {
 			logger.Error("The link could not be found for deletion", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
 
 		err = h.LinkService.Delete(ctx, uint(id))
 		if err != nil {
 			logger.Error("Error when deleting a link", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkDeleteFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
 		logger.Info("The link was successfully deleted", zap.Uint("id", uint(id)))
-		res.JSON(w, map[string]string{"message": "link deleted"}, http.StatusOK)
+		res.MESSAGE(w, r, "msg.link_deleted", http.StatusOK)
 	}
 }
 
@@ -363,7 +368,7 @@ func (h *AdminHandler) GetBlockedUsersCount() http.HandlerFunc {
 		count, err := h.UserService.GetBlockedUsersCount(ctx)
 		if err != nil {
 			logger.Error("Error when getting the number of blocked users", zap.Error(err))
-			res.ERROR(w, common.ErrInternal, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		res.JSON(w, map[string]int64{"blocked_users": count}, http.StatusOK)
@@ -377,7 +382,7 @@ func (h *AdminHandler) GetBlockedLinksCount() http.HandlerFunc {
 		count, err := h.LinkService.GetBlockedLinksCount(ctx)
 		if err != nil {
 			logger.Error("Error when getting the number of blocked links", zap.Error(err))
-			res.ERROR(w, common.ErrInternal, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		res.JSON(w, map[string]int64{"blocked_links": count}, http.StatusOK)
@@ -391,7 +396,7 @@ func (h *AdminHandler) GetDeletedLinksCount() http.HandlerFunc {
 		count, err := h.LinkService.GetDeletedLinksCount(ctx)
 		if err != nil {
 			logger.Error("Error when receiving the number of deleted links", zap.Error(err))
-			res.ERROR(w, common.ErrInternal, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		res.JSON(w, map[string]int64{"deleted_links": count}, http.StatusOK)
@@ -405,7 +410,7 @@ func (h *AdminHandler) GetTotalLinks() http.HandlerFunc {
 		count, err := h.LinkService.GetTotalLinks(ctx)
 		if err != nil {
 			logger.Error("Error when getting the number of links created", zap.Error(err))
-			res.ERROR(w, common.ErrInternal, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		res.JSON(w, map[string]int64{"created_links": count}, http.StatusOK)
@@ -420,7 +425,7 @@ func (h *AdminHandler) GetClickedLinkStats() http.HandlerFunc {
 		from, to, by, err := h.parseStatParams(r)
 		if err != nil {
 			logger.Error("Error parsing stat parameters", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidParam, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidParam)
 			return
 		}
 
@@ -438,21 +443,326 @@ func (h *AdminHandler) GetAllLinksStats() http.HandlerFunc {
 		fromStr := r.URL.Query().Get("from")
 		from, err := time.Parse("2006-01-02", fromStr)
 		if err != nil {
-			res.ERROR(w, common.ErrInvalidParam, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidParam)
 			return
 		}
 
 		toStr := r.URL.Query().Get("to")
 		to, err := time.Parse("2006-01-02", toStr)
 		if err != nil {
-			res.ERROR(w, common.ErrInvalidParam, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidParam)
+			return
+		}
+		filter := payload.LinkStatsFilter{From: from, To: to}
+		if raw := r.URL.Query().Get("campaign_id"); raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				res.ERROR(w, r, common.ErrInvalidParam)
+				return
+			}
+			campaignID := uint(id)
+			filter.CampaignID = &campaignID
+		}
+		stats := h.StatService.GetAllLinksStats(ctx, filter)
+		res.JSON(w, stats, http.StatusOK)
+	}
+}
+
+// GetCampaignStats returns aggregated statistics for the campaigns of all users between two dates.
+func (h *AdminHandler) GetCampaignStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from, to, err := parse.DateRange(r)
+		if err != nil {
+			res.ERROR(w, r, common.ErrInvalidParam)
+			return
+		}
+		stats, err := h.StatService.CampaignStats(r.Context(), nil, from, to)
+		if err != nil {
+			logger.Error("Error when getting campaign statistics", zap.Error(err))
+			res.ERROR(w, r, err)
 			return
 		}
-		stats := h.StatService.GetAllLinksStats(ctx, from, to)
 		res.JSON(w, stats, http.StatusOK)
 	}
 }
 
+// GetLockouts returns a paginated list of active sign-in lockouts.
+func (h *AdminHandler) GetLockouts() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		limit, page, offset := h.parsePagination(r)
+
+		total, err := h.LoginGuard.CountActive(ctx)
+		if err != nil {
+			logger.Error("Error when counting sign-in lockouts", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		lockouts, err := h.LoginGuard.GetActive(ctx, limit, offset)
+		if err != nil {
+			logger.Error("Error when getting sign-in lockouts", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"info":    paginationInfo(r, total, limit, page),
+			"results": lockouts,
+		}
+		res.JSON(w, resp, http.StatusOK)
+	}
+}
+
+// ClearLockout removes a sign-in lockout by its ID.
+func (h *AdminHandler) ClearLockout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := h.parseIDFromPath(r)
+		if err != nil {
+			logger.Error("Lockout ID parsing error", zap.Error(err))
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+
+		if err := h.LoginGuard.Clear(ctx, id); err != nil {
+			if !errors.Is(err, service.ErrLockoutNotFound) {
+				logger.Error("Error when clearing the lockout", zap.Uint("id", id), zap.Error(err))
+			}
+			res.ERROR(w, r, err)
+			return
+		}
+
+		logger.Info("The sign-in lockout was cleared", zap.Uint("id", id))
+		res.MESSAGE(w, r, "msg.lockout_cleared", http.StatusOK)
+	}
+}
+
+// GetAuditLog returns the audit log filtered by actor, action, target and date range.
+// With format=csv the matching records are exported as a CSV file instead of a page.
+func (h *AdminHandler) GetAuditLog() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		filter, err := h.parseAuditFilter(r)
+		if err != nil {
+			logger.Error("Error parsing audit log filter", zap.Error(err))
+			res.ERROR(w, r, common.ErrInvalidParam)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			entries, err := h.Audit.GetAll(ctx, filter, maxAuditExport, 0)
+			if err != nil {
+				logger.Error("Error when exporting the audit log", zap.Error(err))
+				res.ERROR(w, r, err)
+				return
+			}
+			writeAuditCSV(w, entries)
+			return
+		}
+
+		limit, page, offset := h.parsePagination(r)
+		total, err := h.Audit.Count(ctx, filter)
+		if err != nil {
+			logger.Error("Error when counting audit log records", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		entries, err := h.Audit.GetAll(ctx, filter, limit, offset)
+		if err != nil {
+			logger.Error("Error when getting the audit log", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"info":    paginationInfo(r, total, limit, page),
+			"results": entries,
+		}
+		res.JSON(w, resp, http.StatusOK)
+	}
+}
+
+// GetReports returns the moderation queue: open abuse reports grouped by link.
+func (h *AdminHandler) GetReports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		limit, page, offset := h.parsePagination(r)
+
+		total, err := h.Reports.CountQueue(ctx)
+		if err != nil {
+			logger.Error("Error when counting the report queue", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		items, err := h.Reports.Queue(ctx, limit, offset)
+		if err != nil {
+			logger.Error("Error when getting the report queue", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"info":    paginationInfo(r, total, limit, page),
+			"results": items,
+		}
+		res.JSON(w, resp, http.StatusOK)
+	}
+}
+
+// DismissReports closes the open reports of a link without taking action
+// and lifts the block if the reports blocked the link automatically.
+func (h *AdminHandler) DismissReports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := h.parseIDFromPath(r)
+		if err != nil {
+			logger.Error("Link ID parsing error", zap.Error(err))
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+
+		if err := h.Reports.Dismiss(ctx, id); err != nil {
+			h.reportActionError(w, r, id, err)
+			return
+		}
+
+		logger.Info("Reports dismissed", zap.Uint("linkID", id))
+		res.MESSAGE(w, r, "msg.reports_dismissed", http.StatusOK)
+	}
+}
+
+// BlockReportedLink blocks a reported link and closes its reports.
+func (h *AdminHandler) BlockReportedLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := h.parseIDFromPath(r)
+		if err != nil {
+			logger.Error("Link ID parsing error", zap.Error(err))
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+
+		link, err := h.Reports.BlockLink(ctx, id)
+		if err != nil {
+			h.reportActionError(w, r, id, err)
+			return
+		}
+
+		logger.Info("Reported link blocked", zap.Uint("linkID", id))
+		res.JSON(w, link, http.StatusOK)
+	}
+}
+
+// BlockReportedOwner blocks the owner of a reported link and closes its reports.
+func (h *AdminHandler) BlockReportedOwner() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id, err := h.parseIDFromPath(r)
+		if err != nil {
+			logger.Error("Link ID parsing error", zap.Error(err))
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+
+		user, err := h.Reports.BlockOwner(ctx, id)
+		if err != nil {
+			h.reportActionError(w, r, id, err)
+			return
+		}
+
+		logger.Info("Owner of reported link blocked", zap.Uint("linkID", id), zap.Uint("userID", user.ID))
+		res.JSON(w, user, http.StatusOK)
+	}
+}
+
+// GetURLRejections returns a paginated list of URLs refused by the URL policy.
+func (h *AdminHandler) GetURLRejections() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		limit, page, offset := h.parsePagination(r)
+
+		total, err := h.URLPolicy.CountRejections(ctx)
+		if err != nil {
+			logger.Error("Error when counting URL rejections", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		rejections, err := h.URLPolicy.Rejections(ctx, limit, offset)
+		if err != nil {
+			logger.Error("Error when getting URL rejections", zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+
+		resp := map[string]interface{}{
+			"info":    paginationInfo(r, total, limit, page),
+			"results": rejections,
+		}
+		res.JSON(w, resp, http.StatusOK)
+	}
+}
+
+// GetPolicyDomains returns the domains on the URL policy blocklist or allowlist.
+func (h *AdminHandler) GetPolicyDomains() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list := urlpolicy.List(r.PathValue("list"))
+		domains, err := h.URLPolicy.Domains(list)
+		if err != nil {
+			policyDomainError(w, r, err)
+			return
+		}
+		res.JSON(w, map[string]interface{}{"list": list, "domains": domains}, http.StatusOK)
+	}
+}
+
+// AddPolicyDomain adds a domain to the URL policy blocklist or allowlist.
+func (h *AdminHandler) AddPolicyDomain() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[payload.DomainRequest](&w, r)
+		if err != nil {
+			return
+		}
+		list := urlpolicy.List(r.PathValue("list"))
+		domain, err := h.URLPolicy.AddDomain(r.Context(), list, body.Domain)
+		if err != nil {
+			policyDomainError(w, r, err)
+			return
+		}
+		logger.Info("Domain added to URL policy", zap.String("list", string(list)), zap.String("domain", domain))
+		res.JSON(w, map[string]interface{}{"list": list, "domain": domain}, http.StatusCreated)
+	}
+}
+
+// RemovePolicyDomain removes a domain from the URL policy blocklist or allowlist.
+func (h *AdminHandler) RemovePolicyDomain() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		list := urlpolicy.List(r.PathValue("list"))
+		domain := r.PathValue("domain")
+		if err := h.URLPolicy.RemoveDomain(r.Context(), list, domain); err != nil {
+			policyDomainError(w, r, err)
+			return
+		}
+		logger.Info("Domain removed from URL policy", zap.String("list", string(list)), zap.String("domain", domain))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// policyDomainError writes the response for a failed URL policy list operation.
+func policyDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	if common.KindOf(err) == common.KindInternal {
+		logger.Error("Error when updating the URL policy", zap.Error(err))
+	}
+	res.ERROR(w, r, err)
+}
+
+// reportActionError writes the response for a failed moderation action.
+func (h *AdminHandler) reportActionError(w http.ResponseWriter, r *http.Request, linkID uint, err error) {
+	if common.KindOf(err) == common.KindInternal {
+		logger.Error("Error when moderating reports", zap.Uint("linkID", linkID), zap.Error(err))
+	}
+	res.ERROR(w, r, err)
+}
+
 // parseIDFromPath parses the "id" path parameter from the request and returns it as uint.
 func (h *AdminHandler) parseIDFromPath(r *http.Request) (uint, error) {
 	id := r.PathValue("id")
@@ -508,6 +818,120 @@ func (h *AdminHandler) parseStatParams(r *http.Request) (from, to time.Time, by
 	return from, to, by, nil
 }
 
+// parsePagination reads the "limit" and "page" query parameters and returns
+// them together with the resulting offset.
+func (h *AdminHandler) parsePagination(r *http.Request) (limit, page, offset int) {
+	limit = h.Config.DefaultLimit
+	if limit <= 0 {
+		limit = 5
+	}
+	if lStr := r.URL.Query().Get("limit"); lStr != "" {
+		if l, err := strconv.Atoi(lStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	page = 1
+	if pStr := r.URL.Query().Get("page"); pStr != "" {
+		if p, err := strconv.Atoi(pStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	return limit, page, (page - 1) * limit
+}
+
+// paginationInfo builds the "info" block of a paginated response.
+func paginationInfo(r *http.Request, total int64, limit, page int) map[string]interface{} {
+	totalPages := 1
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+
+	var next, prev interface{}
+	if page < totalPages {
+		next = makePageURL(r, page+1, totalPages)
+	}
+	if page > 1 {
+		prev = makePageURL(r, page-1, totalPages)
+	}
+
+	return map[string]interface{}{
+		"count": total,
+		"pages": totalPages,
+		"next":  next,
+		"prev":  prev,
+	}
+}
+
+// parseAuditFilter parses the audit log filter from the query parameters
+// "actor_id", "action", "target_type", "target_id", "from" and "to".
+// Dates are inclusive and use the 2006-01-02 format.
+func (h *AdminHandler) parseAuditFilter(r *http.Request) (payload.AuditLogFilter, error) {
+	q := r.URL.Query()
+	filter := payload.AuditLogFilter{
+		Action:     q.Get("action"),
+		TargetType: q.Get("target_type"),
+	}
+
+	if v := q.Get("actor_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid 'actor_id': %w", err)
+		}
+		filter.ActorID = uint(id)
+	}
+	if v := q.Get("target_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return filter, fmt.Errorf("invalid 'target_id': %w", err)
+		}
+		filter.TargetID = uint(id)
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid 'from' date format: %w", err)
+		}
+		filter.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid 'to' date format: %w", err)
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// writeAuditCSV writes audit log records as a CSV attachment.
+func writeAuditCSV(w http.ResponseWriter, entries []models.AuditLog) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"id", "created_at", "actor_id", "actor_email", "action", "target_type", "target_id", "diff", "ip", "request_id"})
+	for _, e := range entries {
+		_ = cw.Write([]string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatUint(uint64(e.ActorID), 10),
+			e.ActorEmail,
+			e.Action,
+			e.TargetType,
+			strconv.FormatUint(uint64(e.TargetID), 10),
+			string(e.Diff),
+			e.IP,
+			e.RequestID,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Error("Error writing audit CSV", zap.Error(err))
+	}
+}
+
 // getScheme attempts to determine the original request scheme (http or https),
 // taking into account reverse proxies.
 func getScheme(r *http.Request) string {
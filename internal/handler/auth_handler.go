@@ -1,16 +1,22 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"go.uber.org/zap"
 
 	"shorty/internal/common"
 	"shorty/internal/config"
+	"shorty/internal/models"
 	"shorty/internal/payload"
 	"shorty/internal/service"
 	"shorty/pkg/jwt"
 	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
 	"shorty/pkg/req"
 	"shorty/pkg/res"
 )
@@ -19,19 +25,25 @@ import (
 type AuthHandlerDeps struct {
 	Config      *config.Config
 	AuthService service.AuthServ
+	LoginGuard  service.LoginGuardServ
+	Sessions    service.SessionServ
 }
 
 // AuthHandler - обработчик аутентификации.
 type AuthHandler struct {
 	Config      *config.Config
 	AuthService service.AuthServ
+	LoginGuard  service.LoginGuardServ
+	Sessions    service.SessionServ
 }
 
 // NewAuthHandler - создание обработчика аутентификации.
-func NewAuthHandler(router *http.ServeMux, deps AuthHandlerDeps) {
+func NewAuthHandler(router Router, deps AuthHandlerDeps) {
 	handler := &AuthHandler{
 		Config:      deps.Config,
 		AuthService: deps.AuthService,
+		LoginGuard:  deps.LoginGuard,
+		Sessions:    deps.Sessions,
 	}
 
 	// Управление авторизацией.
@@ -43,33 +55,28 @@ func NewAuthHandler(router *http.ServeMux, deps AuthHandlerDeps) {
 func (h *AuthHandler) SignUp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		if r.Method != http.MethodPost {
-			logger.Error("Неверный метод запроса", zap.String("method", r.Method))
-			res.ERROR(w, common.ErrMethodNotAllowed, http.StatusMethodNotAllowed)
-			return
-		}
-
 		body, err := req.HandleBody[payload.SignupRequest](&w, r)
 		if err != nil {
 			logger.Error("Ошибка при парсинге тела запроса для регистрации", zap.Error(err))
-			res.ERROR(w, common.ErrBadRequest, http.StatusBadRequest)
 			return
 		}
 
 		user, err := h.AuthService.Registration(ctx, body.Name, body.Email, body.Password, body.Role, body.IsBlocked)
 		if err != nil {
 			logger.Error("Ошибка регистрации пользователя", zap.String("email", body.Email), zap.Error(err))
-			res.ERROR(w, common.ErrUserRegistrationFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 
 		token, err := jwt.NewJWT(h.Config.Auth.Secret).CreateToken(user)
 		if err != nil {
 			logger.Error("Ошибка при создании токена", zap.String("email", user.Email), zap.Error(err))
-			res.ERROR(w, common.ErrAuthFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, common.ErrAuthFailed)
 			return
 		}
 
+		h.startSession(w, r, user)
+
 		data := payload.SignupResponse{
 			Token: token,
 		}
@@ -82,37 +89,90 @@ func (h *AuthHandler) SignUp() http.HandlerFunc {
 func (h *AuthHandler) SignIn() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		if r.Method != http.MethodPost {
-			logger.Error("Неверный метод запроса", zap.String("method", r.Method))
-			res.ERROR(w, common.ErrMethodNotAllowed, http.StatusMethodNotAllowed)
-			return
-		}
-
 		body, err := req.HandleBody[payload.SigninRequest](&w, r)
 		if err != nil {
 			logger.Error("Ошибка при парсинге тела запроса для авторизации", zap.Error(err))
-			res.ERROR(w, common.ErrBadRequest, http.StatusBadRequest)
+			return
+		}
+
+		// Проверяем, не заблокирован ли вход для аккаунта или IP.
+		ip := req.ClientIP(r, h.Config.TrustProxy)
+		if retryAfter, err := h.LoginGuard.Check(ctx, body.Email, ip); err != nil {
+			if errors.Is(err, service.ErrLoginLocked) {
+				logger.Warn("Вход временно заблокирован", zap.String("ip", ip), zap.Duration("retry_after", retryAfter))
+				writeRetryAfter(w, retryAfter)
+				res.ERROR(w, r, common.ErrTooManyLoginAttempts)
+				return
+			}
+			logger.Error("Ошибка проверки блокировки входа", zap.Error(err))
+			res.ERROR(w, r, common.ErrAuthFailed)
 			return
 		}
 
 		user, err := h.AuthService.Login(ctx, body.Email, body.Password, body.Role)
 		if err != nil {
-			logger.Error("Ошибка авторизации пользователя", zap.String("email", body.Email), zap.Error(err))
-			res.ERROR(w, common.ErrAuthFailed, http.StatusInternalServerError)
+			if !errors.Is(err, service.ErrAuthWrongCredential) {
+				logger.Error("Ошибка авторизации пользователя", zap.Error(err))
+				res.ERROR(w, r, err)
+				return
+			}
+
+			// Неверные учётные данные: учитываем попытку, но не сообщаем, существует ли email.
+			retryAfter, gErr := h.LoginGuard.RegisterFailure(ctx, body.Email, ip)
+			if gErr != nil {
+				logger.Error("Ошибка учёта неудачной попытки входа", zap.Error(gErr))
+			}
+			if retryAfter > 0 {
+				writeRetryAfter(w, retryAfter)
+				res.ERROR(w, r, common.ErrTooManyLoginAttempts)
+				return
+			}
+			res.ERROR(w, r, common.ErrInvalidCredentials)
 			return
 		}
 
+		if err := h.LoginGuard.RegisterSuccess(ctx, body.Email); err != nil {
+			logger.Error("Ошибка сброса счётчика неудачных попыток входа", zap.Uint("userID", user.ID), zap.Error(err))
+		}
+
 		token, err := jwt.NewJWT(h.Config.Auth.Secret).CreateToken(user)
 		if err != nil {
-			logger.Error("Ошибка при создании токена для авторизованного пользователя", zap.String("email", user.Email), zap.Error(err))
-			res.ERROR(w, common.ErrAuthFailed, http.StatusInternalServerError)
+			logger.Error("Ошибка при создании токена для авторизованного пользователя", zap.Uint("userID", user.ID), zap.Error(err))
+			res.ERROR(w, r, common.ErrAuthFailed)
 			return
 		}
 
+		h.startSession(w, r, user)
+
 		data := payload.SinginResponse{
 			Token: token,
 		}
-		logger.Info("Пользователь успешно авторизован", zap.String("email", body.Email))
+		logger.Info("Пользователь успешно авторизован", zap.Uint("userID", user.ID))
 		res.JSON(w, data, http.StatusOK)
 	}
 }
+
+// startSession создаёт cookie-сессию для веб-интерфейса. Сессия из cookie
+// запроса, если она есть, сначала завершается, чтобы прежний токен нельзя было
+// использовать после входа. Ошибка не прерывает вход: JWT в ответе остаётся
+// рабочим для API.
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, user *models.User) {
+	if cookie, err := r.Cookie(h.Config.Session.CookieName); err == nil {
+		if err := h.Sessions.Destroy(r.Context(), cookie.Value); err != nil {
+			logger.Error("Ошибка при завершении прежней сессии", zap.Uint("userID", user.ID), zap.Error(err))
+		}
+	}
+
+	token, _, err := h.Sessions.Create(r.Context(), user, req.ClientIP(r, h.Config.TrustProxy), r.UserAgent())
+	if err != nil {
+		logger.Error("Ошибка при создании сессии", zap.Uint("userID", user.ID), zap.Error(err))
+		middleware.ClearSessionCookie(w, h.Config)
+		return
+	}
+	middleware.SetSessionCookie(w, h.Config, token)
+}
+
+// writeRetryAfter выставляет заголовок Retry-After в секундах, округляя вверх.
+func writeRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/service"
+)
+
+// fakeSessions keeps session tokens in memory.
+type fakeSessions struct {
+	tokens    map[string]uint
+	next      int
+	createErr error
+}
+
+func (s *fakeSessions) Create(_ context.Context, user *models.User, _, _ string) (string, *models.Session, error) {
+	if s.createErr != nil {
+		return "", nil, s.createErr
+	}
+	s.next++
+	token := fmt.Sprintf("token-%d", s.next)
+	s.tokens[token] = user.ID
+	return token, &models.Session{}, nil
+}
+
+func (s *fakeSessions) Resolve(_ context.Context, token string) (*models.Session, *models.User, error) {
+	id, ok := s.tokens[token]
+	if !ok {
+		return nil, nil, errors.New("session not found")
+	}
+	return &models.Session{}, &models.User{ID: id}, nil
+}
+
+func (s *fakeSessions) Destroy(_ context.Context, token string) error {
+	delete(s.tokens, token)
+	return nil
+}
+
+func TestStartSessionReplacesExistingSession(t *testing.T) {
+	cfg := &config.Config{Session: config.SessionConfig{CookieName: "sid"}}
+	tests := []struct {
+		name       string
+		createErr  error
+		wantCookie string
+	}{
+		{name: "new session", wantCookie: "token-1"},
+		{name: "create fails", createErr: errors.New("connection reset"), wantCookie: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessions{tokens: map[string]uint{"old": 1}, createErr: tt.createErr}
+			h := &AuthHandler{Config: cfg, Sessions: sessions}
+
+			r := httptest.NewRequest(http.MethodPost, "/auth/signin", nil)
+			r.AddCookie(&http.Cookie{Name: "sid", Value: "old"})
+			w := httptest.NewRecorder()
+			h.startSession(w, r, &models.User{ID: 2})
+
+			if _, ok := sessions.tokens["old"]; ok {
+				t.Error("the session from the request cookie is still valid")
+			}
+			cookies := w.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != "sid" {
+				t.Fatalf("cookies = %v, want one sid cookie", cookies)
+			}
+			if cookies[0].Value != tt.wantCookie {
+				t.Errorf("sid = %q, want %q", cookies[0].Value, tt.wantCookie)
+			}
+			if tt.wantCookie != "" && sessions.tokens[tt.wantCookie] != 2 {
+				t.Errorf("new session belongs to user %d, want 2", sessions.tokens[tt.wantCookie])
+			}
+		})
+	}
+}
+
+// fakeLoginGuard locks sign-in for checkLocked and answers a failed attempt
+// with failLocked.
+type fakeLoginGuard struct {
+	service.LoginGuardServ
+	checkLocked time.Duration
+	failLocked  time.Duration
+}
+
+func (g *fakeLoginGuard) Check(context.Context, string, string) (time.Duration, error) {
+	if g.checkLocked > 0 {
+		return g.checkLocked, service.ErrLoginLocked
+	}
+	return 0, nil
+}
+
+func (g *fakeLoginGuard) RegisterFailure(context.Context, string, string) (time.Duration, error) {
+	return g.failLocked, nil
+}
+
+// wrongPassword rejects every sign-in.
+type wrongPassword struct {
+	service.AuthServ
+}
+
+func (wrongPassword) Login(context.Context, string, string, models.Role) (*models.User, error) {
+	return nil, service.ErrAuthWrongCredential
+}
+
+func TestSignInRetryAfter(t *testing.T) {
+	tests := []struct {
+		name           string
+		guard          *fakeLoginGuard
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{name: "locked", guard: &fakeLoginGuard{checkLocked: 90*time.Second + time.Millisecond}, wantStatus: http.StatusTooManyRequests, wantRetryAfter: "91"},
+		{name: "locked by this attempt", guard: &fakeLoginGuard{failLocked: time.Minute}, wantStatus: http.StatusTooManyRequests, wantRetryAfter: "60"},
+		{name: "wrong password", guard: &fakeLoginGuard{}, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &AuthHandler{Config: &config.Config{}, AuthService: wrongPassword{}, LoginGuard: tt.guard}
+			body := `{"email":"user@example.com","password":"secret","role":"user"}`
+			r := httptest.NewRequest(http.MethodPost, "/auth/signin", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			h.SignIn()(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+		})
+	}
+}
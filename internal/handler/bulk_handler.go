@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// BulkHandlerDeps - зависимости для создания экземпляра BulkHandler.
+type BulkHandlerDeps struct {
+	Config      *config.Config
+	BulkService service.BulkServ
+}
+
+// BulkHandler - обработчик массового создания ссылок из JSON или CSV.
+type BulkHandler struct {
+	Config      *config.Config
+	BulkService service.BulkServ
+}
+
+// NewBulkHandler регистрирует маршруты массового создания ссылок и привязывает их к методам BulkHandler.
+func NewBulkHandler(router Router, deps BulkHandlerDeps) {
+	handler := &BulkHandler{
+		Config:      deps.Config,
+		BulkService: deps.BulkService,
+	}
+
+	router.Handle("POST /users/links/bulk", middleware.IsAuth(handler.Create(), deps.Config))
+	router.Handle("GET /users/links/bulk/jobs/{id}", middleware.IsAuth(handler.Job(), deps.Config))
+}
+
+// bulkJobResponse - состояние задачи массового создания в ответе.
+type bulkJobResponse struct {
+	ID         string            `json:"id,omitempty"`
+	Mode       string            `json:"mode"`
+	Status     string            `json:"status"`
+	Total      int               `json:"total"`
+	Processed  int               `json:"processed"`
+	Created    int               `json:"created"`
+	Failed     int               `json:"failed"`
+	Results    []bulkRowResponse `json:"results,omitempty"`
+	Error      *res.Problem      `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// bulkRowResponse - результат строки: созданная ссылка или ошибка.
+type bulkRowResponse struct {
+	Row   int          `json:"row"`
+	Link  *models.Link `json:"link,omitempty"`
+	Error *res.Problem `json:"error,omitempty"`
+}
+
+// newBulkJobResponse собирает ответ по задаче, ошибки переводятся на язык запроса.
+func newBulkJobResponse(r *http.Request, job *payload.BulkJob) bulkJobResponse {
+	resp := bulkJobResponse{
+		ID:         job.ID,
+		Mode:       job.Mode,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Created:    job.Created,
+		Failed:     job.Failed,
+		Results:    make([]bulkRowResponse, len(job.Results)),
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Err != nil {
+		problem := res.NewProblem(r, common.AsAppError(job.Err))
+		resp.Error = &problem
+	}
+	for i, result := range job.Results {
+		resp.Results[i] = bulkRowResponse{Row: result.Row, Link: result.Link}
+		if result.Err != nil {
+			problem := res.NewProblem(r, common.AsAppError(result.Err))
+			resp.Results[i].Error = &problem
+		}
+	}
+	return resp
+}
+
+// Create метод для массового создания ссылок текущего пользователя. Тело - JSON-массив
+// ссылок, CSV (text/csv) или форма с CSV-файлом в поле file. Параметр mode выбирает
+// режим: partial (по умолчанию) или atomic; async=true или большой файл запускают
+// фоновую задачу, тогда ответ 202 со ссылкой на её состояние в Location.
+func (h *BulkHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		query := r.URL.Query()
+		mode := query.Get("mode")
+		switch mode {
+		case "":
+			mode = payload.BulkModePartial
+		case payload.BulkModePartial, payload.BulkModeAtomic:
+		default:
+			res.ERROR(w, r, common.ErrInvalidParam.WithField("mode", "oneof", payload.BulkModePartial+" "+payload.BulkModeAtomic))
+			return
+		}
+		var async bool
+		if v := query.Get("async"); v != "" {
+			var err error
+			if async, err = strconv.ParseBool(v); err != nil {
+				res.ERROR(w, r, common.ErrInvalidParam.WithField("async", "invalid_type", "boolean"))
+				return
+			}
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, h.Config.Bulk.MaxBytes)
+		rows, err := h.readRows(r)
+		if err != nil {
+			logger.Error("Ошибка разбора строк для массового создания ссылок", zap.Uint("user_id", userID), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		job, err := h.BulkService.Start(r.Context(), userID, mode, rows, async)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		switch {
+		case job.Status == payload.BulkJobPending:
+			w.Header().Set("Location", "/users/links/bulk/jobs/"+job.ID)
+			res.JSON(w, newBulkJobResponse(r, job), http.StatusAccepted)
+		case job.Err != nil:
+			res.JSON(w, newBulkJobResponse(r, job), common.KindOf(job.Err).Status())
+		default:
+			res.JSON(w, newBulkJobResponse(r, job), http.StatusOK)
+		}
+	}
+}
+
+// Job метод для получения состояния фоновой задачи массового создания ссылок.
+func (h *BulkHandler) Job() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		job, err := h.BulkService.Job(userID, r.PathValue("id"))
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, newBulkJobResponse(r, job), http.StatusOK)
+	}
+}
+
+// readRows читает строки запроса по его Content-Type и проверяет каждую.
+// Ошибка строки попадает в саму строку, ошибка всего тела возвращается.
+func (h *BulkHandler) readRows(r *http.Request) ([]payload.BulkLinkRow, error) {
+	maxRows := h.Config.Bulk.MaxRows
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return readBulkCSV(r.Body, maxRows)
+	case "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, common.ErrBodyTooLarge.Wrap(err)
+			}
+			return nil, common.ErrRequestBodyParse.WithField("file", "required", "").Wrap(err)
+		}
+		defer file.Close()
+		return readBulkCSV(file, maxRows)
+	case "", "application/json":
+		links, err := req.Decode[[]payload.BulkLinkRequest](r.Body)
+		if err != nil {
+			return nil, req.DecodeError(err)
+		}
+		if len(links) > maxRows {
+			return nil, common.ErrBulkTooManyRows
+		}
+		rows := make([]payload.BulkLinkRow, len(links))
+		for i, link := range links {
+			rows[i] = newBulkRow(i+1, link, nil)
+		}
+		return rows, nil
+	}
+	return nil, common.ErrRequestBodyParse
+}
+
+// bulkCSVColumns - порядок колонок CSV без строки заголовка.
+var bulkCSVColumns = []string{"url", "alias", "tags", "expiry"}
+
+// readBulkCSV читает строки из CSV. Если первая строка начинается с "url",
+// это заголовок, и колонки берутся из него в любом порядке. Номер строки в
+// результате - номер строки файла.
+func readBulkCSV(body io.Reader, maxRows int) ([]payload.BulkLinkRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	columns := bulkCSVColumns
+	var rows []payload.BulkLinkRow
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, req.DecodeError(err)
+		}
+		if first {
+			// Excel сохраняет CSV в UTF-8 с BOM.
+			record[0] = strings.TrimPrefix(record[0], "\uFEFF")
+			if strings.EqualFold(strings.TrimSpace(record[0]), "url") {
+				if columns, err = bulkCSVHeader(record); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if len(rows) == maxRows {
+			return nil, common.ErrBulkTooManyRows
+		}
+		line, _ := reader.FieldPos(0)
+		link, err := bulkCSVLink(columns, record)
+		rows = append(rows, newBulkRow(line, link, err))
+	}
+	return rows, nil
+}
+
+// bulkCSVHeader читает колонки из строки заголовка.
+func bulkCSVHeader(record []string) ([]string, error) {
+	columns := make([]string, len(record))
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "url", "alias", "tags", "expiry", "expires_at":
+		default:
+			return nil, common.ErrRequestBodyParse.WithField(name, "unknown_field", "")
+		}
+		columns[i] = name
+	}
+	return columns, nil
+}
+
+// bulkCSVLink собирает ссылку из строки CSV. Теги разделяются запятыми или
+// точками с запятой, срок - дата (начало дня по UTC) или время в RFC 3339.
+func bulkCSVLink(columns, record []string) (payload.BulkLinkRequest, error) {
+	var link payload.BulkLinkRequest
+	for i, value := range record {
+		if i >= len(columns) {
+			break
+		}
+		value = strings.TrimSpace(value)
+		switch columns[i] {
+		case "url":
+			link.URL = value
+		case "alias":
+			link.Alias = value
+		case "tags":
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					link.Tags = append(link.Tags, tag)
+				}
+			}
+		case "expiry", "expires_at":
+			if value == "" {
+				continue
+			}
+			expiresAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				if expiresAt, err = time.Parse(time.DateOnly, value); err != nil {
+					return link, common.ErrInvalidRequest.WithField("expires_at", "invalid_type", "date")
+				}
+			}
+			link.ExpiresAt = &expiresAt
+		}
+	}
+	return link, nil
+}
+
+// newBulkRow проверяет строку так же, как тело запроса на создание одной ссылки.
+func newBulkRow(n int, link payload.BulkLinkRequest, err error) payload.BulkLinkRow {
+	if err == nil {
+		if err = req.IsValidate(link); err != nil {
+			err = req.ValidationError(err)
+		}
+	}
+	return payload.BulkLinkRow{Row: n, Link: link, Err: err}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+)
+
+func TestReadBulkCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		csv       string
+		wantRows  []int // номера строк файла
+		wantURLs  []string
+		wantAlias string // alias первой строки
+		wantTags  []string
+		wantErr   []bool
+	}{
+		{
+			name:     "no header",
+			csv:      "https://a.example,promo,\"go,web\",2030-01-02\nhttps://b.example\n",
+			wantRows: []int{1, 2}, wantURLs: []string{"https://a.example", "https://b.example"},
+			wantAlias: "promo", wantTags: []string{"go", "web"}, wantErr: []bool{false, false},
+		},
+		{
+			name:     "header columns in any order",
+			csv:      "URL,tags,alias\nhttps://a.example,go;web,promo\n",
+			wantRows: []int{2}, wantURLs: []string{"https://a.example"},
+			wantAlias: "promo", wantTags: []string{"go", "web"}, wantErr: []bool{false},
+		},
+		{
+			name:     "a header must start with url",
+			csv:      "tags,url\ngo,https://a.example\n",
+			wantRows: []int{1, 2}, wantURLs: []string{"tags", "go"},
+			wantAlias: "url", wantErr: []bool{true, true},
+		},
+		{
+			name:     "header starting with url",
+			csv:      "url,alias,tags\nhttps://a.example,promo,go;web\n\nhttps://b.example\n",
+			wantRows: []int{2, 4}, wantURLs: []string{"https://a.example", "https://b.example"},
+			wantAlias: "promo", wantTags: []string{"go", "web"}, wantErr: []bool{false, false},
+		},
+		{
+			name:     "byte order mark",
+			csv:      "\uFEFFurl,alias\nhttps://a.example,promo\n",
+			wantRows: []int{2}, wantURLs: []string{"https://a.example"}, wantAlias: "promo", wantErr: []bool{false},
+		},
+		{
+			name:     "invalid rows are reported, not dropped",
+			csv:      "url,expiry\nnot a url,\nhttps://a.example,tomorrow\nhttps://b.example,2030-01-02T10:00:00Z\n",
+			wantRows: []int{2, 3, 4}, wantErr: []bool{true, true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readBulkCSV(strings.NewReader(tt.csv), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("rows = %d, want %d", len(rows), len(tt.wantRows))
+			}
+			for i, row := range rows {
+				if row.Row != tt.wantRows[i] {
+					t.Errorf("row %d: line %d, want %d", i, row.Row, tt.wantRows[i])
+				}
+				if tt.wantURLs != nil && row.Link.URL != tt.wantURLs[i] {
+					t.Errorf("row %d: url %q, want %q", i, row.Link.URL, tt.wantURLs[i])
+				}
+				if (row.Err != nil) != tt.wantErr[i] {
+					t.Errorf("row %d: error %v, want error %v", i, row.Err, tt.wantErr[i])
+				}
+			}
+			if rows[0].Link.Alias != tt.wantAlias {
+				t.Errorf("alias = %q, want %q", rows[0].Link.Alias, tt.wantAlias)
+			}
+			if strings.Join(rows[0].Link.Tags, "|") != strings.Join(tt.wantTags, "|") {
+				t.Errorf("tags = %v, want %v", rows[0].Link.Tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func TestReadBulkCSVExpiry(t *testing.T) {
+	rows, err := readBulkCSV(strings.NewReader("https://a.example,,,2030-01-02\n"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	if got := rows[0].Link.ExpiresAt; got == nil || !got.Equal(want) {
+		t.Errorf("expires_at = %v, want %v", got, want)
+	}
+}
+
+func TestReadBulkCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want error
+	}{
+		{name: "unknown column", csv: "url,owner\nhttps://a.example,me\n", want: common.ErrRequestBodyParse},
+		{name: "row limit", csv: "url\nhttps://a.example\nhttps://b.example\nhttps://c.example\n", want: common.ErrBulkTooManyRows},
+		{name: "broken quotes", csv: "\"https://a.example\n", want: common.ErrRequestBodyParse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readBulkCSV(strings.NewReader(tt.csv), 2)
+			if common.AsAppError(err).Code != common.AsAppError(tt.want).Code {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// The header does not count against the limit.
+	if rows, err := readBulkCSV(strings.NewReader("url\nhttps://a.example\nhttps://b.example\n"), 2); err != nil || len(rows) != 2 {
+		t.Errorf("rows = %d, %v; want 2 rows at the limit", len(rows), err)
+	}
+}
+
+func TestReadRowsJSONLimit(t *testing.T) {
+	h := &BulkHandler{Config: &config.Config{Bulk: config.BulkConfig{MaxRows: 2}}}
+	read := func(body string) error {
+		r := httptest.NewRequest(http.MethodPost, "/users/links/bulk", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		_, err := h.readRows(r)
+		return err
+	}
+	if err := read(`[{"url":"https://a.example"},{"url":"https://b.example"}]`); err != nil {
+		t.Errorf("two rows: %v", err)
+	}
+	if err := read(`[{"url":"https://a.example"},{"url":"https://b.example"},{"url":"https://c.example"}]`); !errors.Is(err, common.ErrBulkTooManyRows) {
+		t.Errorf("three rows = %v, want ErrBulkTooManyRows", err)
+	}
+}
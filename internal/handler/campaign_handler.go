@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+	"shorty/pkg/parse"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// CampaignHandlerDeps - зависимости для создания экземпляра CampaignHandler.
+type CampaignHandlerDeps struct {
+	Config          *config.Config
+	CampaignService service.CampaignServ
+	StatService     service.StatServ
+}
+
+// CampaignHandler - обработчик кампаний пользователя.
+type CampaignHandler struct {
+	Config          *config.Config
+	CampaignService service.CampaignServ
+	StatService     service.StatServ
+}
+
+// NewCampaignHandler регистрирует маршруты кампаний и привязывает их к методам CampaignHandler.
+func NewCampaignHandler(router Router, deps CampaignHandlerDeps) {
+	handler := &CampaignHandler{
+		Config:          deps.Config,
+		CampaignService: deps.CampaignService,
+		StatService:     deps.StatService,
+	}
+
+	router.Handle("POST /users/campaigns", middleware.IsAuth(handler.Create(), deps.Config))
+	router.Handle("GET /users/campaigns", middleware.IsAuth(handler.GetAll(), deps.Config))
+	router.Handle("GET /users/campaigns/stats", middleware.IsAuth(handler.Stats(), deps.Config))
+	router.Handle("DELETE /users/campaigns/{id}", middleware.IsAuth(handler.Delete(), deps.Config))
+}
+
+// Create метод для создания кампании текущего пользователя.
+func (h *CampaignHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		body, err := req.HandleBody[payload.CreateCampaignRequest](&w, r)
+		if err != nil {
+			logger.Error("Ошибка парсинга тела запроса для создания кампании", zap.Error(err))
+			return
+		}
+		campaign, err := h.CampaignService.Create(r.Context(), userID, body.Name, body.Description)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, campaign, http.StatusCreated)
+	}
+}
+
+// GetAll метод для получения кампаний текущего пользователя.
+func (h *CampaignHandler) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		campaigns, err := h.CampaignService.GetAll(r.Context(), userID)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, campaigns, http.StatusOK)
+	}
+}
+
+// Stats метод для получения сводной статистики кампаний текущего пользователя за период from - to.
+func (h *CampaignHandler) Stats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		from, to, err := parse.DateRange(r)
+		if err != nil {
+			res.ERROR(w, r, common.ErrInvalidParam)
+			return
+		}
+		stats, err := h.StatService.CampaignStats(r.Context(), &userID, from, to)
+		if err != nil {
+			logger.Error("Ошибка получения статистики кампаний", zap.Uint("user_id", userID), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, stats, http.StatusOK)
+	}
+}
+
+// Delete метод для удаления кампании текущего пользователя. Ссылки кампании остаются.
+func (h *CampaignHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		id, err := parse.ParseID(r)
+		if err != nil {
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+		if err := h.CampaignService.Delete(r.Context(), id, userID); err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.MESSAGE(w, r, "msg.campaign_deleted", http.StatusOK)
+	}
+}
@@ -0,0 +1,385 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+)
+
+const (
+	dashboardPageSize = 20 // ссылок или пользователей на странице
+	sparklineDays     = 14 // дней в графике кликов
+)
+
+// dashboardResults - результаты действий, передаваемые через ?result=:
+// true - успех (уведомление), false - ошибка. Текст - "dashboard.<результат>".
+var dashboardResults = map[string]bool{
+	"updated":   true,
+	"blocked":   true,
+	"unblocked": true,
+	"deleted":   true,
+	"rejected":  false,
+	"failed":    false,
+}
+
+// DashboardData - данные страниц управления ссылками.
+type DashboardData struct {
+	Admin      bool
+	Tab        string // "links" или "users" в панели администратора
+	Links      []LinkRow
+	Users      []*models.User
+	Query      string
+	Sort       string
+	Days       int // период графика кликов в днях
+	Total      int64
+	Page       int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+	Self       string // адрес текущей страницы для возврата после действий
+}
+
+// LinkRow - строка таблицы ссылок.
+type LinkRow struct {
+	payload.LinkListItem
+	ShortURL  string
+	Sparkline string // точки ломаной SVG-графика кликов
+}
+
+// MyLinksPage показывает ссылки текущего пользователя.
+func (h *PageHandler) MyLinksPage(w http.ResponseWriter, r *http.Request) {
+	data := h.getAuthData(r)
+	session, ok := middleware.SessionFromContext(r.Context())
+	if !ok {
+		redirectToLogin(w, r)
+		return
+	}
+
+	ownerID := session.User.ID
+	dashboard, err := h.linksDashboard(r, &ownerID)
+	if err != nil {
+		h.Error(w, r, common.ErrInternal)
+		return
+	}
+	data.Title = data.T("title.links")
+	data.Page = "links"
+	data.Dashboard = dashboard
+	h.applyResult(r, &data)
+	h.renderLayout(w, http.StatusOK, data)
+}
+
+// AdminDashboardPage показывает администратору все ссылки или всех пользователей.
+func (h *PageHandler) AdminDashboardPage(w http.ResponseWriter, r *http.Request) {
+	data := h.getAuthData(r)
+	if !data.IsAuthenticated {
+		redirectToLogin(w, r)
+		return
+	}
+	if data.Role != string(models.RoleAdmin) {
+		h.Error(w, r, common.ErrNotFound)
+		return
+	}
+
+	var (
+		dashboard *DashboardData
+		err       error
+	)
+	if r.URL.Query().Get("tab") == "users" {
+		dashboard, err = h.usersDashboard(r)
+	} else {
+		dashboard, err = h.linksDashboard(r, nil)
+	}
+	if err != nil {
+		h.Error(w, r, common.ErrInternal)
+		return
+	}
+	dashboard.Admin = true
+	data.Title = data.T("title.admin")
+	data.Page = "admin"
+	data.Dashboard = dashboard
+	h.applyResult(r, &data)
+	h.renderLayout(w, http.StatusOK, data)
+}
+
+// UpdateLinkForm меняет адрес назначения ссылки.
+func (h *PageHandler) UpdateLinkForm(w http.ResponseWriter, r *http.Request) {
+	h.linkAction(w, r, false, func(ctx context.Context, link *models.Link) (string, error) {
+		link.Url = strings.TrimSpace(r.PostFormValue("url"))
+		if link.Url == "" {
+			return "failed", nil
+		}
+		if _, err := h.linkService.Update(ctx, link); err != nil {
+			if errors.Is(err, service.ErrURLRejected) {
+				return "rejected", nil
+			}
+			return "", err
+		}
+		return "updated", nil
+	})
+}
+
+// BlockLinkForm блокирует ссылку. Блокировка - инструмент модерации,
+// поэтому она доступна только администратору.
+func (h *PageHandler) BlockLinkForm(w http.ResponseWriter, r *http.Request) {
+	h.linkAction(w, r, true, func(ctx context.Context, link *models.Link) (string, error) {
+		_, err := h.linkService.Block(ctx, link.ID)
+		return "blocked", err
+	})
+}
+
+// UnblockLinkForm снимает блокировку со ссылки, только для администратора.
+func (h *PageHandler) UnblockLinkForm(w http.ResponseWriter, r *http.Request) {
+	h.linkAction(w, r, true, func(ctx context.Context, link *models.Link) (string, error) {
+		_, err := h.linkService.UnBlock(ctx, link.ID)
+		return "unblocked", err
+	})
+}
+
+// DeleteLinkForm удаляет ссылку.
+func (h *PageHandler) DeleteLinkForm(w http.ResponseWriter, r *http.Request) {
+	h.linkAction(w, r, false, func(ctx context.Context, link *models.Link) (string, error) {
+		return "deleted", h.linkService.Delete(ctx, link.ID)
+	})
+}
+
+// BlockUserForm блокирует пользователя из панели администратора.
+func (h *PageHandler) BlockUserForm(w http.ResponseWriter, r *http.Request) {
+	h.userAction(w, r, func(ctx context.Context, userID uint) (string, error) {
+		_, err := h.userService.Block(ctx, userID)
+		return "blocked", err
+	})
+}
+
+// UnblockUserForm снимает блокировку с пользователя из панели администратора.
+func (h *PageHandler) UnblockUserForm(w http.ResponseWriter, r *http.Request) {
+	h.userAction(w, r, func(ctx context.Context, userID uint) (string, error) {
+		_, err := h.userService.UnBlock(ctx, userID)
+		return "unblocked", err
+	})
+}
+
+// linkAction выполняет действие над ссылкой из формы и возвращает на страницу,
+// с которой пришёл запрос. Действие доступно владельцу ссылки и администратору,
+// с adminOnly - только администратору.
+func (h *PageHandler) linkAction(w http.ResponseWriter, r *http.Request, adminOnly bool, action func(ctx context.Context, link *models.Link) (string, error)) {
+	session, ok := middleware.SessionFromContext(r.Context())
+	if !ok {
+		redirectToLogin(w, r)
+		return
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.Error(w, r, common.ErrInvalidID)
+		return
+	}
+
+	link, err := h.linkService.FindByID(r.Context(), uint(id))
+	isAdmin := session.User.Role == models.RoleAdmin
+	if err != nil || (!isAdmin && (link.UserID == nil || *link.UserID != session.User.ID)) {
+		h.Error(w, r, common.ErrLinkNotFound)
+		return
+	}
+	if adminOnly && !isAdmin {
+		h.Error(w, r, common.ErrLinkNotFound)
+		return
+	}
+
+	result, err := action(adminContext(r.Context(), session.User), link)
+	if err != nil {
+		logger.Error("Ошибка действия над ссылкой", zap.Uint("id", link.ID), zap.String("path", r.URL.Path), zap.Error(err))
+		result = "failed"
+	}
+	h.redirectWithResult(w, r, result)
+}
+
+// userAction выполняет действие администратора над пользователем из формы.
+func (h *PageHandler) userAction(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, userID uint) (string, error)) {
+	session, ok := middleware.SessionFromContext(r.Context())
+	if !ok {
+		redirectToLogin(w, r)
+		return
+	}
+	if session.User.Role != models.RoleAdmin {
+		h.Error(w, r, common.ErrNotFound)
+		return
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		h.Error(w, r, common.ErrInvalidID)
+		return
+	}
+
+	result, err := action(adminContext(r.Context(), session.User), uint(id))
+	if err != nil {
+		logger.Error("Ошибка действия над пользователем", zap.Uint64("id", id), zap.String("path", r.URL.Path), zap.Error(err))
+		result = "failed"
+	}
+	h.redirectWithResult(w, r, result)
+}
+
+// linksDashboard собирает страницу ссылок. nil ownerID - ссылки всех пользователей.
+func (h *PageHandler) linksDashboard(r *http.Request, ownerID *uint) (*DashboardData, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	filter := payload.LinkListFilter{
+		OwnerID: ownerID,
+		Query:   strings.TrimSpace(query.Get("q")),
+		Sort:    query.Get("sort"),
+	}
+	if filter.Sort == "" {
+		filter.Sort = payload.LinkSortNewest
+	}
+
+	total, err := h.linkService.CountSearch(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	d := newDashboard(r, "links", total)
+	d.Query = filter.Query
+	d.Sort = filter.Sort
+	d.Days = sparklineDays
+
+	items, err := h.linkService.Search(ctx, filter, dashboardPageSize, (d.Page-1)*dashboardPageSize)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	clicks, err := h.statService.DailyClicks(ctx, ids, sparklineDays)
+	if err != nil {
+		// Без графиков страница всё равно полезна.
+		logger.Error("Ошибка получения кликов по дням", zap.Error(err))
+	}
+
+	base := getScheme(r) + "://" + r.Host + "/"
+	d.Links = make([]LinkRow, len(items))
+	for i, item := range items {
+		d.Links[i] = LinkRow{
+			LinkListItem: item,
+			ShortURL:     base + item.Hash,
+			Sparkline:    sparkline(clicks[item.ID]),
+		}
+	}
+	return d, nil
+}
+
+// usersDashboard собирает страницу пользователей панели администратора.
+func (h *PageHandler) usersDashboard(r *http.Request) (*DashboardData, error) {
+	ctx := r.Context()
+	total, err := h.userService.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d := newDashboard(r, "users", total)
+	users, err := h.userService.GetAll(ctx, dashboardPageSize, (d.Page-1)*dashboardPageSize)
+	if err != nil {
+		return nil, err
+	}
+	d.Users = users
+	return d, nil
+}
+
+// newDashboard заполняет общие поля страницы: номер страницы и соседние адреса.
+func newDashboard(r *http.Request, tab string, total int64) *DashboardData {
+	totalPages := max(1, int(math.Ceil(float64(total)/dashboardPageSize)))
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	page = min(page, totalPages)
+
+	d := &DashboardData{
+		Tab:        tab,
+		Total:      total,
+		Page:       page,
+		TotalPages: totalPages,
+		Self:       r.URL.RequestURI(),
+	}
+	if page > 1 {
+		d.PrevURL = pageURL(r, page-1)
+	}
+	if page < totalPages {
+		d.NextURL = pageURL(r, page+1)
+	}
+	return d
+}
+
+// pageURL возвращает адрес текущей страницы с другим номером страницы.
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// sparkline возвращает точки ломаной для SVG с viewBox "0 0 100 24".
+func sparkline(values []int64) string {
+	if len(values) < 2 {
+		return ""
+	}
+	peak := int64(1)
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	step := 100.0 / float64(len(values)-1)
+	points := make([]string, len(values))
+	for i, v := range values {
+		y := 22 - float64(v)/float64(peak)*20
+		points[i] = fmt.Sprintf("%.1f,%.1f", float64(i)*step, y)
+	}
+	return strings.Join(points, " ")
+}
+
+// applyResult показывает сообщение о результате предыдущего действия.
+func (h *PageHandler) applyResult(r *http.Request, data *TemplateData) {
+	result := r.URL.Query().Get("result")
+	success, ok := dashboardResults[result]
+	switch {
+	case !ok:
+	case success:
+		data.Notice = data.T("dashboard." + result)
+	default:
+		data.Error = data.T("dashboard." + result)
+	}
+}
+
+// redirectWithResult возвращает на страницу из поля формы next с результатом действия.
+func (h *PageHandler) redirectWithResult(w http.ResponseWriter, r *http.Request, result string) {
+	next := r.PostFormValue("next")
+	// Разрешаем только относительные пути, чтобы не было open redirect.
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		next = "/links"
+	}
+	target, err := url.Parse(next)
+	if err != nil {
+		target = &url.URL{Path: "/links"}
+	}
+	query := target.Query()
+	query.Set("result", result)
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusSeeOther)
+}
+
+// adminContext кладёт администратора в контекст, чтобы его действия
+// попали в журнал аудита так же, как при вызове через API.
+func adminContext(ctx context.Context, user *models.User) context.Context {
+	if user.Role != models.RoleAdmin {
+		return ctx
+	}
+	return context.WithValue(ctx, common.UserContextKey, user)
+}
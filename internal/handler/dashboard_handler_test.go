@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/service"
+	"shorty/pkg/middleware"
+)
+
+// fakeDashboardLinks serves links by ID and records the actions taken on them.
+type fakeDashboardLinks struct {
+	service.LinkServ
+	links   map[uint]*models.Link
+	actions []string
+	admin   bool // the last action ran with an admin in the context
+}
+
+func (s *fakeDashboardLinks) FindByID(_ context.Context, id uint) (*models.Link, error) {
+	link, ok := s.links[id]
+	if !ok {
+		return nil, service.ErrLinkNotFound
+	}
+	return link, nil
+}
+
+func (s *fakeDashboardLinks) record(ctx context.Context, action string, id uint) {
+	s.actions = append(s.actions, action+" "+strconv.FormatUint(uint64(id), 10))
+	user, ok := ctx.Value(common.UserContextKey).(*models.User)
+	s.admin = ok && user.Role == models.RoleAdmin
+}
+
+func (s *fakeDashboardLinks) Block(ctx context.Context, id uint) (*models.Link, error) {
+	s.record(ctx, "block", id)
+	return s.links[id], nil
+}
+
+func (s *fakeDashboardLinks) UnBlock(ctx context.Context, id uint) (*models.Link, error) {
+	s.record(ctx, "unblock", id)
+	return s.links[id], nil
+}
+
+func (s *fakeDashboardLinks) Delete(ctx context.Context, id uint) error {
+	s.record(ctx, "delete", id)
+	return nil
+}
+
+// fakeDashboardUsers records blocked and unblocked users.
+type fakeDashboardUsers struct {
+	service.UserServ
+	actions []string
+}
+
+func (s *fakeDashboardUsers) Block(_ context.Context, id uint) (*models.User, error) {
+	s.actions = append(s.actions, "block "+strconv.FormatUint(uint64(id), 10))
+	return &models.User{ID: id, IsBlocked: true}, nil
+}
+
+func (s *fakeDashboardUsers) UnBlock(_ context.Context, id uint) (*models.User, error) {
+	s.actions = append(s.actions, "unblock "+strconv.FormatUint(uint64(id), 10))
+	return &models.User{ID: id}, nil
+}
+
+var (
+	dashboardOwner    = &models.User{ID: 1, Role: models.RoleUser}
+	dashboardStranger = &models.User{ID: 2, Role: models.RoleUser}
+	dashboardAdmin    = &models.User{ID: 3, Role: models.RoleAdmin}
+)
+
+func newTestDashboard() (*PageHandler, *fakeDashboardLinks, *fakeDashboardUsers) {
+	link := &models.Link{Url: "https://example.com/", Hash: "abc", UserID: &dashboardOwner.ID}
+	link.ID = 10
+	links := &fakeDashboardLinks{links: map[uint]*models.Link{link.ID: link}}
+	users := &fakeDashboardUsers{}
+	h := NewPageHandler(PageHandlerDeps{
+		Config:      &config.Config{},
+		LinkService: links,
+		UserService: users,
+	})
+	return h, links, users
+}
+
+// postForm sends a dashboard form as user; a nil user has no session.
+func postForm(handle http.HandlerFunc, user *models.User, id, next string) *httptest.ResponseRecorder {
+	form := url.Values{"next": {next}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.SetPathValue("id", id)
+	if user != nil {
+		data := &middleware.SessionData{Session: &models.Session{UserID: user.ID}, User: user}
+		r = r.WithContext(context.WithValue(r.Context(), middleware.ContextSessionKey, data))
+	}
+	w := httptest.NewRecorder()
+	handle(w, r)
+	return w
+}
+
+func TestDashboardLinkActions(t *testing.T) {
+	tests := []struct {
+		name       string
+		action     string
+		user       *models.User
+		wantStatus int
+		wantAction string // empty when nothing must happen
+	}{
+		{name: "admin blocks", action: "block", user: dashboardAdmin, wantStatus: http.StatusSeeOther, wantAction: "block 10"},
+		{name: "admin unblocks", action: "unblock", user: dashboardAdmin, wantStatus: http.StatusSeeOther, wantAction: "unblock 10"},
+		{name: "owner cannot block", action: "block", user: dashboardOwner, wantStatus: http.StatusNotFound},
+		{name: "owner cannot unblock", action: "unblock", user: dashboardOwner, wantStatus: http.StatusNotFound},
+		{name: "owner deletes", action: "delete", user: dashboardOwner, wantStatus: http.StatusSeeOther, wantAction: "delete 10"},
+		{name: "stranger cannot delete", action: "delete", user: dashboardStranger, wantStatus: http.StatusNotFound},
+		{name: "stranger cannot block", action: "block", user: dashboardStranger, wantStatus: http.StatusNotFound},
+		{name: "anonymous", action: "block", wantStatus: http.StatusSeeOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, links, _ := newTestDashboard()
+			handle := map[string]http.HandlerFunc{
+				"block":   h.BlockLinkForm,
+				"unblock": h.UnblockLinkForm,
+				"delete":  h.DeleteLinkForm,
+			}[tt.action]
+
+			w := postForm(handle, tt.user, "10", "/links?page=2")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantAction == "" {
+				if len(links.actions) != 0 {
+					t.Errorf("actions = %v, want none", links.actions)
+				}
+				return
+			}
+			if len(links.actions) != 1 || links.actions[0] != tt.wantAction {
+				t.Errorf("actions = %v, want [%s]", links.actions, tt.wantAction)
+			}
+			if links.admin != (tt.user.Role == models.RoleAdmin) {
+				t.Errorf("admin in the audit context = %v", links.admin)
+			}
+			result := map[string]string{"block": "blocked", "unblock": "unblocked", "delete": "deleted"}[tt.action]
+			if location := w.Header().Get("Location"); location != "/links?page=2&result="+result {
+				t.Errorf("Location = %q, want the page with result=%s", location, result)
+			}
+		})
+	}
+}
+
+func TestDashboardUserActions(t *testing.T) {
+	for _, user := range []*models.User{dashboardOwner, dashboardAdmin} {
+		h, _, users := newTestDashboard()
+		block := postForm(h.BlockUserForm, user, "2", "/admin/dashboard?tab=users")
+		unblock := postForm(h.UnblockUserForm, user, "2", "/admin/dashboard?tab=users")
+
+		if user.Role != models.RoleAdmin {
+			if block.Code != http.StatusNotFound || unblock.Code != http.StatusNotFound || len(users.actions) != 0 {
+				t.Errorf("user %d: statuses %d and %d, actions %v; want 404 and none", user.ID, block.Code, unblock.Code, users.actions)
+			}
+			continue
+		}
+		if got := strings.Join(users.actions, ", "); got != "block 2, unblock 2" {
+			t.Errorf("admin actions = %q", got)
+		}
+		if location := unblock.Header().Get("Location"); location != "/admin/dashboard?result=unblocked&tab=users" {
+			t.Errorf("Location = %q", location)
+		}
+	}
+}
+
+func TestDashboardRedirectStaysOnSite(t *testing.T) {
+	for _, next := range []string{"https://evil.example/", "//evil.example/", "/\\evil.example/", ""} {
+		h, _, _ := newTestDashboard()
+		w := postForm(h.DeleteLinkForm, dashboardOwner, "10", next)
+		if location := w.Header().Get("Location"); location != "/links?result=deleted" {
+			t.Errorf("next=%q: Location = %q, want /links", next, location)
+		}
+	}
+}
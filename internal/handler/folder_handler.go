@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+	"shorty/pkg/parse"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// FolderHandlerDeps - зависимости для создания экземпляра FolderHandler.
+type FolderHandlerDeps struct {
+	Config        *config.Config
+	FolderService service.FolderServ
+}
+
+// FolderHandler - обработчик папок и тегов пользователя.
+type FolderHandler struct {
+	Config        *config.Config
+	FolderService service.FolderServ
+}
+
+// NewFolderHandler регистрирует маршруты папок и тегов и привязывает их к методам FolderHandler.
+func NewFolderHandler(router Router, deps FolderHandlerDeps) {
+	handler := &FolderHandler{
+		Config:        deps.Config,
+		FolderService: deps.FolderService,
+	}
+
+	router.Handle("POST /users/folders", middleware.IsAuth(handler.Create(), deps.Config))
+	router.Handle("GET /users/folders", middleware.IsAuth(handler.GetAll(), deps.Config))
+	router.Handle("DELETE /users/folders/{id}", middleware.IsAuth(handler.Delete(), deps.Config))
+	router.Handle("GET /users/tags", middleware.IsAuth(handler.Tags(), deps.Config))
+}
+
+// Create метод для создания папки текущего пользователя.
+func (h *FolderHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		body, err := req.HandleBody[payload.CreateFolderRequest](&w, r)
+		if err != nil {
+			logger.Error("Ошибка парсинга тела запроса для создания папки", zap.Error(err))
+			return
+		}
+		folder, err := h.FolderService.Create(r.Context(), userID, body.Name)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, folder, http.StatusCreated)
+	}
+}
+
+// GetAll метод для получения папок текущего пользователя.
+func (h *FolderHandler) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		folders, err := h.FolderService.GetAll(r.Context(), userID)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, folders, http.StatusOK)
+	}
+}
+
+// Delete метод для удаления папки текущего пользователя. Ссылки папки остаются без папки.
+func (h *FolderHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		id, err := parse.ParseID(r)
+		if err != nil {
+			res.ERROR(w, r, common.ErrInvalidID)
+			return
+		}
+		if err := h.FolderService.Delete(r.Context(), id, userID); err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.MESSAGE(w, r, "msg.folder_deleted", http.StatusOK)
+	}
+}
+
+// Tags метод для получения тегов текущего пользователя с числом ссылок у каждого.
+func (h *FolderHandler) Tags() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
+		tags, err := h.FolderService.Tags(r.Context(), userID)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, tags, http.StatusOK)
+	}
+}
@@ -1,6 +1,9 @@
 package handler
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 type AdminHandl interface {
 	GetUsers() http.HandlerFunc
@@ -18,6 +21,18 @@ type AdminHandl interface {
 	GetTotalLinks() http.HandlerFunc
 	GetClickedLinkStats() http.HandlerFunc
 	GetAllLinksStats() http.HandlerFunc
+	GetCampaignStats() http.HandlerFunc
+	GetLockouts() http.HandlerFunc
+	ClearLockout() http.HandlerFunc
+	GetAuditLog() http.HandlerFunc
+	GetReports() http.HandlerFunc
+	DismissReports() http.HandlerFunc
+	BlockReportedLink() http.HandlerFunc
+	BlockReportedOwner() http.HandlerFunc
+	GetURLRejections() http.HandlerFunc
+	GetPolicyDomains() http.HandlerFunc
+	AddPolicyDomain() http.HandlerFunc
+	RemovePolicyDomain() http.HandlerFunc
 }
 
 type AuthHandl interface {
@@ -32,5 +47,51 @@ type UserHandl interface {
 	GetLinks() http.HandlerFunc
 	UpdateLink() http.HandlerFunc
 	DeleteLink() http.HandlerFunc
+	VariantStats() http.HandlerFunc
+	QRCode() http.HandlerFunc
 	Redirect() http.HandlerFunc
 }
+
+type CampaignHandl interface {
+	Create() http.HandlerFunc
+	GetAll() http.HandlerFunc
+	Stats() http.HandlerFunc
+	Delete() http.HandlerFunc
+}
+
+type FolderHandl interface {
+	Create() http.HandlerFunc
+	GetAll() http.HandlerFunc
+	Delete() http.HandlerFunc
+	Tags() http.HandlerFunc
+}
+
+type BulkHandl interface {
+	Create() http.HandlerFunc
+	Job() http.HandlerFunc
+}
+
+type ReportHandl interface {
+	Report() http.HandlerFunc
+}
+
+type TrackingHandl interface {
+	Conversion() http.HandlerFunc
+	Pixel() http.HandlerFunc
+}
+
+// ErrorPages отвечает на ошибки страницей или JSON в зависимости от клиента.
+type ErrorPages interface {
+	Error(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Renderer рендерит HTML-страницу по имени.
+type Renderer interface {
+	Render(w io.Writer, page string, data any) error
+}
+
+// Router регистрирует маршруты обработчиков; ему удовлетворяет *http.ServeMux.
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
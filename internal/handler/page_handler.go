@@ -1,57 +1,157 @@
 package handler
 
 import (
-	"html/template"
+	"bytes"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
 	"shorty/internal/service"
-	"shorty/pkg/jwt"
+	"shorty/pkg/event"
+	"shorty/pkg/i18n"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+	"shorty/pkg/res"
 )
 
 type TemplateData struct {
 	Title           string
 	IsAuthenticated bool
 	Role            string
-	Page            string // "index" или "stats"
+	UserName        string
+	CSRFToken       string
+	Page            string       // имя страницы - файла шаблона без расширения
+	Hash            string       // хеш ссылки на странице жалобы
+	Link            *models.Link // ссылка на странице предпросмотра
+	ContinueURL     string       // переход по ссылке со страницы предпросмотра
+	Dashboard       *DashboardData
+	Notice          string
+	Error           string
+	Status          int // код ответа на странице ошибки
+	Brand           config.BrandingConfig
+	Locale          string // язык страницы, см. middleware.Locale
+}
+
+// T переводит ключ каталога на язык страницы.
+func (d TemplateData) T(key string, args ...any) string {
+	return i18n.T(d.Locale, key, args...)
+}
+
+// PageHandlerDeps - зависимости для создания экземпляра PageHandler.
+type PageHandlerDeps struct {
+	Config      *config.Config
+	Views       Renderer
+	LinkService service.LinkServ
+	StatService service.StatServ
+	UserService service.UserServ
+	Sessions    service.SessionServ
+	EventBus    *event.EventBus
 }
 
 type PageHandler struct {
-	jwtService  *jwt.JWT
+	config      *config.Config
+	views       Renderer
 	linkService service.LinkServ
+	statService service.StatServ
+	userService service.UserServ
+	sessions    service.SessionServ
+	redirector  *Redirector
 }
 
-func NewPageHandler(jwtSvc *jwt.JWT, linkSvc service.LinkServ) *PageHandler {
-	return &PageHandler{jwtService: jwtSvc, linkService: linkSvc}
+func NewPageHandler(deps PageHandlerDeps) *PageHandler {
+	return &PageHandler{
+		config:      deps.Config,
+		views:       deps.Views,
+		linkService: deps.LinkService,
+		statService: deps.StatService,
+		userService: deps.UserService,
+		sessions:    deps.Sessions,
+		redirector:  NewRedirector(deps.Config, deps.EventBus),
+	}
 }
 
-func (h *PageHandler) renderLayout(w http.ResponseWriter, data TemplateData) {
-	// парсим layout + header + оба контент-шаблона
-	paths := []string{
-		"web/templates/layout.html",
-		"web/templates/header.html",
-		"web/templates/index.html",
-		"web/templates/stats.html",
-		"web/templates/settings.html",
-		"web/templates/login.html",
-		"web/templates/register.html",
+// renderLayout рендерит страницу data.Page в общем макете с указанным кодом ответа.
+func (h *PageHandler) renderLayout(w http.ResponseWriter, status int, data TemplateData) {
+	// Страница собирается в буфер, чтобы при ошибке шаблона ещё можно было ответить 500.
+	var buf bytes.Buffer
+	if err := h.views.Render(&buf, data.Page, data); err != nil {
+		logger.Error("Ошибка рендеринга страницы", zap.String("page", data.Page), zap.Error(err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
-	tmpl := template.Must(template.ParseFiles(paths...))
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// errorPages - ключи каталога для страниц ошибок по коду ошибки;
+// заголовок и текст лежат под <ключ>.title и <ключ>.message.
+var errorPages = map[string]string{
+	common.ErrLinkNotFound.Code:    "error.not_found",
+	common.ErrNotFound.Code:        "error.not_found",
+	common.ErrLinkBlocked.Code:     "error.blocked",
+	common.ErrLinkExpired.Code:     "error.expired",
+	common.ErrTooManyRequests.Code: "error.rate_limited",
+}
 
-	if err := tmpl.ExecuteTemplate(w, "layout.html", data); err != nil {
-		http.Error(w, "template error: "+err.Error(), http.StatusInternalServerError)
+// Error отвечает на ошибку страницей в оформлении сервиса, если клиент -
+// браузер, и problem+json через pkg/res для API-клиентов.
+func (h *PageHandler) Error(w http.ResponseWriter, r *http.Request, err error) {
+	if !res.WantsHTML(r) {
+		res.ERROR(w, r, err)
+		return
+	}
+	appErr := common.AsAppError(err)
+	status := appErr.Kind.Status()
+	page, ok := errorPages[appErr.Code]
+	if !ok {
+		page = "error.generic"
 	}
+
+	data := h.getAuthData(r)
+	data.Title = data.T(page + ".title")
+	data.Page = "error"
+	data.Status = status
+	data.Error = data.T(page + ".message")
+	h.renderLayout(w, status, data)
 }
 
+// HomePage рендерит главную страницу, а по пути "/{hash}" переходит по ссылке.
+// "/{hash}+" или "?preview=1" показывают страницу предпросмотра вместо редиректа,
+// для ссылок с AlwaysPreview она показывается всегда; "?go=1" - переход со
+// страницы предпросмотра. Хвост пути "/{hash}/..." принимается только ссылками
+// с пробросом пути.
 func (h *PageHandler) HomePage(w http.ResponseWriter, r *http.Request) {
-	// Если путь не ровно "/", пробуем редирект по хешу
 	if r.URL.Path != "/" {
-		hash := strings.TrimPrefix(r.URL.Path, "/")
-		if link, err := h.linkService.GetByHash(r.Context(), hash); err == nil {
-			http.Redirect(w, r, link.Url, http.StatusFound)
+		hash, extraPath := splitLinkPath(r)
+		query := r.URL.Query()
+		preview := query.Get(previewParam) == "1"
+		if strings.HasSuffix(hash, "+") {
+			hash = strings.TrimSuffix(hash, "+")
+			preview = true
+		}
+		// "Продолжить" на странице предпросмотра: переход уже подтверждён.
+		confirmed := query.Get(continueParam) == "1"
+
+		link, err := h.linkService.Lookup(r.Context(), hash)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+		if extraPath != "" && !link.ForwardPath {
+			h.Error(w, r, common.ErrLinkNotFound)
 			return
 		}
-		http.NotFound(w, r)
+		if !confirmed && (preview || link.AlwaysPreview) {
+			h.previewPage(w, r, link, extraPath)
+			return
+		}
+		h.redirector.Redirect(w, r, link, extraPath, http.StatusFound)
 		return
 	}
 
@@ -59,52 +159,130 @@ func (h *PageHandler) HomePage(w http.ResponseWriter, r *http.Request) {
 	data := h.getAuthData(r)
 	data.Title = "Shorty"
 	data.Page = "index"
-	h.renderLayout(w, data)
+	h.renderLayout(w, http.StatusOK, data)
+}
+
+// previewPage показывает адрес назначения, название и описание ссылки перед переходом.
+// Кнопка перехода ведёт обратно на короткую ссылку, чтобы переход прошёл через
+// Redirector: с учётом клика, таргетингом, A/B-вариантом и пробросом параметров.
+func (h *PageHandler) previewPage(w http.ResponseWriter, r *http.Request, link *models.Link, extraPath string) {
+	data := h.getAuthData(r)
+	data.Title = data.T("title.preview")
+	if link.Title != "" {
+		data.Title = link.Title
+	}
+	data.Page = "preview"
+	data.Hash = link.Hash
+	data.Link = link
+	data.ContinueURL = continueURL(r, link.Hash, extraPath)
+	if link.AlwaysPreview {
+		data.Notice = data.T("preview.notice")
+	}
+	// Страница предпросмотра не должна индексироваться вместо адреса назначения.
+	w.Header().Set("X-Robots-Tag", "noindex")
+	h.renderLayout(w, http.StatusOK, data)
+}
+
+// continueURL возвращает адрес перехода по ссылке со страницы предпросмотра:
+// тот же хвост пути и параметры запроса, без preview и с подтверждением перехода.
+func continueURL(r *http.Request, hash, extraPath string) string {
+	path := "/" + url.PathEscape(hash)
+	if extraPath != "" {
+		path += "/" + extraPath
+	}
+	query := r.URL.Query()
+	query.Del(previewParam)
+	query.Set(continueParam, "1")
+	return path + "?" + query.Encode()
 }
 
 func (h *PageHandler) SettingsPage(w http.ResponseWriter, r *http.Request) {
 	data := h.getAuthData(r)
-	data.Title = "Настройки"
+	if !data.IsAuthenticated {
+		redirectToLogin(w, r)
+		return
+	}
+	data.Title = data.T("title.settings")
 	data.Page = "settings"
-	h.renderLayout(w, data)
+	h.renderLayout(w, http.StatusOK, data)
 }
 
 func (h *PageHandler) StatsPage(w http.ResponseWriter, r *http.Request) {
 	data := h.getAuthData(r)
-	if !data.IsAuthenticated || data.Role != "admin" {
-		http.Error(w, "Доступ запрещён", http.StatusForbidden)
+	if !data.IsAuthenticated {
+		redirectToLogin(w, r)
+		return
+	}
+	if data.Role != string(models.RoleAdmin) {
+		http.Error(w, data.T("error.forbidden"), http.StatusForbidden)
 		return
 	}
-	data.Title = "Статистика"
+	data.Title = data.T("title.stats")
 	data.Page = "stats"
-	h.renderLayout(w, data)
+	h.renderLayout(w, http.StatusOK, data)
 }
 
 // LoginPage и RegisterPage остаются без layout
 func (h *PageHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 	data := h.getAuthData(r)
-	data.Title = "Вход"
+	if data.IsAuthenticated {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	data.Title = data.T("title.signin")
 	data.Page = "login"
-	h.renderLayout(w, data)
+	h.renderLayout(w, http.StatusOK, data)
 }
 func (h *PageHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	data := h.getAuthData(r)
-	data.Title = "Регистрация"
+	data.Title = data.T("title.signup")
 	data.Page = "register"
-	h.renderLayout(w, data)
+	h.renderLayout(w, http.StatusOK, data)
 }
 
-func (h *PageHandler) getAuthData(r *http.Request) TemplateData {
-	td := TemplateData{}
-	cookie, err := r.Cookie("jwt")
-	if err != nil {
-		return td
+// ReportPage показывает форму жалобы на ссылку и результат её отправки.
+func (h *PageHandler) ReportPage(w http.ResponseWriter, r *http.Request) {
+	data := h.getAuthData(r)
+	data.Title = data.T("title.report")
+	data.Page = "report"
+	data.Hash = r.PathValue("hash")
+	switch {
+	case r.URL.Query().Get("sent") == "1":
+		data.Notice = data.T("report.sent")
+	case r.URL.Query().Get("error") == "invalid":
+		data.Error = data.T("report.reason_required")
+	case r.URL.Query().Get("error") != "":
+		data.Error = data.T("report.failed")
+	}
+	h.renderLayout(w, http.StatusOK, data)
+}
+
+// Logout завершает cookie-сессию и возвращает на главную страницу.
+// CSRF-токен формы проверяется промежуточным ПО middleware.CSRF.
+func (h *PageHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(h.config.Session.CookieName); err == nil {
+		if err := h.sessions.Destroy(r.Context(), cookie.Value); err != nil {
+			logger.Error("Ошибка при завершении сессии", zap.Error(err))
+		}
 	}
-	claims, err := h.jwtService.ParseToken(cookie.Value)
-	if err != nil {
+	middleware.ClearSessionCookie(w, h.config)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (h *PageHandler) getAuthData(r *http.Request) TemplateData {
+	td := TemplateData{Brand: h.config.Branding, Locale: i18n.FromContext(r.Context())}
+	data, ok := middleware.SessionFromContext(r.Context())
+	if !ok {
 		return td
 	}
 	td.IsAuthenticated = true
-	td.Role = string(claims.Role)
+	td.Role = string(data.User.Role)
+	td.UserName = data.User.Name
+	td.CSRFToken = data.Session.CSRFToken
 	return td
 }
+
+// redirectToLogin отправляет неавторизованного пользователя на страницу входа.
+func redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/signin?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+}
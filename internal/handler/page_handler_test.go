@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/event"
+	"shorty/pkg/view"
+	"shorty/web"
+)
+
+// fakeLinkService finds links by hash in a map.
+type fakeLinkService struct {
+	service.LinkServ
+	links map[string]*models.Link
+}
+
+func (s *fakeLinkService) Lookup(_ context.Context, hash string) (*models.Link, error) {
+	link, ok := s.links[hash]
+	if !ok {
+		return nil, common.ErrLinkNotFound
+	}
+	return link, nil
+}
+
+func (s *fakeLinkService) GetByHash(ctx context.Context, hash string) (*models.Link, error) {
+	return s.Lookup(ctx, hash)
+}
+
+// newTestPageHandler returns a page handler over the embedded templates and
+// the event bus that receives its clicks.
+func newTestPageHandler(t *testing.T, links ...*models.Link) (*PageHandler, <-chan event.Event) {
+	t.Helper()
+	views, err := view.New(web.Templates(), view.Options{
+		Layouts: []string{"layout.html", "header.html", "links_table.html"},
+		Funcs:   template.FuncMap{"asset": func(name string) string { return "/static/" + name }},
+	})
+	if err != nil {
+		t.Fatalf("view.New: %v", err)
+	}
+	byHash := make(map[string]*models.Link)
+	for _, link := range links {
+		byHash[link.Hash] = link
+	}
+	bus := event.NewEventBus()
+	h := NewPageHandler(PageHandlerDeps{
+		Config:      &config.Config{Tracking: config.TrackingConfig{ClickIDParam: "sclid", AttributionWindow: time.Hour}},
+		Views:       views,
+		LinkService: &fakeLinkService{links: byHash},
+		EventBus:    bus,
+	})
+	return h, bus.Subscribe()
+}
+
+var continueHref = regexp.MustCompile(`href="([^"]*)"[^>]*class="[^"]*preview-continue`)
+
+// previewAndContinue opens the preview page of path and follows its
+// "continue" link, returning the redirect response.
+func previewAndContinue(t *testing.T, h *PageHandler, path, language string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+	h.HomePage(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("preview of %s: status %d, want 200", path, w.Code)
+	}
+	m := continueHref.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatalf("preview of %s has no continue link", path)
+	}
+	href := html.UnescapeString(m[1])
+	if !strings.HasPrefix(href, "/") {
+		t.Fatalf("continue link %q does not go through the short link", href)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, href, nil)
+	req.Header.Set("Accept-Language", language)
+	w = httptest.NewRecorder()
+	h.HomePage(w, req)
+	if w.Code != http.StatusFound {
+		t.Fatalf("continue %s: status %d, want 302", href, w.Code)
+	}
+	return w
+}
+
+// nextClick waits for the click published by a redirect.
+func nextClick(t *testing.T, events <-chan event.Event) payload.ClickEvent {
+	t.Helper()
+	select {
+	case e := <-events:
+		click, ok := e.Data.(payload.ClickEvent)
+		if !ok {
+			t.Fatalf("event data %T, want payload.ClickEvent", e.Data)
+		}
+		return click
+	case <-time.After(time.Second):
+		t.Fatal("no click was recorded")
+	}
+	return payload.ClickEvent{}
+}
+
+func TestPreviewContinueGoesThroughRedirector(t *testing.T) {
+	link := &models.Link{
+		Url:           "https://example.com/landing",
+		Hash:          "abc",
+		AlwaysPreview: true,
+		ForwardQuery:  models.QueryForwardMerge,
+		UTM:           models.UTM{Campaign: "spring"},
+		Rules:         models.TargetRules{{Languages: []string{"de"}, Url: "https://de.example.com/"}},
+		Variants:      models.Variants{{Name: "b", Url: "https://b.example.com/", Weight: 1}},
+	}
+	link.ID = 7
+	h, events := newTestPageHandler(t, link)
+
+	// A visitor caught by the targeting rule.
+	w := previewAndContinue(t, h, "/abc?ref=mail", "de")
+	location := w.Header().Get("Location")
+	for _, want := range []string{"https://de.example.com/?", "ref=mail", "utm_campaign=spring", "sclid="} {
+		if !strings.Contains(location, want) {
+			t.Errorf("Location %q does not contain %q", location, want)
+		}
+	}
+	if strings.Contains(location, continueParam+"=") {
+		t.Errorf("Location %q forwards the continue parameter", location)
+	}
+	click := nextClick(t, events)
+	if click.LinkID != 7 || click.ClickID == "" || click.MatchedRule == nil || *click.MatchedRule != 0 {
+		t.Errorf("click = %+v, want link 7, a click ID and rule 0", click)
+	}
+
+	// Any other visitor gets the A/B variant.
+	w = previewAndContinue(t, h, "/abc+", "en")
+	if location := w.Header().Get("Location"); !strings.HasPrefix(location, "https://b.example.com/") {
+		t.Errorf("Location = %q, want the variant URL", location)
+	}
+	if click := nextClick(t, events); click.Variant != "b" || click.ClickID == "" {
+		t.Errorf("click = %+v, want variant b with a click ID", click)
+	}
+}
+
+func TestPreviewKeepsTrailingPath(t *testing.T) {
+	link := &models.Link{Url: "https://example.com/docs", Hash: "abc", ForwardPath: true}
+	h, events := newTestPageHandler(t, link)
+
+	w := previewAndContinue(t, h, "/abc/guide/intro?preview=1", "en")
+	if location := w.Header().Get("Location"); !strings.HasPrefix(location, "https://example.com/docs/guide/intro") {
+		t.Errorf("Location = %q, want the forwarded path", location)
+	}
+	if click := nextClick(t, events); click.ForwardedPath != "guide/intro" {
+		t.Errorf("forwarded path = %q, want guide/intro", click.ForwardedPath)
+	}
+}
+
+func TestPreviewPage(t *testing.T) {
+	plain := &models.Link{Url: "https://example.com/", Hash: "abc", Title: "Spring <sale>", Description: "Up to 50% off"}
+	always := &models.Link{Url: "https://example.com/warn", Hash: "warn", AlwaysPreview: true}
+	h, _ := newTestPageHandler(t, plain, always)
+
+	tests := []struct {
+		name        string
+		path        string
+		wantPreview bool
+		wantNotice  bool
+	}{
+		{name: "plus suffix", path: "/abc+", wantPreview: true},
+		{name: "preview parameter", path: "/abc?preview=1", wantPreview: true},
+		{name: "plain visit", path: "/abc"},
+		{name: "always preview", path: "/warn", wantPreview: true, wantNotice: true},
+		{name: "always preview confirmed", path: "/warn?go=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+			h.HomePage(w, req)
+
+			if !tt.wantPreview {
+				if w.Code != http.StatusFound {
+					t.Errorf("status = %d, want a redirect", w.Code)
+				}
+				return
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if got := w.Header().Get("X-Robots-Tag"); got != "noindex" {
+				t.Errorf("X-Robots-Tag = %q, want noindex", got)
+			}
+			body := w.Body.String()
+			if !continueHref.MatchString(body) {
+				t.Error("no continue link")
+			}
+			if strings.Contains(body, "preview-warning") != tt.wantNotice {
+				t.Errorf("warning shown = %v, want %v", !tt.wantNotice, tt.wantNotice)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/abc+", nil)
+	w := httptest.NewRecorder()
+	h.HomePage(w, req)
+	body := w.Body.String()
+	for _, want := range []string{"Spring &lt;sale&gt;", "Up to 50% off", "https://example.com/", `href="/report/abc"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("preview page does not contain %q", want)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.HomePage(w, httptest.NewRequest(http.MethodGet, "/missing+", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("preview of an unknown link: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/pkg/event"
+	"shorty/pkg/i18n"
+	"shorty/pkg/logger"
+	"shorty/pkg/middleware"
+	"shorty/pkg/useragent"
+)
+
+// variantCookiePrefix - префикс cookie с вариантом A/B-теста, за ним следует хеш ссылки.
+const variantCookiePrefix = "shorty_ab_"
+
+// variantCookieMaxAge - сколько посетитель остаётся в своём варианте.
+const variantCookieMaxAge = 90 * 24 * time.Hour
+
+// clickCookie - cookie с ID последнего перехода для атрибуции конверсий.
+const clickCookie = "shorty_click"
+
+// previewParam показывает страницу предпросмотра вместо перехода (?preview=1),
+// continueParam подтверждает переход со страницы предпросмотра (?go=1).
+// Язык интерфейса (?lang=) выбирает middleware.Locale.
+const (
+	previewParam  = "preview"
+	continueParam = "go"
+)
+
+// reservedParams - параметры запроса, которые обрабатывает сам сервис
+// и которые не пробрасываются на адрес назначения.
+var reservedParams = []string{previewParam, continueParam, middleware.LocaleParam}
+
+// sourceParam - параметр, которым QR-код ссылки отмечает сканирования.
+// Другие его значения принадлежат адресу назначения и пробрасываются.
+const sourceParam = "source"
+
+// Redirector перенаправляет посетителя короткой ссылки на адрес назначения
+// и публикует событие о переходе для статистики. Им пользуются все маршруты
+// перехода, чтобы таргетинг, проброс параметров и учёт кликов работали одинаково.
+type Redirector struct {
+	EventBus      *event.EventBus
+	CountryHeader string        // заголовок с кодом страны посетителя, см. config.RedirectConfig
+	SecureCookies bool          // выставлять cookie с Secure и SameSite=None
+	ClickIDParam  string        // параметр адреса назначения с ID перехода, см. config.TrackingConfig
+	ClickMaxAge   time.Duration // сколько хранится cookie с ID перехода
+}
+
+// NewRedirector создаёт Redirector по настройкам приложения.
+func NewRedirector(cfg *config.Config, eventBus *event.EventBus) *Redirector {
+	return &Redirector{
+		EventBus:      eventBus,
+		CountryHeader: cfg.Redirect.CountryHeader,
+		SecureCookies: cfg.Session.CookieSecure,
+		ClickIDParam:  cfg.Tracking.ClickIDParam,
+		ClickMaxAge:   cfg.Tracking.AttributionWindow,
+	}
+}
+
+// Redirect отправляет посетителя по ссылке: по первому подходящему правилу
+// таргетинга, иначе на закреплённый за ним вариант A/B-теста или на адрес
+// ссылки. extraPath - экранированный хвост пути после хеша; он и параметры
+// запроса пробрасываются по настройкам ссылки. Переходу выдаётся ID, который
+// передаётся адресу назначения и сохраняется в cookie для атрибуции конверсий.
+func (rd *Redirector) Redirect(w http.ResponseWriter, r *http.Request, link *models.Link, extraPath string, status int) {
+	query := r.URL.Query()
+	for _, name := range reservedParams {
+		query.Del(name)
+	}
+	var source string
+	if query.Get(sourceParam) == models.ClickSourceQR {
+		source = models.ClickSourceQR
+		query.Del(sourceParam)
+	}
+	var matched *int
+	var variant string
+	dest := link
+	if i := link.Rules.Match(rd.visitor(r)); i >= 0 {
+		matched = &i
+		dest = link.WithTarget(link.Rules[i].Url)
+	} else if v := rd.variant(w, r, link); v != nil {
+		variant = v.Name
+		dest = link.WithTarget(v.Url)
+	}
+	target := dest.RedirectURL(extraPath, query)
+	clickID := newClickID()
+	if clickID != "" {
+		target = rd.withClickID(target, clickID)
+		rd.setCookie(w, clickCookie, clickID, rd.ClickMaxAge)
+	}
+
+	click := payload.ClickEvent{
+		LinkID:         link.ID,
+		ClickID:        clickID,
+		At:             time.Now(),
+		Referrer:       r.Referer(),
+		UserAgent:      r.UserAgent(),
+		ForwardedQuery: link.ForwardedQuery(query),
+		ForwardedPath:  link.ForwardedPath(extraPath),
+		MatchedRule:    matched,
+		Variant:        variant,
+		Source:         source,
+	}
+	if err := rd.EventBus.Publish(event.Event{Type: event.EventLinkVisited, Data: click}); err != nil {
+		logger.Error("Ошибка записи события о переходе по ссылке", zap.Uint("linkID", link.ID), zap.Error(err))
+	}
+
+	logger.Info("Переход по ссылке", zap.String("url", target), zap.String("hash", link.Hash))
+	http.Redirect(w, r, target, status)
+}
+
+// variant возвращает вариант A/B-теста для посетителя, nil если у ссылки нет вариантов.
+// Вариант закрепляется cookie; без неё он выбирается по отпечатку посетителя
+// (IP и User-Agent), так что и без cookie посетитель обычно попадает в тот же вариант.
+func (rd *Redirector) variant(w http.ResponseWriter, r *http.Request, link *models.Link) *models.Variant {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+	name := variantCookiePrefix + link.Hash
+	if cookie, err := r.Cookie(name); err == nil {
+		if v, ok := link.Variants.Find(cookie.Value); ok {
+			return v
+		}
+	}
+	v := link.Variants.Pick(link.Hash + "|" + common.ClientIPFromContext(r.Context()) + "|" + r.UserAgent())
+	if v == nil {
+		return nil
+	}
+	rd.setCookie(w, name, v.Name, variantCookieMaxAge)
+	return v
+}
+
+// setCookie выставляет cookie посетителя для учёта конверсий.
+func (rd *Redirector) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	// Конверсия приходит со стороннего сайта, поэтому cookie должна уходить и туда.
+	if rd.SecureCookies {
+		cookie.Secure, cookie.SameSite = true, http.SameSiteNoneMode
+	}
+	http.SetCookie(w, cookie)
+}
+
+// withClickID добавляет ID перехода к адресу назначения, не трогая
+// остальные параметры запроса.
+func (rd *Redirector) withClickID(target, clickID string) string {
+	if rd.ClickIDParam == "" {
+		return target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	if u.RawQuery != "" {
+		u.RawQuery += "&"
+	}
+	u.RawQuery += url.QueryEscape(rd.ClickIDParam) + "=" + clickID
+	return u.String()
+}
+
+// newClickID возвращает случайный ID перехода или пустую строку, если
+// источник случайности недоступен.
+func newClickID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Error("Ошибка генерации ID перехода", zap.Error(err))
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// visitor описывает посетителя для правил таргетинга.
+func (rd *Redirector) visitor(r *http.Request) models.Visitor {
+	ua := useragent.Parse(r.UserAgent())
+	v := models.Visitor{
+		OS:       ua.OS,
+		Device:   ua.Device,
+		Language: i18n.Preferred(r.Header.Get("Accept-Language")),
+		At:       time.Now(),
+	}
+	if rd.CountryHeader != "" {
+		v.Country = strings.ToUpper(strings.TrimSpace(r.Header.Get(rd.CountryHeader)))
+	}
+	return v
+}
+
+// splitLinkPath делит путь перехода "/{hash}/хвост" на хеш и экранированный хвост.
+func splitLinkPath(r *http.Request) (hash, extraPath string) {
+	hashPart, extraPath, _ := strings.Cut(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+	hash, err := url.PathUnescape(hashPart)
+	if err != nil {
+		hash = hashPart
+	}
+	return hash, extraPath
+}
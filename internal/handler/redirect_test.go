@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"shorty/internal/models"
+	"shorty/pkg/event"
+)
+
+func TestRedirectDropsReservedParams(t *testing.T) {
+	rd := &Redirector{EventBus: event.NewEventBus()}
+	link := &models.Link{Url: "https://example.com/?a=1", Hash: "abc", ForwardQuery: models.QueryForwardMerge}
+
+	r := httptest.NewRequest(http.MethodGet, "/abc?lang=en&preview=1&go=1&ref=mail", nil)
+	w := httptest.NewRecorder()
+	rd.Redirect(w, r, link, "", http.StatusFound)
+
+	location, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	for _, name := range reservedParams {
+		if query.Has(name) {
+			t.Errorf("Location %q forwards %q", location, name)
+		}
+	}
+	if query.Get("a") != "1" || query.Get("ref") != "mail" {
+		t.Errorf("Location %q, want a=1 and ref=mail", location)
+	}
+}
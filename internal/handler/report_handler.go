@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// ReportHandlerDeps - зависимости для обработчика жалоб.
+type ReportHandlerDeps struct {
+	Config        *config.Config
+	ReportService service.ReportServ
+}
+
+// ReportHandler - обработчик жалоб на ссылки.
+type ReportHandler struct {
+	Config        *config.Config
+	ReportService service.ReportServ
+}
+
+// NewReportHandler регистрирует маршруты для жалоб на ссылки.
+func NewReportHandler(router Router, deps ReportHandlerDeps) {
+	handler := &ReportHandler{
+		Config:        deps.Config,
+		ReportService: deps.ReportService,
+	}
+
+	router.HandleFunc("POST /report/{hash}", handler.Report())
+}
+
+// Report принимает жалобу на ссылку. Принимает JSON или форму со страницы
+// жалобы; для формы отвечает редиректом обратно на страницу с результатом.
+func (h *ReportHandler) Report() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		hash := r.PathValue("hash")
+		fromForm := isFormRequest(r)
+
+		var body *payload.ReportLinkRequest
+		var err error
+		if fromForm {
+			body = &payload.ReportLinkRequest{
+				Reason:  r.PostFormValue("reason"),
+				Comment: r.PostFormValue("comment"),
+			}
+			err = req.IsValidate(body)
+		} else {
+			body, err = req.HandleBody[payload.ReportLinkRequest](&w, r)
+			if err != nil {
+				logger.Error("Ошибка парсинга тела запроса жалобы", zap.Error(err))
+				return
+			}
+		}
+		if err != nil {
+			logger.Warn("Некорректная жалоба", zap.String("hash", hash), zap.Error(err))
+			if fromForm {
+				http.Redirect(w, r, "/report/"+hash+"?error=invalid", http.StatusSeeOther)
+				return
+			}
+			res.ERROR(w, r, common.ErrInvalidRequest.Wrap(err))
+			return
+		}
+
+		ip := req.ClientIP(r, h.Config.TrustProxy)
+		_, err = h.ReportService.Report(ctx, hash, models.ReportReason(body.Reason), body.Comment, ip)
+		if err != nil {
+			logger.Error("Ошибка при сохранении жалобы", zap.String("hash", hash), zap.Error(err))
+			if fromForm {
+				http.Redirect(w, r, "/report/"+hash+"?error=failed", http.StatusSeeOther)
+				return
+			}
+			res.ERROR(w, r, err)
+			return
+		}
+
+		if fromForm {
+			http.Redirect(w, r, "/report/"+hash+"?sent=1", http.StatusSeeOther)
+			return
+		}
+		res.MESSAGE(w, r, "msg.report_accepted", http.StatusAccepted)
+	}
+}
+
+// isFormRequest сообщает, отправлен ли запрос HTML-формой.
+func isFormRequest(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, "application/x-www-form-urlencoded") || strings.HasPrefix(ct, "multipart/form-data")
+}
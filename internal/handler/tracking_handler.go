@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/service"
+	"shorty/pkg/logger"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// TrackingHandlerDeps - зависимости для обработчика событий с сайтов назначения.
+type TrackingHandlerDeps struct {
+	Config      *config.Config
+	LinkService service.LinkServ
+	StatService service.StatServ
+}
+
+// TrackingHandler - обработчик событий, которые присылают сайты назначения.
+type TrackingHandler struct {
+	Config      *config.Config
+	LinkService service.LinkServ
+	StatService service.StatServ
+}
+
+// NewTrackingHandler регистрирует маршруты для событий с сайтов назначения.
+func NewTrackingHandler(router Router, deps TrackingHandlerDeps) {
+	handler := &TrackingHandler{
+		Config:      deps.Config,
+		LinkService: deps.LinkService,
+		StatService: deps.StatService,
+	}
+
+	router.HandleFunc("POST /t/conversion", handler.Conversion())
+	router.HandleFunc("GET /t/pixel.gif", handler.Pixel())
+}
+
+// Conversion принимает конверсию (postback) от сервера сайта назначения,
+// который получил ID перехода в параметре адреса. Конверсия засчитывается
+// только переходу по ID из тела запроса или из cookie: без известного
+// перехода накрутить конверсии ссылки или варианта было бы слишком просто.
+func (h *TrackingHandler) Conversion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := req.HandleBody[payload.ConversionRequest](&w, r)
+		if err != nil {
+			logger.Error("Ошибка парсинга тела запроса конверсии", zap.Error(err))
+			return
+		}
+		if err := h.record(r, body); err != nil {
+			logger.Warn("Конверсия не записана", zap.String("clickID", body.ClickID), zap.String("hash", body.Hash), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Pixel принимает конверсию с пикселя на странице сайта назначения
+// (<img src="/t/pixel.gif?click_id=...">). Параметры те же, что у Conversion.
+// Ответом всегда служит прозрачная картинка, ошибки только пишутся в лог.
+func (h *TrackingHandler) Pixel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		body := &payload.ConversionRequest{
+			ClickID: query.Get("click_id"),
+			Hash:    query.Get("hash"),
+		}
+		var err error
+		if value := query.Get("value"); value != "" {
+			body.Value, err = strconv.ParseFloat(value, 64)
+		}
+		if err == nil {
+			err = req.IsValidate(body)
+		}
+		if err == nil {
+			err = h.record(r, body)
+		}
+		if err != nil {
+			logger.Warn("Конверсия с пикселя не записана", zap.String("clickID", body.ClickID), zap.String("hash", body.Hash), zap.Error(err))
+		}
+
+		w.Header().Set("Content-Type", "image/gif")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write(pixelGIF)
+	}
+}
+
+// record записывает конверсию. ID перехода, не указанный в запросе, берётся
+// из cookie, выставленной при переходе. Хеш, если указан, должен совпадать
+// со ссылкой перехода.
+func (h *TrackingHandler) record(r *http.Request, body *payload.ConversionRequest) error {
+	ctx := r.Context()
+	conversion := &models.Conversion{ClickID: body.ClickID, Value: body.Value}
+	if conversion.ClickID == "" {
+		if cookie, err := r.Cookie(clickCookie); err == nil {
+			conversion.ClickID = cookie.Value
+		}
+	}
+	if conversion.ClickID == "" {
+		return common.ErrInvalidRequest.WithField("click_id", "required", "")
+	}
+
+	if body.Hash != "" {
+		link, err := h.LinkService.GetByHash(ctx, body.Hash)
+		if err != nil {
+			return err
+		}
+		conversion.LinkID = link.ID
+	}
+	return h.StatService.RecordConversion(ctx, conversion)
+}
+
+// pixelGIF - прозрачная картинка 1x1 для пикселя конверсий.
+var pixelGIF = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
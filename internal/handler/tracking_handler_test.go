@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/internal/repository"
+	"shorty/internal/service"
+)
+
+// fakeStatRepo keeps clicks and conversions in memory.
+type fakeStatRepo struct {
+	repository.StatRepo
+	clicks      map[string]*models.Click
+	conversions []*models.Conversion
+}
+
+func (r *fakeStatRepo) FindClick(_ context.Context, clickID string) (*models.Click, error) {
+	click, ok := r.clicks[clickID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return click, nil
+}
+
+func (r *fakeStatRepo) ClickConverted(_ context.Context, clickID string) (bool, error) {
+	for _, c := range r.conversions {
+		if c.ClickID == clickID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeStatRepo) SaveConversion(_ context.Context, conversion *models.Conversion) error {
+	r.conversions = append(r.conversions, conversion)
+	return nil
+}
+
+// newTestTrackingHandler returns a tracking handler over the real StatService
+// with two links and three clicks: a recent click on variant "b" of link 1,
+// a click on link 1 outside the attribution window and a click on link 2.
+func newTestTrackingHandler() (*TrackingHandler, *fakeStatRepo) {
+	first := &models.Link{Hash: "abc"}
+	first.ID = 1
+	second := &models.Link{Hash: "def"}
+	second.ID = 2
+	now := time.Now()
+	repo := &fakeStatRepo{clicks: map[string]*models.Click{
+		"recent": {LinkID: 1, ClickID: "recent", Variant: "b", CreatedAt: now.Add(-time.Hour)},
+		"old":    {LinkID: 1, ClickID: "old", CreatedAt: now.Add(-48 * time.Hour)},
+		"other":  {LinkID: 2, ClickID: "other", CreatedAt: now},
+	}}
+	return &TrackingHandler{
+		LinkService: &fakeLinkService{links: map[string]*models.Link{"abc": first, "def": second}},
+		StatService: &service.StatService{Repo: repo, AttributionWindow: 24 * time.Hour},
+	}, repo
+}
+
+func TestConversion(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		cookie     string
+		wantStatus int
+		wantSaved  bool
+	}{
+		{name: "inside the window", body: `{"click_id":"recent","value":9.5}`, wantStatus: http.StatusNoContent, wantSaved: true},
+		{name: "matching hash", body: `{"click_id":"recent","hash":"abc"}`, wantStatus: http.StatusNoContent, wantSaved: true},
+		{name: "click id from the cookie", body: `{}`, cookie: "recent", wantStatus: http.StatusNoContent, wantSaved: true},
+		{name: "outside the window", body: `{"click_id":"old"}`, wantStatus: http.StatusNotFound},
+		{name: "unknown click", body: `{"click_id":"missing"}`, wantStatus: http.StatusNotFound},
+		{name: "click of another link", body: `{"click_id":"other","hash":"abc"}`, wantStatus: http.StatusNotFound},
+		{name: "unknown link", body: `{"click_id":"recent","hash":"zzz"}`, wantStatus: http.StatusNotFound},
+		{name: "no click id", body: `{}`, wantStatus: http.StatusUnprocessableEntity},
+		{name: "negative value", body: `{"click_id":"recent","value":-1}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, repo := newTestTrackingHandler()
+			r := httptest.NewRequest(http.MethodPost, "/t/conversion", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: clickCookie, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			h.Conversion()(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if saved := len(repo.conversions) == 1; saved != tt.wantSaved {
+				t.Fatalf("conversions = %d, want saved %v", len(repo.conversions), tt.wantSaved)
+			}
+			if tt.wantSaved {
+				if c := repo.conversions[0]; c.LinkID != 1 || c.Variant != "b" || c.ClickID != "recent" {
+					t.Errorf("conversion = %+v, want link 1, variant b, click recent", c)
+				}
+			}
+		})
+	}
+}
+
+func TestConversionCountedOnce(t *testing.T) {
+	h, repo := newTestTrackingHandler()
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/t/conversion", strings.NewReader(`{"click_id":"recent"}`))
+		w := httptest.NewRecorder()
+		h.Conversion()(w, r)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", w.Code)
+		}
+	}
+	if len(repo.conversions) != 1 {
+		t.Errorf("conversions = %d, want 1", len(repo.conversions))
+	}
+}
+
+func TestPixel(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantSaved bool
+	}{
+		{name: "known click", query: "?click_id=recent&hash=abc&value=3", wantSaved: true},
+		{name: "outside the window", query: "?click_id=old"},
+		{name: "unknown click", query: "?click_id=missing"},
+		{name: "bad value", query: "?click_id=recent&value=lots"},
+		{name: "no click id", query: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, repo := newTestTrackingHandler()
+			w := httptest.NewRecorder()
+			h.Pixel()(w, httptest.NewRequest(http.MethodGet, "/t/pixel.gif"+tt.query, nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "image/gif" {
+				t.Errorf("Content-Type = %q, want image/gif", ct)
+			}
+			if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", cc)
+			}
+			if !bytes.Equal(w.Body.Bytes(), pixelGIF) {
+				t.Error("body is not the pixel image")
+			}
+			if saved := len(repo.conversions) == 1; saved != tt.wantSaved {
+				t.Errorf("conversions = %d, want saved %v", len(repo.conversions), tt.wantSaved)
+			}
+			if tt.wantSaved && repo.conversions[0].Value != 3 {
+				t.Errorf("value = %v, want 3", repo.conversions[0].Value)
+			}
+		})
+	}
+}
@@ -2,11 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
 
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 
 	"shorty/internal/common"
 	"shorty/internal/config"
@@ -14,6 +14,7 @@ import (
 	"shorty/internal/payload"
 	"shorty/internal/service"
 	"shorty/pkg/event"
+	"shorty/pkg/jwt"
 	"shorty/pkg/logger"
 	"shorty/pkg/middleware"
 	"shorty/pkg/parse"
@@ -26,7 +27,10 @@ type UserHandlerDeps struct {
 	Config      *config.Config
 	UserService service.UserServ
 	LinkService service.LinkServ
+	StatService service.StatServ
+	QRService   service.QRServ
 	EventBus    *event.EventBus
+	ErrorPages  ErrorPages
 }
 
 // UserHandler - обработчик для управления пользователями.
@@ -34,16 +38,24 @@ type UserHandler struct {
 	Config      *config.Config
 	UserService service.UserServ
 	LinkService service.LinkServ
+	StatService service.StatServ
+	QRService   service.QRServ
 	EventBus    *event.EventBus
+	ErrorPages  ErrorPages
+	redirector  *Redirector
 }
 
 // NewUserHandler регистрирует маршруты, связанные с пользователями, и привязывает их к методам UserHandler.
-func NewUserHandler(router *http.ServeMux, deps UserHandlerDeps) {
+func NewUserHandler(router Router, deps UserHandlerDeps) {
 	handler := &UserHandler{
 		Config:      deps.Config,
 		UserService: deps.UserService,
 		LinkService: deps.LinkService,
+		StatService: deps.StatService,
+		QRService:   deps.QRService,
 		EventBus:    deps.EventBus,
+		ErrorPages:  deps.ErrorPages,
+		redirector:  NewRedirector(deps.Config, deps.EventBus),
 	}
 
 	// Управление пользователями.
@@ -51,10 +63,12 @@ func NewUserHandler(router *http.ServeMux, deps UserHandlerDeps) {
 	router.Handle("DELETE /users/{id}", middleware.IsAuth(handler.Delete(), deps.Config))
 
 	// Управление ссылками.
-	router.HandleFunc("POST /users/links", handler.CreateLink())
+	router.Handle("POST /users/links", middleware.OptionalAuth(handler.CreateLink(), deps.Config))
 	router.Handle("GET /users/links", middleware.IsAuth(handler.GetLinks(), deps.Config))
 	router.Handle("PATCH /users/links/{id}", middleware.IsAuth(handler.UpdateLink(), deps.Config))
 	router.Handle("DELETE /users/links/{id}", middleware.IsAuth(handler.DeleteLink(), deps.Config))
+	router.Handle("GET /users/links/{id}/variants", middleware.IsAuth(handler.VariantStats(), deps.Config))
+	router.Handle("GET /users/links/{id}/qr", middleware.IsAuth(handler.QRCode(), deps.Config))
 	router.Handle("GET /users/links/{hash}", middleware.IsAuth(handler.Redirect(), deps.Config))
 }
 
@@ -66,20 +80,19 @@ func (h *UserHandler) Update() http.HandlerFunc {
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
 			logger.Error("Некорректный ID пользователя", zap.String("id", idStr), zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		body, err := req.HandleBody[models.User](&w, r)
 		if err != nil {
 			logger.Error("Ошибка обработки тела запроса", zap.Error(err))
-			res.ERROR(w, common.ErrRequestBodyParse, http.StatusBadRequest)
 			return
 		}
 		body.ID = uint(id)
 		updatedUser, err := h.UserService.Update(ctx, body)
 		if err != nil {
 			logger.Error("Ошибка обновления пользователя", zap.Int("id", id), zap.Error(err))
-			res.ERROR(w, common.ErrUserUpdateFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("Пользователь успешно обновлён", zap.Int("id", id))
@@ -96,18 +109,18 @@ func (h *UserHandler) Delete() http.HandlerFunc {
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
 			logger.Error("Некорректный ID пользователя", zap.String("id", idStr), zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidID)
 			return
 		}
 		err = h.UserService.Delete(ctx, uint(id))
 		if err != nil {
 			logger.Error("Ошибка удаления пользователя", zap.Int("id", id), zap.Error(err))
-			res.ERROR(w, common.ErrUserDeleteFailed, http.StatusInternalServerError)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("Пользователь успешно удалён", zap.Int("id", id))
 
-		res.JSON(w, map[string]string{"message": "Пользователь удалён"}, http.StatusOK)
+		res.MESSAGE(w, r, "msg.user_deleted", http.StatusOK)
 	}
 }
 
@@ -118,14 +131,29 @@ func (h *UserHandler) CreateLink() http.HandlerFunc {
 		body, err := req.HandleBody[payload.CreateLinkRequest](&w, r)
 		if err != nil {
 			logger.Error("Ошибка парсинга тела запроса для создания ссылки", zap.Error(err))
-			res.ERROR(w, common.ErrRequestBodyParse, http.StatusBadRequest)
 			return
 		}
 		link := models.NewLink(body.URL)
+		link.Title = body.Title
+		link.Description = body.Description
+		link.AlwaysPreview = body.AlwaysPreview
+		link.ExpiresAt = body.ExpiresAt
+		link.CampaignID = body.CampaignID
+		link.UTM = body.UTM()
+		link.ForwardQuery = models.QueryForward(body.ForwardQuery)
+		link.ForwardPath = body.ForwardPath
+		link.Rules = payload.TargetRules(body.Rules)
+		link.Variants = payload.Variants(body.Variants)
+		link.FolderID = body.FolderID
+		link.Tags = payload.Tags(body.Tags)
+		link.Notes = body.Notes
+		if userID, ok := currentUserID(r); ok {
+			link.UserID = &userID
+		}
 		newLink, err := h.LinkService.Create(ctx, link)
 		if err != nil {
 			logger.Error("Ошибка создания сокращённого URL", zap.Error(err))
-			res.ERROR(w, common.ErrLinkCreateUR, http.StatusBadRequest)
+			res.ERROR(w, r, err)
 			return
 		}
 		logger.Info("Сокращённый URL успешно создан", zap.String("short_url", newLink.Url))
@@ -133,129 +161,334 @@ func (h *UserHandler) CreateLink() http.HandlerFunc {
 	}
 }
 
-// GetLinks метод для получения списка ссылок.
+// GetLinks метод для получения списка ссылок текущего пользователя с поиском
+// (q), фильтрами по тегам (tag, можно несколько), папке (folder, 0 - без папки)
+// и статусу (status) и сортировкой (sort). Count - число ссылок под фильтром.
 func (h *UserHandler) GetLinks() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
+		userID, ok := currentUserID(r)
+		if !ok {
+			res.ERROR(w, r, common.ErrUnauthorized)
+			return
+		}
 		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
 		if err != nil {
 			logger.Error("Неверный параметр 'limit'", zap.String("limit", r.URL.Query().Get("limit")), zap.Error(err))
-			res.ERROR(w, common.ErrInvalidLimit, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidLimit)
 			return
 		}
 		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
 		if err != nil {
 			logger.Error("Неверный параметр 'offset'", zap.String("offset", r.URL.Query().Get("offset")), zap.Error(err))
-			res.ERROR(w, common.ErrInvalidOffset, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrInvalidOffset)
+			return
+		}
+		filter, err := linkListFilter(r, userID)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		count, err := h.LinkService.CountSearch(ctx, *filter)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		links, err := h.LinkService.Search(ctx, *filter, limit, offset)
+		if err != nil {
+			res.ERROR(w, r, err)
 			return
 		}
-		count, _ := h.LinkService.Count(ctx)
-		links, _ := h.LinkService.GetAll(ctx, limit, offset)
-		logger.Info("Получение всех сокращённых ссылок", zap.Int("limit", limit), zap.Int("offset", offset), zap.Int64("count", count))
+		logger.Info("Получение сокращённых ссылок пользователя", zap.Uint("user_id", userID), zap.Int("limit", limit), zap.Int("offset", offset), zap.Int64("count", count))
 		res.JSON(w, payload.GetAllLinksResponse{Count: count, Links: links}, http.StatusOK)
 	}
 }
 
-// UpdateLink метод для обновления ссылки.
+// linkListSorts и linkListStatuses - допустимые значения параметров sort и status.
+var (
+	linkListSorts = []string{payload.LinkSortNewest, payload.LinkSortOldest, payload.LinkSortClicks,
+		payload.LinkSortURL, payload.LinkSortRelevance}
+	linkListStatuses = []string{payload.LinkStatusActive, payload.LinkStatusBlocked, payload.LinkStatusExpired}
+)
+
+// linkListFilter читает фильтр списка ссылок владельца из запроса. С запросом q
+// ссылки по умолчанию сортируются по релевантности, без него - сначала новые.
+func linkListFilter(r *http.Request, ownerID uint) (*payload.LinkListFilter, error) {
+	query := r.URL.Query()
+	filter := &payload.LinkListFilter{
+		OwnerID: &ownerID,
+		Query:   strings.TrimSpace(query.Get("q")),
+		Sort:    query.Get("sort"),
+		Status:  query.Get("status"),
+	}
+	for _, tag := range query["tag"] {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	if v := query.Get("folder"); v != "" {
+		folderID, err := strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			return nil, common.ErrInvalidParam.WithField("folder", "invalid_type", "integer")
+		}
+		id := uint(folderID)
+		filter.FolderID = &id
+	}
+	switch {
+	case filter.Sort == "" && filter.Query != "":
+		filter.Sort = payload.LinkSortRelevance
+	case filter.Sort == "":
+		filter.Sort = payload.LinkSortNewest
+	case !slices.Contains(linkListSorts, filter.Sort):
+		return nil, common.ErrInvalidParam.WithField("sort", "oneof", strings.Join(linkListSorts, " "))
+	}
+	if filter.Status != "" && !slices.Contains(linkListStatuses, filter.Status) {
+		return nil, common.ErrInvalidParam.WithField("status", "oneof", strings.Join(linkListStatuses, " "))
+	}
+	return filter, nil
+}
+
+// UpdateLink метод для обновления ссылки текущего пользователя.
+// Чужая ссылка не отличается от несуществующей.
 func (h *UserHandler) UpdateLink() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		id, err := parse.ParseID(r)
+		link, err := h.ownLink(r)
 		if err != nil {
-			logger.Error("Неверный ID для обновления ссылки", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			logger.Error("Ошибка поиска ссылки для обновления", zap.String("id", r.PathValue("id")), zap.Error(err))
+			res.ERROR(w, r, err)
 			return
 		}
+		id := link.ID
 		body, err := req.HandleBody[payload.UpdateLinkRequest](&w, r)
 		if err != nil {
 			logger.Error("Ошибка парсинга тела запроса для обновления ссылки", zap.Error(err))
-			res.ERROR(w, common.ErrRequestBodyParse, http.StatusInternalServerError)
 			return
 		}
-		link, err := h.LinkService.Update(ctx, &models.Link{
-			Model: gorm.Model{ID: uint(id)},
-			Url:   body.URL,
-			Hash:  body.Hash,
-		})
+		link.Url = body.URL
+		if body.Hash != "" {
+			link.Hash = body.Hash
+		}
+		if body.Title != nil {
+			link.Title = *body.Title
+		}
+		if body.Description != nil {
+			link.Description = *body.Description
+		}
+		if body.AlwaysPreview != nil {
+			link.AlwaysPreview = *body.AlwaysPreview
+		}
+		if body.ExpiresAt != nil {
+			link.ExpiresAt = body.ExpiresAt
+		}
+		if body.CampaignID != nil {
+			link.CampaignID = body.CampaignID
+		}
+		body.ApplyUTM(&link.UTM)
+		if body.ForwardQuery != nil {
+			link.ForwardQuery = models.QueryForward(*body.ForwardQuery)
+		}
+		if body.ForwardPath != nil {
+			link.ForwardPath = *body.ForwardPath
+		}
+		if body.Rules != nil {
+			link.Rules = payload.TargetRules(*body.Rules)
+		}
+		if body.Variants != nil {
+			link.Variants = payload.Variants(*body.Variants)
+		}
+		if body.FolderID != nil {
+			link.FolderID = body.FolderID
+			if *body.FolderID == 0 {
+				link.FolderID = nil
+			}
+		}
+		link.Tags = payload.Tags(body.Tags)
+		if body.Notes != nil {
+			link.Notes = *body.Notes
+		}
+		link, err = h.LinkService.Update(ctx, link)
 		if err != nil {
-			logger.Error("Ошибка обновления ссылки", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkUpdateLinkFailed, http.StatusInternalServerError)
+			logger.Error("Ошибка обновления ссылки", zap.Uint("id", id), zap.Error(err))
+			res.ERROR(w, r, err)
 			return
 		}
-		logger.Info("Ссылка успешно обновлена", zap.Uint("id", uint(id)), zap.String("url", body.URL))
+		logger.Info("Ссылка успешно обновлена", zap.Uint("id", id), zap.String("url", body.URL))
 		res.JSON(w, link, http.StatusOK)
 	}
 }
 
-// DeleteLink метод для удаления ссылки по идентификатору.
+// DeleteLink метод для удаления ссылки текущего пользователя по идентификатору.
+// Чужая ссылка не отличается от несуществующей.
 func (h *UserHandler) DeleteLink() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		id, err := parse.ParseID(r)
+		link, err := h.ownLink(r)
+		if err != nil {
+			logger.Error("Ошибка поиска ссылки для удаления", zap.String("id", r.PathValue("id")), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		id := link.ID
+		err = h.LinkService.Delete(ctx, id)
+		if err != nil {
+			logger.Error("Ошибка удаления ссылки", zap.Uint("id", id), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		logger.Info("Ссылка успешно удалена", zap.Uint("id", id))
+		res.MESSAGE(w, r, "msg.link_deleted", http.StatusOK)
+	}
+}
+
+// VariantStats метод для получения статистики вариантов A/B-теста ссылки текущего пользователя.
+func (h *UserHandler) VariantStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		link, err := h.ownLink(r)
+		if err != nil {
+			res.ERROR(w, r, err)
+			return
+		}
+		stats, err := h.StatService.VariantStats(ctx, link)
+		if err != nil {
+			logger.Error("Ошибка получения статистики вариантов", zap.Uint("id", link.ID), zap.Error(err))
+			res.ERROR(w, r, err)
+			return
+		}
+		res.JSON(w, stats, http.StatusOK)
+	}
+}
+
+// QRCode метод для получения QR-кода короткой ссылки текущего пользователя в PNG или SVG.
+// Код ведёт на короткую ссылку с параметром source=qr, чтобы сканирования
+// отделялись от кликов в статистике.
+func (h *UserHandler) QRCode() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		link, err := h.ownLink(r)
 		if err != nil {
-			logger.Error("Неверный ID для удаления ссылки", zap.Error(err))
-			res.ERROR(w, common.ErrInvalidID, http.StatusBadRequest)
+			res.ERROR(w, r, err)
 			return
 		}
-		_, err = h.LinkService.FindByID(ctx, uint(id))
+		opts, err := qrRequest(r)
 		if err != nil {
-			logger.Error("Ссылка не найдена для удаления", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkNotFound, http.StatusNotFound)
+			res.ERROR(w, r, err)
 			return
 		}
-		err = h.LinkService.Delete(ctx, uint(id))
+		content := getScheme(r) + "://" + r.Host + "/" + link.Hash + "?" + sourceParam + "=" + models.ClickSourceQR
+		img, err := h.QRService.Render(content, *opts)
 		if err != nil {
-			logger.Error("Ошибка удаления ссылки", zap.Uint("id", uint(id)), zap.Error(err))
-			res.ERROR(w, common.ErrLinkDeleteFailed, http.StatusInternalServerError)
+			logger.Error("Ошибка создания QR-кода", zap.Uint("id", link.ID), zap.Error(err))
+			res.ERROR(w, r, err)
 			return
 		}
-		logger.Info("Ссылка успешно удалена", zap.Uint("id", uint(id)))
-		res.JSON(w, map[string]string{"message": "ссылка удалена"}, http.StatusOK)
+
+		w.Header().Set("ETag", img.ETag)
+		w.Header().Set("Cache-Control", "private, max-age=86400")
+		if r.Header.Get("If-None-Match") == img.ETag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", img.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(img.Data)))
+		w.Write(img.Data)
 	}
 }
 
-// Redirect - редирект на оригинальный URL.
+// qrRequest читает параметры QR-кода из запроса, подставляя значения по умолчанию.
+func qrRequest(r *http.Request) (*payload.QRRequest, error) {
+	query := r.URL.Query()
+	opts := &payload.QRRequest{
+		Format: query.Get("format"),
+		Size:   512,
+		Margin: 4,
+		ECC:    query.Get("ecc"),
+		FG:     strings.TrimPrefix(query.Get("fg"), "#"),
+		BG:     strings.TrimPrefix(query.Get("bg"), "#"),
+	}
+	if opts.Format == "" {
+		opts.Format = "png"
+	}
+	if opts.ECC == "" {
+		opts.ECC = "M"
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"size", &opts.Size}, {"margin", &opts.Margin}} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, common.ErrInvalidParam.WithField(p.name, "invalid_type", "integer")
+			}
+			*p.dst = n
+		}
+	}
+	if v := query.Get("logo"); v != "" {
+		logo, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, common.ErrInvalidParam.WithField("logo", "invalid_type", "boolean")
+		}
+		opts.Logo = logo
+	}
+	if err := req.IsValidate(opts); err != nil {
+		return nil, common.ErrInvalidRequest.Wrap(err)
+	}
+	return opts, nil
+}
+
+// ownLink находит ссылку по ID из пути и проверяет, что она принадлежит
+// текущему пользователю; чужая ссылка не отличается от несуществующей.
+func (h *UserHandler) ownLink(r *http.Request) (*models.Link, error) {
+	userID, ok := currentUserID(r)
+	if !ok {
+		return nil, common.ErrUnauthorized
+	}
+	id, err := parse.ParseID(r)
+	if err != nil {
+		logger.Error("Неверный ID ссылки", zap.Error(err))
+		return nil, common.ErrInvalidID
+	}
+	link, err := h.LinkService.FindByID(r.Context(), uint(id))
+	if err != nil {
+		return nil, err
+	}
+	if link.UserID == nil || *link.UserID != userID {
+		return nil, common.ErrLinkNotFound
+	}
+	return link, nil
+}
+
+// Redirect - редирект на оригинальный URL с пробросом параметров запроса по настройкам ссылки.
 func (h *UserHandler) Redirect() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		hash := r.PathValue("hash")
 		if hash == "" {
 			logger.Error("Не указан hash для редиректа")
-			res.ERROR(w, common.ErrLinkHashNotProvided, http.StatusBadRequest)
+			res.ERROR(w, r, common.ErrLinkHashNotProvided)
 			return
 		}
 
 		// Получаем ссылку по хешу
-		link, err := h.LinkService.GetByHash(ctx, hash)
+		link, err := h.LinkService.Lookup(ctx, hash)
 		if err != nil {
-			logger.Error("Ошибка получения ссылки по хешу", zap.String("hash", hash), zap.Error(err))
-			res.ERROR(w, common.ErrURLNotFound, http.StatusInternalServerError)
+			logger.Warn("Переход по недоступной ссылке", zap.String("hash", hash), zap.Error(err))
+			h.ErrorPages.Error(w, r, err)
 			return
 		}
 
-		// Логируем переход в статистику
-		go func(linkID uint) {
-			timer := time.NewTimer(time.Second)
-			defer timer.Stop()
-
-			done := make(chan struct{})
-
-			go func() {
-				if err := h.EventBus.Publish(event.Event{Type: event.EventLinkVisited, Data: linkID}); err != nil {
-					logger.Error("Ошибка записи события о переходе по ссылке", zap.Uint("linkID", linkID), zap.Error(err))
-					res.ERROR(w, common.ErrClickWriteFailed, http.StatusInternalServerError)
-				}
-				close(done)
-			}()
-
-			select {
-			case <-done:
-			case <-timer.C:
-				logger.Info("Таймаут при записи клика", zap.Uint("linkID", linkID))
-			}
-		}(link.ID)
+		h.redirector.Redirect(w, r, link, "", http.StatusTemporaryRedirect)
+	}
+}
 
-		logger.Info("Переход по ссылке", zap.String("url", link.Url), zap.String("hash", hash))
-		http.Redirect(w, r, link.Url, http.StatusTemporaryRedirect)
+// currentUserID возвращает ID пользователя из JWT-токена или cookie-сессии.
+func currentUserID(r *http.Request) (uint, bool) {
+	if data, ok := r.Context().Value(middleware.ContextUserKey).(*jwt.JWTData); ok {
+		return data.UserID, true
+	}
+	if session, ok := middleware.SessionFromContext(r.Context()); ok {
+		return session.User.ID, true
 	}
+	return 0, false
 }
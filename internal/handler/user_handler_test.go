@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"shorty/internal/common"
+	"shorty/internal/payload"
+)
+
+func TestQRRequestDefaults(t *testing.T) {
+	opts, err := qrRequest(httptest.NewRequest("GET", "/users/links/1/qr", nil))
+	if err != nil {
+		t.Fatalf("qrRequest: %v", err)
+	}
+	want := payload.QRRequest{Format: "png", Size: 512, Margin: 4, ECC: "M"}
+	if *opts != want {
+		t.Errorf("qrRequest = %+v, want %+v", *opts, want)
+	}
+}
+
+func TestQRRequestParams(t *testing.T) {
+	opts, err := qrRequest(httptest.NewRequest("GET", "/users/links/1/qr?format=svg&size=64&margin=0&ecc=h&fg=%23112233&bg=FFFFFF&logo=true", nil))
+	if err != nil {
+		t.Fatalf("qrRequest: %v", err)
+	}
+	want := payload.QRRequest{Format: "svg", Size: 64, Margin: 0, ECC: "h", FG: "112233", BG: "FFFFFF", Logo: true}
+	if *opts != want {
+		t.Errorf("qrRequest = %+v, want %+v", *opts, want)
+	}
+}
+
+func TestQRRequestInvalid(t *testing.T) {
+	tests := []struct {
+		query string
+		want  error
+	}{
+		{"size=big", common.ErrInvalidParam},
+		{"margin=1.5", common.ErrInvalidParam},
+		{"logo=maybe", common.ErrInvalidParam},
+		{"format=gif", common.ErrInvalidRequest},
+		{"size=63", common.ErrInvalidRequest},
+		{"size=2049", common.ErrInvalidRequest},
+		{"margin=-1", common.ErrInvalidRequest},
+		{"margin=17", common.ErrInvalidRequest},
+		{"ecc=X", common.ErrInvalidRequest},
+		{"fg=12345", common.ErrInvalidRequest},
+		{"bg=gggggg", common.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		_, err := qrRequest(httptest.NewRequest("GET", "/users/links/1/qr?"+tt.query, nil))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("qrRequest(%s) error = %v, want %v", tt.query, err, tt.want)
+		}
+	}
+}
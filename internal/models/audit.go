@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogImmutable is returned when something tries to change an audit record.
+var ErrAuditLogImmutable = errors.New("audit log is append-only")
+
+// Audit target types.
+const (
+	AuditTargetUser      = "user"
+	AuditTargetLink      = "link"
+	AuditTargetURLPolicy = "url_policy"
+)
+
+// AuditLog represents a single administrative action.
+// Diff holds the changed fields as {"field": {"before": ..., "after": ...}}.
+type AuditLog struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time      `gorm:"index" json:"created_at"`
+	ActorID    uint           `gorm:"index" json:"actor_id"`
+	ActorEmail string         `json:"actor_email"`
+	Action     string         `gorm:"index" json:"action"`
+	TargetType string         `gorm:"index:idx_audit_target" json:"target_type"`
+	TargetID   uint           `gorm:"index:idx_audit_target" json:"target_id"`
+	Diff       datatypes.JSON `json:"diff"`
+	IP         string         `json:"ip"`
+	RequestID  string         `json:"request_id"`
+}
+
+// BeforeUpdate forbids changing audit records.
+func (a *AuditLog) BeforeUpdate(*gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete forbids deleting audit records.
+func (a *AuditLog) BeforeDelete(*gorm.DB) error {
+	return ErrAuditLogImmutable
+}
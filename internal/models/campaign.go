@@ -0,0 +1,57 @@
+package models
+
+import (
+	"net/url"
+
+	"gorm.io/gorm"
+)
+
+// Campaign groups the links of one owner for a marketing campaign.
+// Name is unique per owner and is the default utm_campaign of its links.
+type Campaign struct {
+	gorm.Model
+	UserID      uint   `json:"user_id" gorm:"uniqueIndex:idx_campaigns_owner_name"`
+	Name        string `json:"name" gorm:"uniqueIndex:idx_campaigns_owner_name"`
+	Description string `json:"description"`
+}
+
+// UTM holds the campaign parameters merged into a link's destination on redirect.
+type UTM struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// IsZero reports whether no UTM parameter is set.
+func (u UTM) IsZero() bool {
+	return u == UTM{}
+}
+
+// Apply returns rawURL with the set UTM parameters added, replacing the
+// parameters of the same name already in the URL. A URL that cannot be
+// parsed is returned unchanged.
+func (u UTM) Apply(rawURL string) string {
+	if u.IsZero() {
+		return rawURL
+	}
+	dest, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := dest.Query()
+	for name, value := range map[string]string{
+		"utm_source":   u.Source,
+		"utm_medium":   u.Medium,
+		"utm_campaign": u.Campaign,
+		"utm_term":     u.Term,
+		"utm_content":  u.Content,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	dest.RawQuery = query.Encode()
+	return dest.String()
+}
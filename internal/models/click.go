@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ClickSourceQR marks visits that came from scanning the link's QR code.
+const ClickSourceQR = "qr"
+
+// Click represents a single visit of a short link. Daily totals are kept
+// in Stat; a Click keeps the details of one visit.
+type Click struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	LinkID         uint      `json:"link_id" gorm:"index"`
+	ClickID        string    `json:"click_id" gorm:"index"` // random ID handed to the destination for attribution
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`
+	Referrer       string    `json:"referrer"`
+	UserAgent      string    `json:"user_agent"`
+	ForwardedQuery string    `json:"forwarded_query,omitempty"`      // encoded query forwarded onto the destination
+	ForwardedPath  string    `json:"forwarded_path,omitempty"`       // trailing path forwarded onto the destination
+	MatchedRule    *int      `json:"matched_rule,omitempty"`         // index of the targeting rule used, nil for Url
+	Variant        string    `json:"variant,omitempty" gorm:"index"` // A/B variant the visitor was sent to
+	Source         string    `json:"source,omitempty" gorm:"index"`  // ClickSourceQR for scans, empty for clicks
+}
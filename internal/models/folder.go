@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// Folder groups the links of one owner. A link is in at most one folder;
+// Name is unique per owner.
+type Folder struct {
+	gorm.Model
+	UserID uint   `json:"user_id" gorm:"uniqueIndex:idx_folders_owner_name"`
+	Name   string `json:"name" gorm:"uniqueIndex:idx_folders_owner_name"`
+}
+
+// Tag labels links of one owner; a link can have many tags. Tags are created
+// on first use and their names are stored lowercased, unique per owner.
+type Tag struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"-" gorm:"uniqueIndex:idx_tags_owner_name"`
+	Name   string `json:"name" gorm:"uniqueIndex:idx_tags_owner_name"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+// QueryForward defines how a visitor's query parameters are forwarded
+// onto the destination of a link.
+type QueryForward string
+
+const (
+	QueryForwardOff      QueryForward = "off"      // the visitor's query is dropped, same as ""
+	QueryForwardMerge    QueryForward = "merge"    // added, the destination's parameters win on conflict
+	QueryForwardOverride QueryForward = "override" // added, the visitor's parameters win on conflict
+)
+
+// ForwardedQuery returns the part of the visitor's query that is forwarded
+// onto the destination, nil when query forwarding is off.
+func (l *Link) ForwardedQuery(query url.Values) url.Values {
+	if (l.ForwardQuery != QueryForwardMerge && l.ForwardQuery != QueryForwardOverride) || len(query) == 0 {
+		return nil
+	}
+	return query
+}
+
+// ForwardedPath returns the escaped trailing path that is forwarded onto
+// the destination, "" when path forwarding is off.
+func (l *Link) ForwardedPath(extraPath string) string {
+	if !l.ForwardPath {
+		return ""
+	}
+	return strings.Trim(extraPath, "/")
+}
+
+// RedirectURL returns the address a visitor is sent to: Url with the
+// visitor's trailing path (escaped, as in the request) and query forwarded
+// according to the link options, then the link's UTM parameters merged in.
+// A Url that cannot be parsed is returned as Destination does.
+func (l *Link) RedirectURL(extraPath string, query url.Values) string {
+	extraPath, query = l.ForwardedPath(extraPath), l.ForwardedQuery(query)
+	if extraPath == "" && query == nil {
+		return l.Destination()
+	}
+	dest, err := url.Parse(l.Url)
+	if err != nil {
+		return l.Destination()
+	}
+	if extraPath != "" {
+		joined := strings.TrimSuffix(dest.EscapedPath(), "/") + "/" + extraPath
+		if path, err := url.PathUnescape(joined); err == nil {
+			dest.Path, dest.RawPath = path, joined
+		}
+	}
+	if query != nil {
+		merged := dest.Query()
+		for name, values := range query {
+			if _, exists := merged[name]; exists && l.ForwardQuery == QueryForwardMerge {
+				continue
+			}
+			merged[name] = values
+		}
+		dest.RawQuery = merged.Encode()
+	}
+	return l.UTM.Apply(dest.String())
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRedirectURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      Link
+		extraPath string
+		query     string
+		want      string
+	}{
+		{
+			name:  "forwarding off",
+			link:  Link{Url: "https://example.com/a?x=1"},
+			query: "x=2&y=3", extraPath: "more",
+			want: "https://example.com/a?x=1",
+		},
+		{
+			name:  "merge keeps the destination's value",
+			link:  Link{Url: "https://example.com/a?x=1", ForwardQuery: QueryForwardMerge},
+			query: "x=2&y=3",
+			want:  "https://example.com/a?x=1&y=3",
+		},
+		{
+			name:  "override takes the visitor's value",
+			link:  Link{Url: "https://example.com/a?x=1", ForwardQuery: QueryForwardOverride},
+			query: "x=2&y=3",
+			want:  "https://example.com/a?x=2&y=3",
+		},
+		{
+			name: "empty visitor query",
+			link: Link{Url: "https://example.com/a?b=1&a=2", ForwardQuery: QueryForwardMerge},
+			want: "https://example.com/a?b=1&a=2",
+		},
+		{
+			name:      "path forwarding",
+			link:      Link{Url: "https://example.com/docs/", ForwardPath: true},
+			extraPath: "/guide/intro/",
+			want:      "https://example.com/docs/guide/intro",
+		},
+		{
+			name:      "escaped path stays escaped",
+			link:      Link{Url: "https://example.com/files", ForwardPath: true},
+			extraPath: "a%2Fb/c%20d",
+			want:      "https://example.com/files/a%2Fb/c%20d",
+		},
+		{
+			name:      "path and query with UTM",
+			link:      Link{Url: "https://example.com", ForwardPath: true, ForwardQuery: QueryForwardMerge, UTM: UTM{Source: "news", Campaign: "spring"}},
+			extraPath: "p",
+			query:     "ref=1&utm_source=visitor",
+			want:      "https://example.com/p?ref=1&utm_campaign=spring&utm_source=news",
+		},
+		{
+			name: "UTM only",
+			link: Link{Url: "https://example.com/?id=7", UTM: UTM{Medium: "email"}},
+			want: "https://example.com/?id=7&utm_medium=email",
+		},
+		{
+			name:      "unparsable destination",
+			link:      Link{Url: "https://example.com/%zz", ForwardPath: true},
+			extraPath: "x",
+			want:      "https://example.com/%zz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.link.RedirectURL(tt.extraPath, query); got != tt.want {
+				t.Errorf("RedirectURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,37 +1,95 @@
 package models
 
 import (
-	"crypto/rand"
-	"encoding/base64"
-	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// LinkCodeSequence is the database sequence that numbers generated link codes.
+const LinkCodeSequence = "link_code_seq"
+
+// LinkSearchDocument is the full-text search document of a link: its URL,
+// title, notes and hash. Queries must use the same expression to hit the
+// GIN index built on it.
+const LinkSearchDocument = "to_tsvector('simple', coalesce(url, '') || ' ' || coalesce(title, '') || ' ' || coalesce(notes, '') || ' ' || coalesce(hash, ''))"
+
+// LinkSearchIndex creates the GIN index that full-text search uses.
+const LinkSearchIndex = "CREATE INDEX IF NOT EXISTS idx_links_search ON links USING GIN (" + LinkSearchDocument + ")"
+
+// LinkSubstringIndexes create the trigram indexes that let substring search
+// (ILIKE '%...%') on the destination, hash and title use an index scan.
+// They need the pg_trgm extension.
+var LinkSubstringIndexes = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"CREATE INDEX IF NOT EXISTS idx_links_url_trgm ON links USING GIN (url gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_links_hash_trgm ON links USING GIN (hash gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS idx_links_title_trgm ON links USING GIN (title gin_trgm_ops)",
+}
+
 // Link represents the entity model for a shortened URL.
 type Link struct {
 	gorm.Model
 	Url       string `json:"url"`
+	FinalUrl  string `json:"final_url,omitempty"` // where Url leads after following its redirects
 	Hash      string `json:"hash" gorm:"uniqueIndex"`
 	Stats     []Stat `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	IsBlocked bool   `json:"is_blocked" gorm:"default:false"`
+	UserID    *uint  `json:"user_id,omitempty" gorm:"index;index:idx_links_owner_canonical,priority:1"` // owner, nil for anonymous links
+
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil for links that never expire
+
+	// CanonicalUrl is Url in canonical form, used to find an owner's existing
+	// link to the same destination.
+	CanonicalUrl string `json:"-" gorm:"index:idx_links_owner_canonical,priority:2"`
+
+	// Campaign tagging. UTM parameters are merged into Url on redirect.
+	CampaignID *uint `json:"campaign_id,omitempty" gorm:"index"`
+	UTM        UTM   `json:"utm" gorm:"embedded;embeddedPrefix:utm_"`
+
+	// Passthrough of the visitor's query and trailing path, see RedirectURL.
+	ForwardQuery QueryForward `json:"forward_query,omitempty" gorm:"size:16"`
+	ForwardPath  bool         `json:"forward_path" gorm:"default:false"`
+
+	// Targeting rules checked in order on redirect before falling back to Url.
+	Rules TargetRules `json:"rules,omitempty" gorm:"type:jsonb;serializer:json"`
+	// A/B split: visitors not caught by a rule are divided between the variants.
+	Variants Variants `json:"variants,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	// Organisation of the owner's links. Tags are not loaded with the link
+	// unless asked for; nil Tags mean "not loaded" to LinkService.Update.
+	FolderID *uint  `json:"folder_id,omitempty" gorm:"index"`
+	Tags     []Tag  `json:"tags,omitempty" gorm:"many2many:link_tags"`
+	Notes    string `json:"notes"`
+
+	// Preview page. With AlwaysPreview the interstitial is shown on every
+	// visit instead of redirecting straight away.
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	AlwaysPreview bool   `json:"always_preview" gorm:"default:false"`
 }
 
-// NewLink creates a new Link instance with a generated short hash.
+// NewLink creates a new Link instance. The short hash is assigned
+// by LinkService when the link is stored.
 func NewLink(url string) *Link {
-	return &Link{
-		Url:  url,
-		Hash: generateHash(10),
-	}
+	return &Link{Url: url}
+}
+
+// Destination returns the address a visitor is redirected to:
+// Url with the link's UTM parameters merged in.
+func (l *Link) Destination() string {
+	return l.UTM.Apply(l.Url)
+}
+
+// WithTarget returns a copy of the link that leads to url, used to redirect
+// by a targeting rule while keeping the link's forwarding and UTM options.
+func (l *Link) WithTarget(url string) *Link {
+	target := *l
+	target.Url = url
+	return &target
 }
 
-// generateHash generates a random base64-encoded string of the specified length.
-// It is used as a short identifier for the link.
-func generateHash(n int) string {
-	bytes := make([]byte, n)
-	if _, err := rand.Read(bytes); err != nil {
-		fmt.Println("Error generating random data:", err)
-		return ""
-	}
-	return base64.RawURLEncoding.EncodeToString(bytes)[:n]
+// IsExpired reports whether the link has expired at the given time.
+func (l *Link) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
 }
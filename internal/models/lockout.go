@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LockoutScope defines what a failed sign-in attempt is counted against.
+type LockoutScope string
+
+const (
+	LockoutScopeAccount LockoutScope = "account" // keyed by normalised email
+	LockoutScopeIP      LockoutScope = "ip"      // keyed by client IP address
+)
+
+// LoginLockout tracks failed sign-in attempts for an account or an IP address
+// and the lockout currently applied to it.
+type LoginLockout struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	Scope         LockoutScope `gorm:"uniqueIndex:idx_login_lockout_scope_key" json:"scope"`
+	Key           string       `gorm:"uniqueIndex:idx_login_lockout_scope_key" json:"key"`
+	Failures      int          `json:"failures"`
+	Lockouts      int          `json:"lockouts"`
+	LastFailureAt time.Time    `json:"last_failure_at"`
+	LockedUntil   *time.Time   `gorm:"index" json:"locked_until,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+// IsLocked reports whether the lockout is active at the given moment.
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
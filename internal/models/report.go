@@ -0,0 +1,34 @@
+package models
+
+import "gorm.io/gorm"
+
+// ReportReason defines why a link was reported.
+type ReportReason string
+
+const (
+	ReportReasonPhishing ReportReason = "phishing"
+	ReportReasonSpam     ReportReason = "spam"
+	ReportReasonMalware  ReportReason = "malware"
+	ReportReasonOther    ReportReason = "other"
+)
+
+// ReportStatus defines the moderation state of a report.
+type ReportStatus string
+
+const (
+	ReportStatusOpen      ReportStatus = "open"      // waiting for moderation
+	ReportStatusDismissed ReportStatus = "dismissed" // moderator found nothing wrong
+	ReportStatusActioned  ReportStatus = "actioned"  // link or owner was blocked
+)
+
+// Report represents an abuse report for a short link.
+// ReporterKey is a keyed hash of the reporter's IP, not the IP itself.
+type Report struct {
+	gorm.Model
+	LinkID      uint         `json:"link_id" gorm:"index"`
+	Reason      ReportReason `json:"reason"`
+	Comment     string       `json:"comment"`
+	ReporterKey string       `json:"-" gorm:"index"`
+	Status      ReportStatus `json:"status" gorm:"index;default:open"`
+	AutoBlocked bool         `json:"auto_blocked" gorm:"default:false"` // link was blocked automatically while the report was open
+}
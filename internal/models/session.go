@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Session represents a server-side web UI session. The ID is a hash of the
+// token stored in the session cookie, so a leaked database row cannot be
+// replayed as a cookie.
+type Session struct {
+	ID         string    `gorm:"primaryKey;size:64" json:"-"`
+	UserID     uint      `gorm:"index" json:"user_id"`
+	CSRFToken  string    `json:"-"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `gorm:"index" json:"expires_at"`
+}
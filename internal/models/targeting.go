@@ -0,0 +1,62 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// TargetRule sends matching visitors of a link to Url instead of the
+// link's own destination. Empty conditions match every visitor; a rule
+// with several conditions matches when all of them do.
+type TargetRule struct {
+	Name      string     `json:"name,omitempty"`
+	OS        []string   `json:"os,omitempty"`        // useragent.OS* values
+	Devices   []string   `json:"devices,omitempty"`   // useragent.Device* values
+	Languages []string   `json:"languages,omitempty"` // base language tags, e.g. "en"
+	Countries []string   `json:"countries,omitempty"` // ISO 3166-1 alpha-2 codes
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	Url       string     `json:"url"`
+}
+
+// TargetRules is an ordered list of rules; the first match wins.
+type TargetRules []TargetRule
+
+// Visitor describes the visitor a rule is matched against.
+type Visitor struct {
+	OS       string
+	Device   string
+	Language string
+	Country  string
+	At       time.Time
+}
+
+// Matches reports whether the rule applies to the visitor.
+func (r *TargetRule) Matches(v Visitor) bool {
+	return matchesAny(r.OS, v.OS) &&
+		matchesAny(r.Devices, v.Device) &&
+		matchesAny(r.Languages, v.Language) &&
+		matchesAny(r.Countries, v.Country) &&
+		(r.StartsAt == nil || !v.At.Before(*r.StartsAt)) &&
+		(r.EndsAt == nil || v.At.Before(*r.EndsAt))
+}
+
+// Match returns the index of the first rule matching the visitor, -1 if none does.
+func (rules TargetRules) Match(v Visitor) int {
+	for i := range rules {
+		if rules[i].Matches(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchesAny reports whether value is one of allowed, ignoring case.
+// An empty list allows any value.
+func matchesAny(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, value) })
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTargetRulesMatch(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	rules := TargetRules{
+		{Name: "ios-de", OS: []string{"ios"}, Countries: []string{"DE"}, Url: "https://example.com/ios-de"},
+		{Name: "mobile", Devices: []string{"mobile", "tablet"}, Url: "https://example.com/m"},
+		{Name: "spring", Languages: []string{"en"}, StartsAt: &start, EndsAt: &end, Url: "https://example.com/spring"},
+	}
+	during := start.Add(24 * time.Hour)
+	tests := []struct {
+		name    string
+		visitor Visitor
+		want    int
+	}{
+		{"all conditions of a rule", Visitor{OS: "ios", Device: "mobile", Country: "de", At: during}, 0},
+		{"first match wins", Visitor{OS: "ios", Device: "mobile", Country: "FR", At: during}, 1},
+		{"one of several values", Visitor{OS: "android", Device: "tablet", At: during}, 1},
+		{"inside the window", Visitor{OS: "windows", Device: "desktop", Language: "EN", At: during}, 2},
+		{"at the start", Visitor{Device: "desktop", Language: "en", At: start}, 2},
+		{"at the end", Visitor{Device: "desktop", Language: "en", At: end}, -1},
+		{"before the start", Visitor{Device: "desktop", Language: "en", At: start.Add(-time.Second)}, -1},
+		{"no match", Visitor{OS: "windows", Device: "desktop", Language: "de", At: during}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Match(tt.visitor); got != tt.want {
+				t.Errorf("Match = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := (TargetRules{{Url: "https://example.com"}}).Match(Visitor{}); got != 0 {
+		t.Errorf("a rule without conditions matched %d, want 0", got)
+	}
+	if got := TargetRules(nil).Match(Visitor{OS: "ios"}); got != -1 {
+		t.Errorf("no rules matched %d, want -1", got)
+	}
+}
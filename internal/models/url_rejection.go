@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// URLRejection records a destination URL refused by the URL policy.
+// Moderators review these to spot abuse and tune the domain lists.
+type URLRejection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	URL       string    `json:"url"`
+	Host      string    `gorm:"index" json:"host"`
+	Reason    string    `gorm:"index" json:"reason"`
+	Detail    string    `json:"detail"`
+	UserID    *uint     `gorm:"index" json:"user_id"`
+	IP        string    `json:"ip"`
+}
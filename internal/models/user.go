@@ -23,6 +23,7 @@ type User struct {
 	Password  string         `json:"password,omitempty"`
 	Role      Role           `json:"role"`
 	IsBlocked bool           `json:"is_blocked" gorm:"default:false"`
+	Locale    string         `json:"locale,omitempty" gorm:"size:8"` // Preferred UI/API language; empty means negotiate
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deletedAt,omitempty"`
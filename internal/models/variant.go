@@ -0,0 +1,60 @@
+package models
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Variant is one of the weighted destinations of an A/B split link.
+type Variant struct {
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// Variants are the destinations a split link divides its visitors between.
+type Variants []Variant
+
+// Find returns the variant with the given name.
+func (vs Variants) Find(name string) (*Variant, bool) {
+	for i := range vs {
+		if vs[i].Name == name {
+			return &vs[i], true
+		}
+	}
+	return nil, false
+}
+
+// Pick chooses a variant for a visitor key in proportion to the weights.
+// The same key gets the same variant as long as the variants do not change.
+func (vs Variants) Pick(key string) *Variant {
+	total := 0
+	for _, v := range vs {
+		total += max(v.Weight, 0)
+	}
+	if total == 0 {
+		return nil
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	bucket := int(h.Sum64() % uint64(total))
+	for i := range vs {
+		if bucket -= max(vs[i].Weight, 0); bucket < 0 {
+			return &vs[i]
+		}
+	}
+	return nil
+}
+
+// Conversion represents a goal reached by a visitor of a link, reported by
+// the destination site. ClickID is the click the conversion is attributed to,
+// empty when it was reported for the link alone. Variant is the A/B variant
+// the visitor was sent to.
+type Conversion struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	LinkID    uint      `json:"link_id" gorm:"index"`
+	ClickID   string    `json:"click_id,omitempty" gorm:"index"`
+	Variant   string    `json:"variant,omitempty" gorm:"index"`
+	Value     float64   `json:"value,omitempty"` // optional order value or score
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestVariantsPickIsSticky(t *testing.T) {
+	vs := Variants{{Name: "A", Weight: 50}, {Name: "B", Weight: 50}}
+	for i := 0; i < 100; i++ {
+		key := "visitor-" + strconv.Itoa(i)
+		first := vs.Pick(key)
+		if first == nil {
+			t.Fatalf("Pick(%q) = nil", key)
+		}
+		if again := vs.Pick(key); again.Name != first.Name {
+			t.Fatalf("Pick(%q) = %s, then %s", key, first.Name, again.Name)
+		}
+	}
+}
+
+func TestVariantsPickFollowsWeights(t *testing.T) {
+	vs := Variants{{Name: "A", Weight: 70}, {Name: "B", Weight: 20}, {Name: "C", Weight: 10}, {Name: "off", Weight: 0}}
+	const n = 20000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[vs.Pick("key-"+strconv.Itoa(i)).Name]++
+	}
+	for _, v := range vs {
+		share := float64(counts[v.Name]) / n
+		want := float64(v.Weight) / 100
+		if math.Abs(share-want) > 0.02 {
+			t.Errorf("variant %s got %.3f of the visitors, want about %.2f", v.Name, share, want)
+		}
+	}
+	if counts["off"] != 0 {
+		t.Errorf("a variant with weight 0 got %d visitors", counts["off"])
+	}
+}
+
+func TestVariantsPickWithoutWeight(t *testing.T) {
+	for _, vs := range []Variants{nil, {{Name: "A"}}, {{Name: "A", Weight: -5}, {Name: "B", Weight: 0}}} {
+		if got := vs.Pick("key"); got != nil {
+			t.Errorf("Pick over %v = %v, want nil", vs, got)
+		}
+	}
+	// A negative weight counts as zero.
+	vs := Variants{{Name: "A", Weight: -10}, {Name: "B", Weight: 1}}
+	if got := vs.Pick("key"); got == nil || got.Name != "B" {
+		t.Errorf("Pick = %v, want B", got)
+	}
+}
+
+func TestVariantsFind(t *testing.T) {
+	vs := Variants{{Name: "A", Url: "https://a.example"}, {Name: "B", Url: "https://b.example"}}
+	if v, ok := vs.Find("B"); !ok || v.Url != "https://b.example" {
+		t.Errorf("Find(B) = %v, %v", v, ok)
+	}
+	if _, ok := vs.Find("b"); ok {
+		t.Error("Find is case-sensitive, but found b")
+	}
+	if _, ok := vs.Find(""); ok {
+		t.Error("Find found a variant without a name")
+	}
+}
@@ -0,0 +1,14 @@
+package payload
+
+import "time"
+
+// AuditLogFilter represents the filters for listing audit log records.
+// Zero values mean "no filter".
+type AuditLogFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   uint
+	From       time.Time
+	To         time.Time
+}
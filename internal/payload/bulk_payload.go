@@ -0,0 +1,73 @@
+package payload
+
+import (
+	"time"
+
+	"shorty/internal/models"
+)
+
+// Bulk creation modes.
+const (
+	BulkModePartial = "partial" // valid rows are created, failed rows are reported
+	BulkModeAtomic  = "atomic"  // all rows are created in one transaction, or none
+)
+
+// Bulk job statuses.
+const (
+	BulkJobPending = "pending"
+	BulkJobRunning = "running"
+	BulkJobDone    = "done"
+	BulkJobFailed  = "failed" // nothing was created in atomic mode, or the job broke off
+)
+
+// BulkLinkRequest represents one link of a bulk creation request: an element
+// of a JSON array or a CSV row with the columns url, alias, tags and expiry.
+type BulkLinkRequest struct {
+	URL       string     `json:"url" validate:"required,urlpolicy"`
+	Alias     string     `json:"alias" validate:"omitempty,alias"`
+	Tags      []string   `json:"tags" validate:"max=20,dive,required,max=50"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Model converts the request to a new link of the owner.
+func (r *BulkLinkRequest) Model(ownerID uint) *models.Link {
+	link := models.NewLink(r.URL)
+	link.Hash = r.Alias
+	link.Tags = Tags(r.Tags)
+	link.ExpiresAt = r.ExpiresAt
+	link.UserID = &ownerID
+	return link
+}
+
+// BulkLinkRow is a numbered row of a bulk request: the element number of a
+// JSON array or the line of a CSV file. Err is set for a row that could not
+// be parsed or validated; it is reported and not created.
+type BulkLinkRow struct {
+	Row  int
+	Link BulkLinkRequest
+	Err  error
+}
+
+// BulkLinkResult is the outcome of one row: the created link or the error.
+type BulkLinkResult struct {
+	Row  int
+	Link *models.Link
+	Err  error
+}
+
+// BulkJob represents the state of a bulk creation. Results are filled in
+// once the job is finished.
+type BulkJob struct {
+	ID         string
+	OwnerID    uint
+	Mode       string
+	Status     string
+	Total      int
+	Processed  int
+	Created    int
+	Failed     int
+	Results    []BulkLinkResult
+	Err        error // why the job failed as a whole
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
@@ -0,0 +1,7 @@
+package payload
+
+// CreateCampaignRequest represents the request payload for creating a campaign.
+type CreateCampaignRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+}
@@ -0,0 +1,13 @@
+package payload
+
+// CreateFolderRequest represents the request payload for creating a folder.
+type CreateFolderRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// TagListItem represents a tag of a user with the number of its links.
+type TagListItem struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Links int64  `json:"links"`
+}
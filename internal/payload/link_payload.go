@@ -1,17 +1,171 @@
 package payload
 
-import "shorty/internal/models"
+import (
+	"strings"
+	"time"
+
+	"shorty/internal/models"
+)
 
 // CreateLinkRequest represents the request payload for creating a new shortened link.
 type CreateLinkRequest struct {
-	URL string `json:"url" validate:"required,url"`
+	URL           string     `json:"url" validate:"required,urlpolicy"`
+	Title         string     `json:"title" validate:"max=200"`
+	Description   string     `json:"description" validate:"max=1000"`
+	AlwaysPreview bool       `json:"always_preview"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+
+	// Campaign tagging, merged into the destination on redirect.
+	CampaignID  *uint  `json:"campaign_id"`
+	UTMSource   string `json:"utm_source" validate:"max=200"`
+	UTMMedium   string `json:"utm_medium" validate:"max=200"`
+	UTMCampaign string `json:"utm_campaign" validate:"max=200"`
+	UTMTerm     string `json:"utm_term" validate:"max=200"`
+	UTMContent  string `json:"utm_content" validate:"max=200"`
+
+	// Passthrough of the visitor's query ("off", "merge" or "override") and trailing path.
+	ForwardQuery string `json:"forward_query" validate:"omitempty,oneof=off merge override"`
+	ForwardPath  bool   `json:"forward_path"`
+
+	// Targeting rules, checked in order before falling back to URL.
+	Rules []TargetRuleRequest `json:"rules" validate:"max=20,dive"`
+	// A/B split between weighted destinations.
+	Variants []VariantRequest `json:"variants" validate:"max=10,unique=Name,dive"`
+
+	// Organisation: tags are created on first use.
+	FolderID *uint    `json:"folder_id"`
+	Tags     []string `json:"tags" validate:"max=20,dive,required,max=50"`
+	Notes    string   `json:"notes" validate:"max=5000"`
+}
+
+// UTM returns the campaign parameters of the request.
+func (r *CreateLinkRequest) UTM() models.UTM {
+	return models.UTM{
+		Source:   r.UTMSource,
+		Medium:   r.UTMMedium,
+		Campaign: r.UTMCampaign,
+		Term:     r.UTMTerm,
+		Content:  r.UTMContent,
+	}
 }
 
 // UpdateLinkRequest represents the request payload for updating an existing shortened link.
+// Omitted optional fields keep their current values.
 type UpdateLinkRequest struct {
-	URL       string `json:"url" validate:"required,url"`
-	Hash      string `json:"hash"`
-	IsBlocked bool   `json:"is_blocked"`
+	URL           string     `json:"url" validate:"required,urlpolicy"`
+	Hash          string     `json:"hash" validate:"omitempty,alias"`
+	IsBlocked     bool       `json:"is_blocked"`
+	Title         *string    `json:"title" validate:"omitempty,max=200"`
+	Description   *string    `json:"description" validate:"omitempty,max=1000"`
+	AlwaysPreview *bool      `json:"always_preview"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+
+	CampaignID  *uint   `json:"campaign_id"`
+	UTMSource   *string `json:"utm_source" validate:"omitempty,max=200"`
+	UTMMedium   *string `json:"utm_medium" validate:"omitempty,max=200"`
+	UTMCampaign *string `json:"utm_campaign" validate:"omitempty,max=200"`
+	UTMTerm     *string `json:"utm_term" validate:"omitempty,max=200"`
+	UTMContent  *string `json:"utm_content" validate:"omitempty,max=200"`
+
+	ForwardQuery *string `json:"forward_query" validate:"omitempty,oneof=off merge override"`
+	ForwardPath  *bool   `json:"forward_path"`
+
+	// Rules replaces all targeting rules; an empty list removes them.
+	Rules *[]TargetRuleRequest `json:"rules" validate:"omitempty,max=20,dive"`
+	// Variants replaces all A/B variants; an empty list ends the split.
+	Variants *[]VariantRequest `json:"variants" validate:"omitempty,max=10,unique=Name,dive"`
+
+	// FolderID moves the link to a folder, 0 takes it out of its folder.
+	// Tags replaces all tags; an empty list removes them.
+	FolderID *uint    `json:"folder_id"`
+	Tags     []string `json:"tags" validate:"omitempty,max=20,dive,required,max=50"`
+	Notes    *string  `json:"notes" validate:"omitempty,max=5000"`
+}
+
+// VariantRequest represents an A/B variant in link requests.
+type VariantRequest struct {
+	Name   string `json:"name" validate:"required,alias"`
+	URL    string `json:"url" validate:"required,urlpolicy"`
+	Weight int    `json:"weight" validate:"required,min=1,max=1000"`
+}
+
+// Variants converts the requested variants to the link model.
+func Variants(reqs []VariantRequest) models.Variants {
+	variants := make(models.Variants, len(reqs))
+	for i, r := range reqs {
+		variants[i] = models.Variant{Name: r.Name, Url: r.URL, Weight: r.Weight}
+	}
+	return variants
+}
+
+// Tags converts the requested tag names to the link model. A nil list stays
+// nil, so that an update without tags keeps the link's tags.
+func Tags(names []string) []models.Tag {
+	if names == nil {
+		return nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{Name: name}
+	}
+	return tags
+}
+
+// TargetRuleRequest represents a targeting rule in link requests.
+type TargetRuleRequest struct {
+	Name      string     `json:"name" validate:"max=100"`
+	OS        []string   `json:"os" validate:"dive,oneof=ios android windows macos chromeos linux other"`
+	Devices   []string   `json:"devices" validate:"dive,oneof=mobile tablet desktop bot"`
+	Languages []string   `json:"languages" validate:"dive,alpha,min=2,max=3"`
+	Countries []string   `json:"countries" validate:"dive,alpha,len=2"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	URL       string     `json:"url" validate:"required,urlpolicy"`
+}
+
+// TargetRules converts the requested rules to the link model.
+func TargetRules(reqs []TargetRuleRequest) models.TargetRules {
+	rules := make(models.TargetRules, len(reqs))
+	for i, r := range reqs {
+		rules[i] = models.TargetRule{
+			Name:      r.Name,
+			OS:        r.OS,
+			Devices:   r.Devices,
+			Languages: r.Languages,
+			Countries: upper(r.Countries),
+			StartsAt:  r.StartsAt,
+			EndsAt:    r.EndsAt,
+			Url:       r.URL,
+		}
+	}
+	return rules
+}
+
+// upper returns the values in upper case.
+func upper(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToUpper(v)
+	}
+	return out
+}
+
+// ApplyUTM overwrites the UTM parameters given in the request.
+func (r *UpdateLinkRequest) ApplyUTM(utm *models.UTM) {
+	for _, field := range []struct {
+		value *string
+		dst   *string
+	}{
+		{r.UTMSource, &utm.Source},
+		{r.UTMMedium, &utm.Medium},
+		{r.UTMCampaign, &utm.Campaign},
+		{r.UTMTerm, &utm.Term},
+		{r.UTMContent, &utm.Content},
+	} {
+		if field.value != nil {
+			*field.dst = *field.value
+		}
+	}
 }
 
 // BlockLinkRequest represents the request payload for blocking or unblocking a shortened link.
@@ -19,8 +173,47 @@ type BlockLinkRequest struct {
 	IsBlocked bool `json:"is_blocked"`
 }
 
-// GetAllLinksResponse represents the response payload containing a list of links and their count.
+// GetAllLinksResponse represents the response payload containing a page of
+// links and the number of links matching the filter.
 type GetAllLinksResponse struct {
-	Count int64         `json:"count"`
-	Links []models.Link `json:"url"`
+	Count int64          `json:"count"`
+	Links []LinkListItem `json:"url"`
+}
+
+// Link list sort orders.
+const (
+	LinkSortNewest    = "newest"
+	LinkSortOldest    = "oldest"
+	LinkSortClicks    = "clicks"
+	LinkSortURL       = "url"
+	LinkSortRelevance = "relevance" // best full-text match first, newest without Query
+)
+
+// Link list statuses.
+const (
+	LinkStatusActive  = "active"  // neither blocked nor expired
+	LinkStatusBlocked = "blocked" // blocked by a moderator
+	LinkStatusExpired = "expired" // past its expiry time
+)
+
+// LinkListFilter represents the criteria for listing links.
+// A nil OwnerID lists the links of all users.
+type LinkListFilter struct {
+	OwnerID  *uint
+	Query    string   // full-text query over the destination, title, notes and hash
+	Tags     []string // links with all of these tags
+	FolderID *uint    // links in this folder, 0 for links in no folder
+	Status   string   // one of the LinkStatus* constants, any status if empty
+	Sort     string   // one of the LinkSort* constants, newest by default
+
+	// Substring also matches Query as a substring of the destination, hash
+	// or title. It needs the trigram indexes of migrations/search.
+	Substring bool
+}
+
+// LinkListItem represents a link in a list together with its click total and owner.
+type LinkListItem struct {
+	models.Link `gorm:"embedded"`
+	TotalClicks int64  `json:"total_clicks"`
+	OwnerEmail  string `json:"owner_email,omitempty"`
 }
@@ -0,0 +1,20 @@
+package payload
+
+// QRRequest represents the options of a link's QR code image, given as
+// query parameters of the same names.
+type QRRequest struct {
+	Format string `json:"format" validate:"oneof=png svg"`
+	Size   int    `json:"size" validate:"min=64,max=2048"`           // largest side in pixels
+	Margin int    `json:"margin" validate:"min=0,max=16"`            // quiet zone in modules
+	ECC    string `json:"ecc" validate:"oneof=L M Q H l m q h"`      // error correction level
+	FG     string `json:"fg" validate:"omitempty,len=6,hexadecimal"` // colour of dark modules, "rrggbb"
+	BG     string `json:"bg" validate:"omitempty,len=6,hexadecimal"` // background colour, "rrggbb"
+	Logo   bool   `json:"logo"`                                      // draw the configured logo over the centre
+}
+
+// QRImage represents a rendered QR code.
+type QRImage struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+}
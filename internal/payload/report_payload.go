@@ -0,0 +1,23 @@
+package payload
+
+import "time"
+
+// ReportLinkRequest represents the request payload for reporting a short link.
+type ReportLinkRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=phishing spam malware other"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+// ReportQueueItem represents the open reports of a single link in the moderation queue.
+type ReportQueueItem struct {
+	LinkID         uint      `json:"link_id"`
+	Hash           string    `json:"hash"`
+	URL            string    `json:"url"`
+	OwnerID        *uint     `json:"owner_id"`
+	IsBlocked      bool      `json:"is_blocked"`
+	AutoBlocked    bool      `json:"auto_blocked"`
+	Reports        int64     `json:"reports"`
+	Reporters      int64     `json:"reporters"`
+	Reasons        string    `json:"reasons"`
+	LastReportedAt time.Time `json:"last_reported_at"`
+}
@@ -1,5 +1,25 @@
 package payload
 
+import (
+	"net/url"
+	"time"
+)
+
+// ClickEvent represents a visit of a short link published on the event bus
+// as the data of event.EventLinkVisited.
+type ClickEvent struct {
+	LinkID         uint
+	ClickID        string // ID handed to the destination for conversion attribution
+	At             time.Time
+	Referrer       string
+	UserAgent      string
+	ForwardedQuery url.Values // query parameters forwarded onto the destination
+	ForwardedPath  string     // trailing path forwarded onto the destination
+	MatchedRule    *int       // index of the targeting rule used, nil for the link's Url
+	Variant        string     // A/B variant the visitor was sent to
+	Source         string     // models.ClickSourceQR for scans, empty for clicks
+}
+
 // GetStatsResponse represents a response containing general statistics over a specific period.
 type GetStatsResponse struct {
 	Period string `json:"period"`
@@ -13,4 +33,56 @@ type LinkStatsResponse struct {
 	TotalClicks   int64  `json:"total_clicks"`
 	LastClickDate string `json:"last_click_date"`
 	BlockedCount  int64  `json:"blocked_count"`
+	Conversions   int64  `json:"conversions"`
+	Scans         int64  `json:"scans"` // visits from the QR code, included in TotalClicks
+}
+
+// LinkStatsFilter represents the criteria for per-link statistics.
+// A nil CampaignID includes the links of all campaigns and links without one.
+type LinkStatsFilter struct {
+	From       time.Time
+	To         time.Time
+	CampaignID *uint
+}
+
+// CampaignStatsResponse represents aggregated statistics for a campaign.
+type CampaignStatsResponse struct {
+	CampaignID    uint       `json:"campaign_id"`
+	Name          string     `json:"name"`
+	UserID        uint       `json:"user_id"`
+	Links         int64      `json:"links"`
+	TotalClicks   int64      `json:"total_clicks"`
+	LastClickDate *time.Time `json:"last_click_date,omitempty"`
+}
+
+// ConversionRequest represents a conversion reported by the destination site.
+// It is attributed to the click with ClickID, taken from the visitor's
+// cookie when omitted. The link and the A/B variant always come from the
+// click; Hash, if set, only has to name the same link.
+type ConversionRequest struct {
+	ClickID string  `json:"click_id" validate:"omitempty,max=64"`
+	Hash    string  `json:"hash" validate:"omitempty,max=64"`
+	Value   float64 `json:"value" validate:"min=0"`
+}
+
+// VariantStatsResponse represents the clicks and conversions of an A/B variant.
+type VariantStatsResponse struct {
+	Variant        string  `json:"variant"`
+	Weight         int     `json:"weight"`
+	Clicks         int64   `json:"clicks"`
+	Conversions    int64   `json:"conversions"`
+	ConversionRate float64 `json:"conversion_rate"` // conversions per click
+}
+
+// VariantCount represents a number of events per A/B variant.
+type VariantCount struct {
+	Variant string
+	Count   int64
+}
+
+// DailyClicks represents the number of clicks on a link on a single day.
+type DailyClicks struct {
+	LinkID uint      `json:"link_id"`
+	Date   time.Time `json:"date"`
+	Clicks int64     `json:"clicks"`
 }
@@ -0,0 +1,6 @@
+package payload
+
+// DomainRequest represents the request payload for adding a domain to a URL policy list.
+type DomainRequest struct {
+	Domain string `json:"domain" validate:"required,max=253"`
+}
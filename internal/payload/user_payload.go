@@ -4,10 +4,10 @@ import "shorty/internal/models"
 
 // CreateUserRequest represents the request payload for creating a new user.
 type CreateUserRequest struct {
-	Name      string      `json:"name" validate:"required"`
+	Name      string      `json:"name" validate:"required,max=100"`
 	Email     string      `json:"email" validate:"required,email"`
-	Password  string      `json:"password" validate:"required"`
-	Role      models.Role `json:"role" validate:"required"`
+	Password  string      `json:"password" validate:"required,password"`
+	Role      models.Role `json:"role" validate:"required,oneof=user admin"`
 	IsBlocked bool        `json:"is_blocked"`
 }
 
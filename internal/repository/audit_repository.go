@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// AuditRepository handles database operations for the AuditLog entity.
+// It only appends and reads records: the audit log is never changed.
+type AuditRepository struct {
+	Database *db.DB
+}
+
+// NewAuditRepository creates a new instance of AuditRepository.
+func NewAuditRepository(db *db.DB) *AuditRepository {
+	return &AuditRepository{Database: db}
+}
+
+// CreateAuditLog appends a record to the audit log.
+func (r *AuditRepository) CreateAuditLog(ctx context.Context, entry *models.AuditLog) error {
+	res := r.Database.DB.WithContext(ctx).Create(entry)
+	if res.Error != nil {
+		logger.Error("Failed to write audit log", zap.String("action", entry.Action), zap.Error(res.Error))
+		return fmt.Errorf("failed to write audit log: %w", res.Error)
+	}
+	return nil
+}
+
+// GetAuditLogs returns a page of audit log records matching the filter, newest first.
+func (r *AuditRepository) GetAuditLogs(ctx context.Context, filter payload.AuditLogFilter, limit, offset int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	res := r.filtered(ctx, filter).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries)
+	if res.Error != nil {
+		logger.Error("Failed to get audit log", zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to get audit log: %w", res.Error)
+	}
+	return entries, nil
+}
+
+// CountAuditLogs returns the number of audit log records matching the filter.
+func (r *AuditRepository) CountAuditLogs(ctx context.Context, filter payload.AuditLogFilter) (int64, error) {
+	var count int64
+	res := r.filtered(ctx, filter).Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count audit log", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count audit log: %w", res.Error)
+	}
+	return count, nil
+}
+
+// filtered builds a query with the filter conditions applied.
+func (r *AuditRepository) filtered(ctx context.Context, filter payload.AuditLogFilter) *gorm.DB {
+	query := r.Database.DB.WithContext(ctx).Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
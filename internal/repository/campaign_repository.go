@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// CampaignRepository handles database operations for the Campaign entity.
+type CampaignRepository struct {
+	Database *db.DB
+}
+
+// NewCampaignRepository creates a new instance of CampaignRepository.
+func NewCampaignRepository(db *db.DB) *CampaignRepository {
+	return &CampaignRepository{Database: db}
+}
+
+// CreateCampaign stores a new campaign. A duplicate name of the same owner
+// fails with gorm.ErrDuplicatedKey.
+func (r *CampaignRepository) CreateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	if err := r.Database.DB.WithContext(ctx).Create(campaign).Error; err != nil {
+		logger.Error("Failed to create campaign", zap.Uint("userID", campaign.UserID), zap.Error(err))
+		return fmt.Errorf("failed to create campaign: %w", err)
+	}
+	return nil
+}
+
+// GetCampaigns returns the campaigns of an owner ordered by name.
+func (r *CampaignRepository) GetCampaigns(ctx context.Context, ownerID uint) ([]models.Campaign, error) {
+	var campaigns []models.Campaign
+	result := r.Database.DB.WithContext(ctx).
+		Where("user_id = ?", ownerID).
+		Order("name ASC").
+		Find(&campaigns)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve campaigns", zap.Uint("userID", ownerID), zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to retrieve campaigns: %w", result.Error)
+	}
+	return campaigns, nil
+}
+
+// FindCampaign retrieves a campaign of an owner by ID.
+func (r *CampaignRepository) FindCampaign(ctx context.Context, id, ownerID uint) (*models.Campaign, error) {
+	var campaign models.Campaign
+	result := r.Database.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, ownerID).
+		First(&campaign)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		logger.Error("Failed to find campaign", zap.Uint("campaignID", id), zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return &campaign, nil
+}
+
+// DeleteCampaign removes a campaign of an owner and detaches its links.
+// The row is deleted for good so that the name can be used again.
+func (r *CampaignRepository) DeleteCampaign(ctx context.Context, id, ownerID uint) error {
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND user_id = ?", id, ownerID).Delete(&models.Campaign{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Link{}).
+			Where("campaign_id = ?", id).
+			Update("campaign_id", nil).Error
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Failed to delete campaign", zap.Uint("campaignID", id), zap.Error(err))
+		return fmt.Errorf("failed to delete campaign: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// FolderRepository handles database operations for the Folder entity.
+type FolderRepository struct {
+	Database *db.DB
+}
+
+// NewFolderRepository creates a new instance of FolderRepository.
+func NewFolderRepository(db *db.DB) *FolderRepository {
+	return &FolderRepository{Database: db}
+}
+
+// CreateFolder stores a new folder. A duplicate name of the same owner
+// fails with gorm.ErrDuplicatedKey.
+func (r *FolderRepository) CreateFolder(ctx context.Context, folder *models.Folder) error {
+	if err := r.Database.DB.WithContext(ctx).Create(folder).Error; err != nil {
+		logger.Error("Failed to create folder", zap.Uint("userID", folder.UserID), zap.Error(err))
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	return nil
+}
+
+// GetFolders returns the folders of an owner ordered by name.
+func (r *FolderRepository) GetFolders(ctx context.Context, ownerID uint) ([]models.Folder, error) {
+	var folders []models.Folder
+	result := r.Database.DB.WithContext(ctx).
+		Where("user_id = ?", ownerID).
+		Order("name ASC").
+		Find(&folders)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve folders", zap.Uint("userID", ownerID), zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to retrieve folders: %w", result.Error)
+	}
+	return folders, nil
+}
+
+// FindFolder retrieves a folder of an owner by ID.
+func (r *FolderRepository) FindFolder(ctx context.Context, id, ownerID uint) (*models.Folder, error) {
+	var folder models.Folder
+	result := r.Database.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, ownerID).
+		First(&folder)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		logger.Error("Failed to find folder", zap.Uint("folderID", id), zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return &folder, nil
+}
+
+// DeleteFolder removes a folder of an owner and takes its links out of it.
+// The row is deleted for good so that the name can be used again.
+func (r *FolderRepository) DeleteFolder(ctx context.Context, id, ownerID uint) error {
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND user_id = ?", id, ownerID).Delete(&models.Folder{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Link{}).
+			Where("folder_id = ?", id).
+			Update("folder_id", nil).Error
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Failed to delete folder", zap.Uint("folderID", id), zap.Error(err))
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+	return err
+}
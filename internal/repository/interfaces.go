@@ -10,8 +10,11 @@ import (
 
 type LinkRepo interface {
 	CreateLink(ctx context.Context, link *models.Link) (*models.Link, error)
+	CreateLinks(ctx context.Context, links []*models.Link) error
 	GetLinks(ctx context.Context, limit, offset int) ([]models.Link, error)
 	GetLinkHash(ctx context.Context, hash string) (*models.Link, error)
+	FindLinkByHash(ctx context.Context, hash string) (*models.Link, error)
+	FindOwnedLinkByCanonicalURL(ctx context.Context, ownerID uint, canonicalURL string) (*models.Link, error)
 	UpdateLink(ctx context.Context, link *models.Link) (*models.Link, error)
 	DeleteLink(ctx context.Context, linkID uint) error
 	CountLinks(ctx context.Context) (int64, error)
@@ -21,12 +24,24 @@ type LinkRepo interface {
 	GetBlockedLinksCount(ctx context.Context) (int64, error)
 	GetDeletedLinksCount(ctx context.Context) (int64, error)
 	GetTotalLinks(ctx context.Context) (int64, error)
+	CountCodes(ctx context.Context, length int) (int64, error)
+	NextCodeNumber(ctx context.Context) (uint64, error)
+	SearchLinks(ctx context.Context, filter payload.LinkListFilter, limit, offset int) ([]payload.LinkListItem, error)
+	CountSearchLinks(ctx context.Context, filter payload.LinkListFilter) (int64, error)
 }
 
 type StatRepo interface {
 	AddClick(ctx context.Context, linkID uint) error
+	SaveClick(ctx context.Context, click *models.Click) error
+	FindClick(ctx context.Context, clickID string) (*models.Click, error)
+	SaveConversion(ctx context.Context, conversion *models.Conversion) error
+	ClickConverted(ctx context.Context, clickID string) (bool, error)
+	CountVariantClicks(ctx context.Context, linkID uint) ([]payload.VariantCount, error)
+	CountVariantConversions(ctx context.Context, linkID uint) ([]payload.VariantCount, error)
 	GetClickedLinkStats(ctx context.Context, by string, from, to time.Time) []payload.GetStatsResponse
-	GetAllLinksStats(ctx context.Context, from, to time.Time) []payload.LinkStatsResponse
+	GetAllLinksStats(ctx context.Context, filter payload.LinkStatsFilter) []payload.LinkStatsResponse
+	GetCampaignStats(ctx context.Context, ownerID *uint, from, to time.Time) ([]payload.CampaignStatsResponse, error)
+	GetDailyClicks(ctx context.Context, linkIDs []uint, from, to time.Time) ([]payload.DailyClicks, error)
 }
 
 type UserRepo interface {
@@ -44,3 +59,63 @@ type UserRepo interface {
 	UserExists(ctx context.Context, userID uint) (bool, error)
 	EmailExists(ctx context.Context, email string) (bool, error)
 }
+
+type LockoutRepo interface {
+	GetLockouts(ctx context.Context, scope models.LockoutScope, keys ...string) ([]models.LoginLockout, error)
+	RegisterFailure(ctx context.Context, scope models.LockoutScope, key string, apply func(l *models.LoginLockout)) (*models.LoginLockout, error)
+	ResetLockout(ctx context.Context, scope models.LockoutScope, key string) error
+	GetActiveLockouts(ctx context.Context, now time.Time, limit, offset int) ([]models.LoginLockout, error)
+	CountActiveLockouts(ctx context.Context, now time.Time) (int64, error)
+	DeleteLockout(ctx context.Context, id uint) error
+}
+
+type SessionRepo interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	TouchSession(ctx context.Context, id string, seenAt time.Time) error
+	DeleteSession(ctx context.Context, id string) error
+	DeleteExpiredSessions(ctx context.Context, now, idleSince time.Time) (int64, error)
+}
+
+type AuditRepo interface {
+	CreateAuditLog(ctx context.Context, entry *models.AuditLog) error
+	GetAuditLogs(ctx context.Context, filter payload.AuditLogFilter, limit, offset int) ([]models.AuditLog, error)
+	CountAuditLogs(ctx context.Context, filter payload.AuditLogFilter) (int64, error)
+}
+
+type CampaignRepo interface {
+	CreateCampaign(ctx context.Context, campaign *models.Campaign) error
+	GetCampaigns(ctx context.Context, ownerID uint) ([]models.Campaign, error)
+	FindCampaign(ctx context.Context, id, ownerID uint) (*models.Campaign, error)
+	DeleteCampaign(ctx context.Context, id, ownerID uint) error
+}
+
+type FolderRepo interface {
+	CreateFolder(ctx context.Context, folder *models.Folder) error
+	GetFolders(ctx context.Context, ownerID uint) ([]models.Folder, error)
+	FindFolder(ctx context.Context, id, ownerID uint) (*models.Folder, error)
+	DeleteFolder(ctx context.Context, id, ownerID uint) error
+}
+
+type TagRepo interface {
+	EnsureTags(ctx context.Context, ownerID uint, names []string) ([]models.Tag, error)
+	ReplaceLinkTags(ctx context.Context, link *models.Link, tags []models.Tag) error
+	GetLinkTags(ctx context.Context, linkIDs []uint) (map[uint][]models.Tag, error)
+	GetTags(ctx context.Context, ownerID uint) ([]payload.TagListItem, error)
+}
+
+type ReportRepo interface {
+	SaveReport(ctx context.Context, report *models.Report) error
+	CountOpenReporters(ctx context.Context, linkID uint) (int64, error)
+	GetReportQueue(ctx context.Context, limit, offset int) ([]payload.ReportQueueItem, error)
+	CountReportQueue(ctx context.Context) (int64, error)
+	ResolveReports(ctx context.Context, linkID uint, status models.ReportStatus) (int64, error)
+	MarkAutoBlocked(ctx context.Context, linkID uint) error
+	IsAutoBlocked(ctx context.Context, linkID uint) (bool, error)
+}
+
+type URLRejectionRepo interface {
+	CreateURLRejection(ctx context.Context, rejection *models.URLRejection) error
+	GetURLRejections(ctx context.Context, limit, offset int) ([]models.URLRejection, error)
+	CountURLRejections(ctx context.Context) (int64, error)
+}
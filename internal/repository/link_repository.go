@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"shorty/internal/models"
+	"shorty/internal/payload"
 	"shorty/pkg/db"
 	"shorty/pkg/logger"
 )
@@ -35,6 +38,23 @@ func (r *LinkRepository) CreateLink(ctx context.Context, link *models.Link) (*mo
 	return link, nil
 }
 
+// bulkInsertSize is the number of links inserted by one statement of CreateLinks.
+const bulkInsertSize = 500
+
+// CreateLinks stores links together with their tags in one transaction:
+// either all of them are saved or none.
+func (r *LinkRepository) CreateLinks(ctx context.Context, links []*models.Link) error {
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(links, bulkInsertSize).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create links", zap.Int("count", len(links)), zap.Error(err))
+		return fmt.Errorf("failed to save links in the database: %w", err)
+	}
+	logger.Info("Links successfully created", zap.Int("count", len(links)))
+	return nil
+}
+
 // GetLinks retrieves a paginated list of active, unblocked links.
 func (r *LinkRepository) GetLinks(ctx context.Context, limit, offset int) ([]models.Link, error) {
 	var links []models.Link
@@ -71,9 +91,51 @@ func (r *LinkRepository) GetLinkHash(ctx context.Context, hash string) (*models.
 	return &link, nil
 }
 
-// UpdateLink updates an existing link and returns the updated record.
+// linkEditableColumns are the columns written by UpdateLink. They are listed
+// explicitly so that zero values (an empty title, a cleared flag) are saved too.
+var linkEditableColumns = []string{"url", "final_url", "canonical_url", "hash", "title", "description", "always_preview", "expires_at",
+	"campaign_id", "utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"forward_query", "forward_path", "rules", "variants", "folder_id", "notes"}
+
+// FindLinkByHash retrieves a link by its hash, including blocked and expired ones.
+func (r *LinkRepository) FindLinkByHash(ctx context.Context, hash string) (*models.Link, error) {
+	var link models.Link
+	result := r.Database.DB.WithContext(ctx).Where("hash = ?", hash).First(&link)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		logger.Error("Failed to find link by hash", zap.String("hash", hash), zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return &link, nil
+}
+
+// FindOwnedLinkByCanonicalURL retrieves the newest usable link of an owner
+// with the given canonical URL: not deleted, not blocked and not expired.
+func (r *LinkRepository) FindOwnedLinkByCanonicalURL(ctx context.Context, ownerID uint, canonicalURL string) (*models.Link, error) {
+	var link models.Link
+	result := r.Database.DB.WithContext(ctx).
+		Where("user_id = ? AND canonical_url = ? AND is_blocked = false", ownerID, canonicalURL).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("id DESC").
+		First(&link)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		logger.Error("Failed to find link by canonical URL", zap.Uint("ownerID", ownerID), zap.Error(result.Error))
+		return nil, result.Error
+	}
+	return &link, nil
+}
+
+// UpdateLink updates the editable fields of a link and returns the updated record.
 func (r *LinkRepository) UpdateLink(ctx context.Context, link *models.Link) (*models.Link, error) {
-	result := r.Database.DB.WithContext(ctx).Clauses(clause.Returning{}).Updates(link)
+	result := r.Database.DB.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Select(linkEditableColumns).
+		Updates(link)
 	if result.Error != nil {
 		logger.Error("Failed to update link", zap.Uint("linkID", link.ID), zap.Error(result.Error))
 		return nil, fmt.Errorf("failed to update link in the database: %w", result.Error)
@@ -192,6 +254,34 @@ func (r *LinkRepository) GetDeletedLinksCount(ctx context.Context) (int64, error
 	return count, nil
 }
 
+// CountCodes returns the number of links, including deleted ones, whose hash
+// has the given length. Deleted links keep their hash, so it stays taken.
+func (r *LinkRepository) CountCodes(ctx context.Context, length int) (int64, error) {
+	var count int64
+	result := r.Database.DB.WithContext(ctx).
+		Model(&models.Link{}).
+		Unscoped().
+		Where("length(hash) = ?", length).
+		Count(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count link codes", zap.Int("length", length), zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to count link codes: %w", result.Error)
+	}
+	return count, nil
+}
+
+// NextCodeNumber returns the next value of the link code sequence
+// used by the sequence-based code generator.
+func (r *LinkRepository) NextCodeNumber(ctx context.Context) (uint64, error) {
+	var n uint64
+	result := r.Database.DB.WithContext(ctx).Raw("SELECT nextval('" + models.LinkCodeSequence + "')").Scan(&n)
+	if result.Error != nil {
+		logger.Error("Failed to read link code sequence", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to read link code sequence: %w", result.Error)
+	}
+	return n, nil
+}
+
 // GetTotalLinks returns the total number of links ever created (including deleted).
 func (r *LinkRepository) GetTotalLinks(ctx context.Context) (int64, error) {
 	var count int64
@@ -202,3 +292,100 @@ func (r *LinkRepository) GetTotalLinks(ctx context.Context) (int64, error) {
 	}
 	return count, nil
 }
+
+// linkListOrders maps the list sort orders to ORDER BY clauses.
+var linkListOrders = map[string]string{
+	payload.LinkSortNewest: "links.created_at DESC, links.id DESC",
+	payload.LinkSortOldest: "links.created_at ASC, links.id ASC",
+	payload.LinkSortClicks: "total_clicks DESC, links.id DESC",
+	payload.LinkSortURL:    "links.url ASC, links.id ASC",
+}
+
+// SearchLinks returns a page of links matching the filter with their click totals.
+func (r *LinkRepository) SearchLinks(ctx context.Context, filter payload.LinkListFilter, limit, offset int) ([]payload.LinkListItem, error) {
+	order, ok := linkListOrders[filter.Sort]
+	if !ok {
+		order = linkListOrders[payload.LinkSortNewest]
+	}
+	var orderBy any = order
+	if filter.Sort == payload.LinkSortRelevance && filter.Query != "" {
+		orderBy = clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(" + models.LinkSearchDocument + ", websearch_to_tsquery('simple', ?)) DESC, links.id DESC",
+			Vars: []any{filter.Query},
+		}}
+	}
+
+	var items []payload.LinkListItem
+	res := r.filterLinks(r.Database.DB.WithContext(ctx), filter).
+		Select(`
+			links.*,
+			COALESCE((SELECT SUM(stats.clicks) FROM stats
+				WHERE stats.link_id = links.id AND stats.deleted_at IS NULL), 0) AS total_clicks,
+			users.email AS owner_email
+		`).
+		Joins("LEFT JOIN users ON users.id = links.user_id").
+		Order(orderBy).
+		Limit(limit).
+		Offset(offset).
+		Scan(&items)
+	if res.Error != nil {
+		logger.Error("Failed to search links", zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to search links: %w", res.Error)
+	}
+	return items, nil
+}
+
+// CountSearchLinks returns the number of links matching the filter.
+func (r *LinkRepository) CountSearchLinks(ctx context.Context, filter payload.LinkListFilter) (int64, error) {
+	var count int64
+	res := r.filterLinks(r.Database.DB.WithContext(ctx), filter).Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count links", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count links: %w", res.Error)
+	}
+	return count, nil
+}
+
+// filterLinks applies the list filter to a query on links.
+func (r *LinkRepository) filterLinks(db *gorm.DB, filter payload.LinkListFilter) *gorm.DB {
+	query := db.Model(&models.Link{})
+	if filter.OwnerID != nil {
+		query = query.Where("links.user_id = ?", *filter.OwnerID)
+	}
+	switch {
+	case filter.Query != "" && filter.Substring:
+		// Substrings find parts of a URL or code that are not whole words.
+		// Each branch of the OR has its own index, so Postgres combines
+		// them in a bitmap scan instead of reading the whole table.
+		like := "%" + escapeLike(filter.Query) + "%"
+		query = query.Where(models.LinkSearchDocument+" @@ websearch_to_tsquery('simple', ?) OR links.url ILIKE ? OR links.hash ILIKE ? OR links.title ILIKE ?",
+			filter.Query, like, like, like)
+	case filter.Query != "":
+		query = query.Where(models.LinkSearchDocument+" @@ websearch_to_tsquery('simple', ?)", filter.Query)
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where(`EXISTS (SELECT 1 FROM link_tags JOIN tags ON tags.id = link_tags.tag_id
+			WHERE link_tags.link_id = links.id AND tags.name = ?)`, tag)
+	}
+	if filter.FolderID != nil {
+		if *filter.FolderID == 0 {
+			query = query.Where("links.folder_id IS NULL")
+		} else {
+			query = query.Where("links.folder_id = ?", *filter.FolderID)
+		}
+	}
+	switch filter.Status {
+	case payload.LinkStatusActive:
+		query = query.Where("NOT links.is_blocked AND (links.expires_at IS NULL OR links.expires_at > NOW())")
+	case payload.LinkStatusBlocked:
+		query = query.Where("links.is_blocked")
+	case payload.LinkStatusExpired:
+		query = query.Where("links.expires_at <= NOW()")
+	}
+	return query
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
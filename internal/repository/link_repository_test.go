@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/internal/payload"
+)
+
+// dryRun opens a Postgres dialect that only builds SQL and never connects.
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run database: %v", err)
+	}
+	return db
+}
+
+func TestFilterLinksSubstring(t *testing.T) {
+	r := &LinkRepository{}
+	tests := []struct {
+		name      string
+		filter    payload.LinkListFilter
+		wantFTS   bool
+		wantILIKE bool
+	}{
+		{name: "full-text only by default", filter: payload.LinkListFilter{Query: "promo"}, wantFTS: true},
+		{name: "substring opt-in", filter: payload.LinkListFilter{Query: "promo", Substring: true}, wantFTS: true, wantILIKE: true},
+		{name: "no query", filter: payload.LinkListFilter{Substring: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var links []models.Link
+			stmt := r.filterLinks(dryRun(t), tt.filter).Find(&links).Statement
+			sql := stmt.SQL.String()
+			if got := strings.Contains(sql, "websearch_to_tsquery"); got != tt.wantFTS {
+				t.Errorf("full-text match = %v, want %v in %s", got, tt.wantFTS, sql)
+			}
+			if got := strings.Contains(sql, "ILIKE"); got != tt.wantILIKE {
+				t.Errorf("ILIKE = %v, want %v in %s", got, tt.wantILIKE, sql)
+			}
+		})
+	}
+
+	// Wildcards in the query match literally.
+	var links []models.Link
+	stmt := r.filterLinks(dryRun(t), payload.LinkListFilter{Query: "50%_off", Substring: true}).Find(&links).Statement
+	want := `%50\%\_off%`
+	found := false
+	for _, v := range stmt.Vars {
+		found = found || v == want
+	}
+	if !found {
+		t.Errorf("vars = %v, want the escaped pattern %q", stmt.Vars, want)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"shorty/internal/models"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// LockoutRepository handles database operations for the LoginLockout entity.
+type LockoutRepository struct {
+	Database *db.DB
+}
+
+// NewLockoutRepository creates a new instance of LockoutRepository.
+func NewLockoutRepository(db *db.DB) *LockoutRepository {
+	return &LockoutRepository{Database: db}
+}
+
+// GetLockouts returns the records for the given scope/key pairs that exist.
+func (r *LockoutRepository) GetLockouts(ctx context.Context, scope models.LockoutScope, keys ...string) ([]models.LoginLockout, error) {
+	var lockouts []models.LoginLockout
+	res := r.Database.DB.WithContext(ctx).
+		Where("scope = ? AND key IN ?", scope, keys).
+		Find(&lockouts)
+	if res.Error != nil {
+		logger.Error("Failed to get login lockouts", zap.String("scope", string(scope)), zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to get login lockouts: %w", res.Error)
+	}
+	return lockouts, nil
+}
+
+// RegisterFailure loads (or creates) the record for scope/key under a row lock,
+// lets apply mutate it and saves the result in the same transaction.
+func (r *LockoutRepository) RegisterFailure(ctx context.Context, scope models.LockoutScope, key string, apply func(l *models.LoginLockout)) (*models.LoginLockout, error) {
+	var lockout models.LoginLockout
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Make sure the row exists so that it can be locked.
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginLockout{Scope: scope, Key: key}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND key = ?", scope, key).
+			First(&lockout).Error; err != nil {
+			return err
+		}
+		apply(&lockout)
+		return tx.Save(&lockout).Error
+	})
+	if err != nil {
+		logger.Error("Failed to register login failure", zap.String("scope", string(scope)), zap.Error(err))
+		return nil, fmt.Errorf("failed to register login failure: %w", err)
+	}
+	return &lockout, nil
+}
+
+// ResetLockout removes the record for scope/key.
+func (r *LockoutRepository) ResetLockout(ctx context.Context, scope models.LockoutScope, key string) error {
+	res := r.Database.DB.WithContext(ctx).
+		Where("scope = ? AND key = ?", scope, key).
+		Delete(&models.LoginLockout{})
+	if res.Error != nil {
+		logger.Error("Failed to reset login lockout", zap.String("scope", string(scope)), zap.Error(res.Error))
+		return fmt.Errorf("failed to reset login lockout: %w", res.Error)
+	}
+	return nil
+}
+
+// GetActiveLockouts returns a page of lockouts that are active at the given moment.
+func (r *LockoutRepository) GetActiveLockouts(ctx context.Context, now time.Time, limit, offset int) ([]models.LoginLockout, error) {
+	var lockouts []models.LoginLockout
+	res := r.Database.DB.WithContext(ctx).
+		Where("locked_until > ?", now).
+		Order("locked_until DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&lockouts)
+	if res.Error != nil {
+		logger.Error("Failed to get active login lockouts", zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to get active login lockouts: %w", res.Error)
+	}
+	return lockouts, nil
+}
+
+// CountActiveLockouts returns the number of lockouts active at the given moment.
+func (r *LockoutRepository) CountActiveLockouts(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.LoginLockout{}).
+		Where("locked_until > ?", now).
+		Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count active login lockouts", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count active login lockouts: %w", res.Error)
+	}
+	return count, nil
+}
+
+// DeleteLockout removes a lockout record by its ID.
+func (r *LockoutRepository) DeleteLockout(ctx context.Context, id uint) error {
+	res := r.Database.DB.WithContext(ctx).Delete(&models.LoginLockout{}, id)
+	if res.Error != nil {
+		logger.Error("Failed to delete login lockout", zap.Uint("id", id), zap.Error(res.Error))
+		return fmt.Errorf("failed to delete login lockout: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		logger.Warn("Login lockout not found for deletion", zap.Uint("id", id))
+		return gorm.ErrRecordNotFound
+	}
+	logger.Info("Login lockout cleared", zap.Uint("id", id))
+	return nil
+}
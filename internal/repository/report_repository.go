@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// ReportRepository handles database operations for the Report entity.
+type ReportRepository struct {
+	Database *db.DB
+}
+
+// NewReportRepository creates a new instance of ReportRepository.
+func NewReportRepository(db *db.DB) *ReportRepository {
+	return &ReportRepository{Database: db}
+}
+
+// SaveReport stores an open report. A repeated report of the same link by
+// the same reporter replaces the previous open one instead of adding a new row.
+func (r *ReportRepository) SaveReport(ctx context.Context, report *models.Report) error {
+	err := r.Database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.Report
+		err := tx.Where("link_id = ? AND reporter_key = ? AND status = ?", report.LinkID, report.ReporterKey, models.ReportStatusOpen).
+			First(&existing).Error
+		if err == nil {
+			report.ID = existing.ID
+			return tx.Model(&existing).Updates(map[string]interface{}{
+				"reason":  report.Reason,
+				"comment": report.Comment,
+			}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Create(report).Error
+	})
+	if err != nil {
+		logger.Error("Failed to save report", zap.Uint("linkID", report.LinkID), zap.Error(err))
+		return fmt.Errorf("failed to save report: %w", err)
+	}
+	return nil
+}
+
+// CountOpenReporters returns the number of distinct reporters with open reports for a link.
+func (r *ReportRepository) CountOpenReporters(ctx context.Context, linkID uint) (int64, error) {
+	var count int64
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportStatusOpen).
+		Distinct("reporter_key").
+		Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count reporters", zap.Uint("linkID", linkID), zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count reporters: %w", res.Error)
+	}
+	return count, nil
+}
+
+// GetReportQueue returns open reports grouped by link, most reported first.
+func (r *ReportRepository) GetReportQueue(ctx context.Context, limit, offset int) ([]payload.ReportQueueItem, error) {
+	var items []payload.ReportQueueItem
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Select(`
+			reports.link_id AS link_id,
+			links.hash AS hash,
+			links.url AS url,
+			links.user_id AS owner_id,
+			links.is_blocked AS is_blocked,
+			BOOL_OR(reports.auto_blocked) AS auto_blocked,
+			COUNT(reports.id) AS reports,
+			COUNT(DISTINCT reports.reporter_key) AS reporters,
+			STRING_AGG(DISTINCT reports.reason, ',') AS reasons,
+			MAX(reports.created_at) AS last_reported_at
+		`).
+		Joins("JOIN links ON links.id = reports.link_id").
+		Where("reports.status = ?", models.ReportStatusOpen).
+		Group("reports.link_id, links.hash, links.url, links.user_id, links.is_blocked").
+		Order("reporters DESC, last_reported_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&items)
+	if res.Error != nil {
+		logger.Error("Failed to get report queue", zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to get report queue: %w", res.Error)
+	}
+	return items, nil
+}
+
+// CountReportQueue returns the number of links with open reports.
+func (r *ReportRepository) CountReportQueue(ctx context.Context) (int64, error) {
+	var count int64
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Where("status = ?", models.ReportStatusOpen).
+		Distinct("link_id").
+		Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count report queue", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count report queue: %w", res.Error)
+	}
+	return count, nil
+}
+
+// ResolveReports moves all open reports of a link to the given status.
+// It returns the number of reports that were resolved.
+func (r *ReportRepository) ResolveReports(ctx context.Context, linkID uint, status models.ReportStatus) (int64, error) {
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportStatusOpen).
+		Update("status", status)
+	if res.Error != nil {
+		logger.Error("Failed to resolve reports", zap.Uint("linkID", linkID), zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to resolve reports: %w", res.Error)
+	}
+	logger.Info("Reports resolved", zap.Uint("linkID", linkID), zap.String("status", string(status)), zap.Int64("count", res.RowsAffected))
+	return res.RowsAffected, nil
+}
+
+// MarkAutoBlocked flags the open reports of a link as the reason it was blocked
+// automatically. The reports stay open until a moderator reviews them.
+func (r *ReportRepository) MarkAutoBlocked(ctx context.Context, linkID uint) error {
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Where("link_id = ? AND status = ?", linkID, models.ReportStatusOpen).
+		Update("auto_blocked", true)
+	if res.Error != nil {
+		logger.Error("Failed to mark reports as auto-blocked", zap.Uint("linkID", linkID), zap.Error(res.Error))
+		return fmt.Errorf("failed to mark reports as auto-blocked: %w", res.Error)
+	}
+	return nil
+}
+
+// IsAutoBlocked reports whether the link has open reports that blocked it automatically.
+func (r *ReportRepository) IsAutoBlocked(ctx context.Context, linkID uint) (bool, error) {
+	var count int64
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Report{}).
+		Where("link_id = ? AND status = ? AND auto_blocked", linkID, models.ReportStatusOpen).
+		Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to check auto-blocked reports", zap.Uint("linkID", linkID), zap.Error(res.Error))
+		return false, fmt.Errorf("failed to check auto-blocked reports: %w", res.Error)
+	}
+	return count > 0, nil
+}
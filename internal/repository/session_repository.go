@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/models"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// SessionRepository handles database operations for the Session entity.
+type SessionRepository struct {
+	Database *db.DB
+}
+
+// NewSessionRepository creates a new instance of SessionRepository.
+func NewSessionRepository(db *db.DB) *SessionRepository {
+	return &SessionRepository{Database: db}
+}
+
+// CreateSession stores a new session.
+func (r *SessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	res := r.Database.DB.WithContext(ctx).Create(session)
+	if res.Error != nil {
+		logger.Error("Failed to create session", zap.Uint("userID", session.UserID), zap.Error(res.Error))
+		return fmt.Errorf("failed to create session: %w", res.Error)
+	}
+	return nil
+}
+
+// GetSession finds a session by its ID. Returns gorm.ErrRecordNotFound if there is none.
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	res := r.Database.DB.WithContext(ctx).Where("id = ?", id).First(&session)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return &session, nil
+}
+
+// TouchSession updates the last activity time of a session.
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, seenAt time.Time) error {
+	res := r.Database.DB.WithContext(ctx).
+		Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("last_seen_at", seenAt)
+	if res.Error != nil {
+		logger.Error("Failed to touch session", zap.Error(res.Error))
+		return fmt.Errorf("failed to touch session: %w", res.Error)
+	}
+	return nil
+}
+
+// DeleteSession removes a session by its ID.
+func (r *SessionRepository) DeleteSession(ctx context.Context, id string) error {
+	res := r.Database.DB.WithContext(ctx).Where("id = ?", id).Delete(&models.Session{})
+	if res.Error != nil {
+		logger.Error("Failed to delete session", zap.Error(res.Error))
+		return fmt.Errorf("failed to delete session: %w", res.Error)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes sessions that passed their absolute expiry
+// or have been idle since before idleSince.
+func (r *SessionRepository) DeleteExpiredSessions(ctx context.Context, now, idleSince time.Time) (int64, error) {
+	res := r.Database.DB.WithContext(ctx).
+		Where("expires_at <= ? OR last_seen_at <= ?", now, idleSince).
+		Delete(&models.Session{})
+	if res.Error != nil {
+		logger.Error("Failed to delete expired sessions", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
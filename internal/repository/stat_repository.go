@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
@@ -78,6 +80,80 @@ func (r *StatRepository) AddClick(ctx context.Context, linkID uint) error {
 	return tx.Commit().Error
 }
 
+// SaveClick метод для сохранения записи об одном переходе по ссылке.
+func (r *StatRepository) SaveClick(ctx context.Context, click *models.Click) error {
+	if err := r.Database.DB.WithContext(ctx).Create(click).Error; err != nil {
+		logger.Error("Ошибка при сохранении перехода", zap.Uint("linkID", click.LinkID), zap.Error(err))
+		return fmt.Errorf("failed to save click: %w", err)
+	}
+	return nil
+}
+
+// FindClick метод для поиска перехода по его ID для атрибуции конверсий.
+func (r *StatRepository) FindClick(ctx context.Context, clickID string) (*models.Click, error) {
+	var click models.Click
+	result := r.Database.DB.WithContext(ctx).Where("click_id = ?", clickID).First(&click)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, gorm.ErrRecordNotFound
+		}
+		logger.Error("Ошибка при поиске перехода", zap.String("clickID", clickID), zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to find click: %w", result.Error)
+	}
+	return &click, nil
+}
+
+// SaveConversion метод для сохранения конверсии по ссылке.
+func (r *StatRepository) SaveConversion(ctx context.Context, conversion *models.Conversion) error {
+	if err := r.Database.DB.WithContext(ctx).Create(conversion).Error; err != nil {
+		logger.Error("Ошибка при сохранении конверсии", zap.Uint("linkID", conversion.LinkID), zap.Error(err))
+		return fmt.Errorf("failed to save conversion: %w", err)
+	}
+	return nil
+}
+
+// ClickConverted метод для проверки, есть ли уже конверсия по переходу.
+func (r *StatRepository) ClickConverted(ctx context.Context, clickID string) (bool, error) {
+	var count int64
+	result := r.Database.DB.
+		WithContext(ctx).
+		Model(&models.Conversion{}).
+		Where("click_id = ?", clickID).
+		Count(&count)
+	if result.Error != nil {
+		logger.Error("Ошибка при проверке конверсии перехода", zap.String("clickID", clickID), zap.Error(result.Error))
+		return false, fmt.Errorf("failed to check conversion: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// CountVariantClicks метод для подсчёта переходов по ссылке в разбивке по вариантам A/B-теста.
+func (r *StatRepository) CountVariantClicks(ctx context.Context, linkID uint) ([]payload.VariantCount, error) {
+	return r.countByVariant(ctx, &models.Click{}, linkID)
+}
+
+// CountVariantConversions метод для подсчёта конверсий по ссылке в разбивке по вариантам A/B-теста.
+func (r *StatRepository) CountVariantConversions(ctx context.Context, linkID uint) ([]payload.VariantCount, error) {
+	return r.countByVariant(ctx, &models.Conversion{}, linkID)
+}
+
+// countByVariant считает записи модели по ссылке, сгруппированные по варианту.
+func (r *StatRepository) countByVariant(ctx context.Context, model any, linkID uint) ([]payload.VariantCount, error) {
+	var counts []payload.VariantCount
+	result := r.Database.DB.
+		WithContext(ctx).
+		Model(model).
+		Select("variant, COUNT(*) AS count").
+		Where("link_id = ? AND variant <> ''", linkID).
+		Group("variant").
+		Scan(&counts)
+	if result.Error != nil {
+		logger.Error("Ошибка при подсчёте по вариантам", zap.Uint("linkID", linkID), zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to count by variant: %w", result.Error)
+	}
+	return counts, nil
+}
+
 // GetClickedLinkStats метод для получения статистики по дням или месяцам за заданный период.
 func (r *StatRepository) GetClickedLinkStats(ctx context.Context, by string, from, to time.Time) []payload.GetStatsResponse {
 	var stats []payload.GetStatsResponse
@@ -107,9 +183,10 @@ func (r *StatRepository) GetClickedLinkStats(ctx context.Context, by string, fro
 	return stats
 }
 
-func (r *StatRepository) GetAllLinksStats(ctx context.Context, from, to time.Time) []payload.LinkStatsResponse {
+// GetAllLinksStats метод для получения статистики по ссылкам за период, при необходимости только по одной кампании.
+func (r *StatRepository) GetAllLinksStats(ctx context.Context, filter payload.LinkStatsFilter) []payload.LinkStatsResponse {
 	var stats []payload.LinkStatsResponse
-	r.Database.DB.
+	query := r.Database.DB.
 		Model(&models.Stat{}).
 		WithContext(ctx).
 		Select(`
@@ -117,13 +194,75 @@ func (r *StatRepository) GetAllLinksStats(ctx context.Context, from, to time.Tim
 			links.url AS url,
 			COUNT(stats.id) AS total_clicks,
 			MAX(stats.date) AS last_click_date,
-			SUM(CASE WHEN links.is_blocked = true THEN 1 ELSE 0 END) AS blocked_count
-		`).
+			SUM(CASE WHEN links.is_blocked = true THEN 1 ELSE 0 END) AS blocked_count,
+			(SELECT COUNT(*) FROM conversions
+				WHERE conversions.link_id = links.id
+				AND conversions.created_at >= ? AND conversions.created_at < ?) AS conversions,
+			(SELECT COUNT(*) FROM clicks
+				WHERE clicks.link_id = links.id AND clicks.source = ?
+				AND clicks.created_at >= ? AND clicks.created_at < ?) AS scans
+		`, filter.From, filter.To.AddDate(0, 0, 1), models.ClickSourceQR, filter.From, filter.To.AddDate(0, 0, 1)).
 		Joins("LEFT JOIN links ON stats.link_id = links.id").
-		Where("stats.date BETWEEN ? AND ?", from, to).
+		Where("stats.date BETWEEN ? AND ?", filter.From, filter.To)
+	if filter.CampaignID != nil {
+		query = query.Where("links.campaign_id = ?", *filter.CampaignID)
+	}
+	query.
 		Group("links.id, links.url").
 		Order("total_clicks DESC").
 		Scan(&stats)
 	logger.Info("Статистика по всем ссылкам получена", zap.Int("count", len(stats)))
 	return stats
 }
+
+// GetCampaignStats метод для получения сводной статистики кампаний за период:
+// число ссылок, сумма кликов и дата последнего клика. Без ownerID - по всем владельцам.
+func (r *StatRepository) GetCampaignStats(ctx context.Context, ownerID *uint, from, to time.Time) ([]payload.CampaignStatsResponse, error) {
+	var stats []payload.CampaignStatsResponse
+	query := r.Database.DB.
+		WithContext(ctx).
+		Model(&models.Campaign{}).
+		Select(`
+			campaigns.id AS campaign_id,
+			campaigns.name AS name,
+			campaigns.user_id AS user_id,
+			COUNT(DISTINCT links.id) AS links,
+			COALESCE(SUM(stats.clicks), 0) AS total_clicks,
+			MAX(stats.date) AS last_click_date
+		`).
+		Joins("LEFT JOIN links ON links.campaign_id = campaigns.id AND links.deleted_at IS NULL").
+		Joins("LEFT JOIN stats ON stats.link_id = links.id AND stats.date BETWEEN ? AND ?", from, to)
+	if ownerID != nil {
+		query = query.Where("campaigns.user_id = ?", *ownerID)
+	}
+	result := query.
+		Group("campaigns.id, campaigns.name, campaigns.user_id").
+		Order("total_clicks DESC, campaigns.name ASC").
+		Scan(&stats)
+	if result.Error != nil {
+		logger.Error("Ошибка при получении статистики кампаний", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to get campaign stats: %w", result.Error)
+	}
+	return stats, nil
+}
+
+// GetDailyClicks метод для получения кликов по дням для набора ссылок за период.
+func (r *StatRepository) GetDailyClicks(ctx context.Context, linkIDs []uint, from, to time.Time) ([]payload.DailyClicks, error) {
+	var clicks []payload.DailyClicks
+	if len(linkIDs) == 0 {
+		return clicks, nil
+	}
+	result := r.Database.DB.
+		WithContext(ctx).
+		Model(&models.Stat{}).
+		Select("link_id, date, SUM(clicks) AS clicks").
+		Where("link_id IN ? AND date BETWEEN ? AND ?", linkIDs, from, to).
+		Group("link_id, date").
+		Order("date").
+		Scan(&clicks)
+	if result.Error != nil {
+		logger.Error("Ошибка при получении кликов по дням", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to get daily clicks: %w", result.Error)
+	}
+	return clicks, nil
+}
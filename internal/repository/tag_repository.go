@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// TagRepository handles database operations for the Tag entity.
+type TagRepository struct {
+	Database *db.DB
+}
+
+// NewTagRepository creates a new instance of TagRepository.
+func NewTagRepository(db *db.DB) *TagRepository {
+	return &TagRepository{Database: db}
+}
+
+// EnsureTags returns the tags of an owner with the given names, creating
+// the missing ones.
+func (r *TagRepository) EnsureTags(ctx context.Context, ownerID uint, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return []models.Tag{}, nil
+	}
+	tags := make([]models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = models.Tag{UserID: ownerID, Name: name}
+	}
+	db := r.Database.DB.WithContext(ctx)
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		logger.Error("Failed to create tags", zap.Uint("userID", ownerID), zap.Error(err))
+		return nil, fmt.Errorf("failed to create tags: %w", err)
+	}
+	// Tags that already existed got no ID from the insert.
+	tags = tags[:0]
+	if err := db.Where("user_id = ? AND name IN ?", ownerID, names).Order("name ASC").Find(&tags).Error; err != nil {
+		logger.Error("Failed to retrieve tags", zap.Uint("userID", ownerID), zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve tags: %w", err)
+	}
+	return tags, nil
+}
+
+// ReplaceLinkTags sets the tags of a link, dropping the ones not in tags.
+func (r *TagRepository) ReplaceLinkTags(ctx context.Context, link *models.Link, tags []models.Tag) error {
+	if err := r.Database.DB.WithContext(ctx).Model(link).Association("Tags").Replace(tags); err != nil {
+		logger.Error("Failed to replace link tags", zap.Uint("linkID", link.ID), zap.Error(err))
+		return fmt.Errorf("failed to replace link tags: %w", err)
+	}
+	link.Tags = tags
+	return nil
+}
+
+// GetLinkTags returns the tags of each of the links ordered by name.
+func (r *TagRepository) GetLinkTags(ctx context.Context, linkIDs []uint) (map[uint][]models.Tag, error) {
+	var rows []struct {
+		LinkID uint
+		models.Tag
+	}
+	tags := make(map[uint][]models.Tag, len(linkIDs))
+	if len(linkIDs) == 0 {
+		return tags, nil
+	}
+	result := r.Database.DB.WithContext(ctx).
+		Table("tags").
+		Select("link_tags.link_id, tags.*").
+		Joins("JOIN link_tags ON link_tags.tag_id = tags.id").
+		Where("link_tags.link_id IN ?", linkIDs).
+		Order("tags.name ASC").
+		Scan(&rows)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve link tags", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to retrieve link tags: %w", result.Error)
+	}
+	for _, row := range rows {
+		tags[row.LinkID] = append(tags[row.LinkID], row.Tag)
+	}
+	return tags, nil
+}
+
+// GetTags returns the tags of an owner with the number of their links, ordered by name.
+func (r *TagRepository) GetTags(ctx context.Context, ownerID uint) ([]payload.TagListItem, error) {
+	var tags []payload.TagListItem
+	result := r.Database.DB.WithContext(ctx).
+		Model(&models.Tag{}).
+		Select("tags.id, tags.name, COUNT(links.id) AS links").
+		Joins("LEFT JOIN link_tags ON link_tags.tag_id = tags.id").
+		Joins("LEFT JOIN links ON links.id = link_tags.link_id AND links.deleted_at IS NULL").
+		Where("tags.user_id = ?", ownerID).
+		Group("tags.id, tags.name").
+		Order("tags.name ASC").
+		Scan(&tags)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve tags", zap.Uint("userID", ownerID), zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to retrieve tags: %w", result.Error)
+	}
+	return tags, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/models"
+	"shorty/pkg/db"
+	"shorty/pkg/logger"
+)
+
+// URLRejectionRepository handles database operations for the URLRejection entity.
+type URLRejectionRepository struct {
+	Database *db.DB
+}
+
+// NewURLRejectionRepository creates a new instance of URLRejectionRepository.
+func NewURLRejectionRepository(db *db.DB) *URLRejectionRepository {
+	return &URLRejectionRepository{Database: db}
+}
+
+// CreateURLRejection stores a rejected URL.
+func (r *URLRejectionRepository) CreateURLRejection(ctx context.Context, rejection *models.URLRejection) error {
+	if err := r.Database.DB.WithContext(ctx).Create(rejection).Error; err != nil {
+		logger.Error("Failed to save URL rejection", zap.String("url", rejection.URL), zap.Error(err))
+		return fmt.Errorf("failed to save URL rejection: %w", err)
+	}
+	return nil
+}
+
+// GetURLRejections returns rejected URLs, newest first.
+func (r *URLRejectionRepository) GetURLRejections(ctx context.Context, limit, offset int) ([]models.URLRejection, error) {
+	var rejections []models.URLRejection
+	res := r.Database.DB.WithContext(ctx).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&rejections)
+	if res.Error != nil {
+		logger.Error("Failed to get URL rejections", zap.Error(res.Error))
+		return nil, fmt.Errorf("failed to get URL rejections: %w", res.Error)
+	}
+	return rejections, nil
+}
+
+// CountURLRejections returns the number of rejected URLs.
+func (r *URLRejectionRepository) CountURLRejections(ctx context.Context) (int64, error) {
+	var count int64
+	res := r.Database.DB.WithContext(ctx).Model(&models.URLRejection{}).Count(&count)
+	if res.Error != nil {
+		logger.Error("Failed to count URL rejections", zap.Error(res.Error))
+		return 0, fmt.Errorf("failed to count URL rejections: %w", res.Error)
+	}
+	return count, nil
+}
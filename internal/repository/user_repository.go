@@ -108,7 +108,7 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 
 	if res.Error != nil {
 		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
-			logger.Warn("User not found by email", zap.String("email", email))
+			logger.Debug("User not found by email")
 			return nil, gorm.ErrRecordNotFound // Return original GORM error
 		}
 		logger.Error("Failed to find user by email",
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+// Audit actions
+const (
+	AuditUserUpdate  = "user.update"
+	AuditUserDelete  = "user.delete"
+	AuditUserBlock   = "user.block"
+	AuditUserUnblock = "user.unblock"
+	AuditLinkDelete  = "link.delete"
+	AuditLinkBlock   = "link.block"
+	AuditLinkUnblock = "link.unblock"
+
+	AuditURLPolicyAdd    = "url_policy.add"
+	AuditURLPolicyRemove = "url_policy.remove"
+)
+
+// auditHiddenFields are never written to the audit log.
+var auditHiddenFields = map[string]bool{"password": true}
+
+// AuditService writes and reads the administrative audit log.
+type AuditService struct {
+	Repo repository.AuditRepo
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(repo repository.AuditRepo) *AuditService {
+	return &AuditService{Repo: repo}
+}
+
+// Record appends an action to the audit log. The actor is the administrator
+// stored in ctx by the admin middleware; without one nothing is recorded,
+// so the same service methods called on behalf of regular users are not audited.
+// before and after are snapshots of the target, either of them may be nil.
+func (s *AuditService) Record(ctx context.Context, action, targetType string, targetID uint, before, after any) {
+	actor, ok := ctx.Value(common.UserContextKey).(*models.User)
+	if !ok || actor == nil {
+		return
+	}
+
+	diff, err := auditDiff(before, after)
+	if err != nil {
+		logger.Error("Failed to build audit diff", zap.String("action", action), zap.Error(err))
+	}
+
+	entry := &models.AuditLog{
+		ActorID:    actor.ID,
+		ActorEmail: actor.Email,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Diff:       diff,
+		IP:         common.ClientIPFromContext(ctx),
+		RequestID:  logger.FromContext(ctx),
+	}
+	// The action has already happened, so a failed write is only logged.
+	if err := s.Repo.CreateAuditLog(context.WithoutCancel(ctx), entry); err != nil {
+		logger.Error("Audit record lost",
+			zap.String("action", action),
+			zap.Uint("actorID", actor.ID),
+			zap.Uint("targetID", targetID),
+			zap.Error(err))
+	}
+}
+
+// GetAll returns a page of audit log records matching the filter.
+func (s *AuditService) GetAll(ctx context.Context, filter payload.AuditLogFilter, limit, offset int) ([]models.AuditLog, error) {
+	entries, err := s.Repo.GetAuditLogs(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Count returns the number of audit log records matching the filter.
+func (s *AuditService) Count(ctx context.Context, filter payload.AuditLogFilter) (int64, error) {
+	return s.Repo.CountAuditLogs(ctx, filter)
+}
+
+// auditDiff returns the fields that differ between two snapshots.
+func auditDiff(before, after any) (datatypes.JSON, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]map[string]any)
+	for key, bv := range b {
+		if av, ok := a[key]; !ok || !reflect.DeepEqual(av, bv) {
+			diff[key] = map[string]any{"before": bv, "after": a[key]}
+		}
+	}
+	for key, av := range a {
+		if _, ok := b[key]; !ok {
+			diff[key] = map[string]any{"before": nil, "after": av}
+		}
+	}
+	return json.Marshal(diff)
+}
+
+// auditFields turns a snapshot into its JSON fields without hidden ones.
+func auditFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if v == nil {
+		return fields, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return fields, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for key := range auditHiddenFields {
+		delete(fields, key)
+	}
+	return fields, nil
+}
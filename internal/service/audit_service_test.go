@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/repository"
+)
+
+// fakeAuditRepo collects audit entries in memory.
+type fakeAuditRepo struct {
+	entries []*models.AuditLog
+}
+
+func (r *fakeAuditRepo) CreateAuditLog(_ context.Context, entry *models.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeAuditRepo) GetAuditLogs(context.Context, payload.AuditLogFilter, int, int) ([]models.AuditLog, error) {
+	return nil, nil
+}
+
+func (r *fakeAuditRepo) CountAuditLogs(context.Context, payload.AuditLogFilter) (int64, error) {
+	return 0, nil
+}
+
+// fakeBlockUserRepo serves one user and stores its blocked state.
+type fakeBlockUserRepo struct {
+	repository.UserRepo
+	user *models.User
+}
+
+func (r *fakeBlockUserRepo) GetUserByID(context.Context, uint) (*models.User, error) {
+	user := *r.user
+	return &user, nil
+}
+
+func (r *fakeBlockUserRepo) BlockUsers(_ context.Context, user *models.User) (*models.User, error) {
+	r.user = user
+	return user, nil
+}
+
+// fakeBlockLinkRepo serves one link and stores its blocked state.
+type fakeBlockLinkRepo struct {
+	repository.LinkRepo
+	link *models.Link
+}
+
+func (r *fakeBlockLinkRepo) FindLinkByID(context.Context, uint) (*models.Link, error) {
+	link := *r.link
+	return &link, nil
+}
+
+func (r *fakeBlockLinkRepo) BlockLink(_ context.Context, link *models.Link) (*models.Link, error) {
+	r.link = link
+	return link, nil
+}
+
+func TestAuditRecordsAdminActions(t *testing.T) {
+	admin := &models.User{ID: 1, Email: "admin@example.com", Role: models.RoleAdmin}
+	user := &models.User{ID: 5, Email: "user@example.com"}
+	link := &models.Link{Hash: "abc"}
+	link.ID = 9
+
+	tests := []struct {
+		name       string
+		do         func(ctx context.Context, audit AuditServ) error
+		action     string
+		targetType string
+		targetID   uint
+	}{
+		{
+			name: "block user",
+			do: func(ctx context.Context, audit AuditServ) error {
+				s := NewUserService(&fakeBlockUserRepo{user: user}, audit)
+				_, err := s.Block(ctx, user.ID)
+				return err
+			},
+			action: AuditUserBlock, targetType: models.AuditTargetUser, targetID: 5,
+		},
+		{
+			name: "block link",
+			do: func(ctx context.Context, audit AuditServ) error {
+				s := &LinkService{Repo: &fakeBlockLinkRepo{link: link}, Audit: audit}
+				_, err := s.Block(ctx, link.ID)
+				return err
+			},
+			action: AuditLinkBlock, targetType: models.AuditTargetLink, targetID: 9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAuditRepo{}
+			ctx := context.WithValue(context.Background(), common.UserContextKey, admin)
+			ctx = common.WithClientIP(ctx, "203.0.113.7")
+			if err := tt.do(ctx, NewAuditService(repo)); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(repo.entries))
+			}
+			e := repo.entries[0]
+			if e.ActorID != admin.ID || e.ActorEmail != admin.Email {
+				t.Errorf("actor = %d %q, want %d %q", e.ActorID, e.ActorEmail, admin.ID, admin.Email)
+			}
+			if e.Action != tt.action || e.TargetType != tt.targetType || e.TargetID != tt.targetID {
+				t.Errorf("entry = %s %s/%d, want %s %s/%d", e.Action, e.TargetType, e.TargetID, tt.action, tt.targetType, tt.targetID)
+			}
+			if e.IP != "203.0.113.7" {
+				t.Errorf("IP = %q", e.IP)
+			}
+			var diff map[string]map[string]any
+			if err := json.Unmarshal(e.Diff, &diff); err != nil {
+				t.Fatal(err)
+			}
+			if len(diff) != 1 || diff["is_blocked"]["before"] != false || diff["is_blocked"]["after"] != true {
+				t.Errorf("diff = %s, want only is_blocked false -> true", e.Diff)
+			}
+
+			// The same call without an administrator in the context is not audited.
+			repo.entries = nil
+			if err := tt.do(context.Background(), NewAuditService(repo)); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.entries) != 0 {
+				t.Errorf("entries without an admin = %d, want 0", len(repo.entries))
+			}
+		})
+	}
+}
+
+func TestAuditDiffHidesPassword(t *testing.T) {
+	before := map[string]any{"name": "a", "password": "old"}
+	after := map[string]any{"name": "b", "password": "new"}
+	raw, err := auditDiff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diff map[string]any
+	if err := json.Unmarshal(raw, &diff); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := diff["password"]; ok || len(diff) != 1 {
+		t.Errorf("diff = %s, want only name", raw)
+	}
+}
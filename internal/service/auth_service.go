@@ -4,19 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"shorty/internal/common"
 	"shorty/internal/models"
 	"shorty/internal/repository"
 	"shorty/pkg/logger"
 )
 
 var (
-	ErrAuthCreation        = errors.New("failed to create user")
-	ErrAuthNotFound        = errors.New("user not found")
-	ErrAuthWrongCredential = errors.New("invalid email or password")
+	ErrAuthCreation        = common.ErrUserRegistrationFailed
+	ErrAuthNotFound        = common.ErrAuthFailed
+	ErrAuthWrongCredential = common.ErrInvalidCredentials
+	ErrAuthEmailTaken      = common.ErrEmailTaken
 )
 
 // AuthService provides methods for user authentication and registration.
@@ -39,14 +42,14 @@ func (s *AuthService) Registration(ctx context.Context, name, email, password st
 	}
 	if exists != nil {
 		logger.Warn("User with this email is already registered", zap.String("email", email))
-		return nil, fmt.Errorf("user with email %s is already registered", email)
+		return nil, ErrAuthEmailTaken
 	}
 
 	// Hash the user's password
 	hashedPassword, err := models.Hash(password)
 	if err != nil {
 		logger.Error("Error hashing password", zap.Error(err))
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, fmt.Errorf("%w: failed to hash password: %v", ErrAuthCreation, err)
 	}
 
 	// Create a new user model
@@ -68,25 +71,32 @@ func (s *AuthService) Registration(ctx context.Context, name, email, password st
 	return user, nil
 }
 
+// dummyPasswordHash is compared against when the email is unknown, so that
+// a missing account takes as long to reject as a wrong password.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := models.Hash("shorty-dummy-password")
+	return string(hash)
+})
+
 // Login authenticates an existing user.
+// Unknown email and wrong password both result in ErrAuthWrongCredential.
 func (s *AuthService) Login(ctx context.Context, email, password string, role models.Role) (*models.User, error) {
 	// Attempt to find user by email
 	exists, err := s.Repo.GetUserByEmail(ctx, email)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			logger.Warn("Invalid login credentials", zap.String("email", email))
-			return nil, ErrAuthWrongCredential
-		}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		logger.Error("Error retrieving user", zap.Error(err))
-		return nil, fmt.Errorf("error retrieving user: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrAuthNotFound, err)
 	}
 
 	// Verify provided password against the stored hash
-	err = models.VerifyPassword(exists.Password, password)
-	if err != nil {
-		logger.Error("Password verification failed", zap.Error(err))
+	hashedPassword := dummyPasswordHash()
+	if exists != nil {
+		hashedPassword = exists.Password
+	}
+	if err := models.VerifyPassword(hashedPassword, password); err != nil || exists == nil {
+		logger.Warn("Invalid login credentials")
 		return nil, ErrAuthWrongCredential
 	}
-	logger.Info("User successfully logged in", zap.String("email", email))
+	logger.Info("User successfully logged in", zap.Uint("userID", exists.ID))
 	return exists, nil
 }
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/pkg/logger"
+)
+
+var (
+	ErrBulkEmpty       = common.ErrBulkEmpty
+	ErrBulkRolledBack  = common.ErrBulkRolledBack
+	ErrBulkJobNotFound = common.ErrBulkJobNotFound
+	ErrBulkTooManyJobs = common.ErrBulkTooManyJobs
+)
+
+// BulkServiceDeps - зависимости для создания экземпляра BulkService.
+type BulkServiceDeps struct {
+	Links    LinkServ
+	SyncRows int           // запрос с большим числом строк выполняется в фоне
+	JobTTL   time.Duration // сколько хранится завершённая задача
+	MaxJobs  int           // незавершённых фоновых задач одного владельца, 0 - без ограничения
+}
+
+// BulkService создаёт ссылки пачками. Каждая строка проверяется так же, как
+// при создании одной ссылки. В режиме partial создаются все правильные строки,
+// в режиме atomic - все строки одной транзакцией или ни одной.
+// Задачи хранятся в памяти процесса и теряются при перезапуске.
+type BulkService struct {
+	Links    LinkServ
+	SyncRows int
+	JobTTL   time.Duration
+	MaxJobs  int
+
+	mu   sync.Mutex
+	jobs map[string]*payload.BulkJob
+}
+
+// NewBulkService создаёт новый экземпляр BulkService.
+func NewBulkService(deps *BulkServiceDeps) *BulkService {
+	return &BulkService{
+		Links:    deps.Links,
+		SyncRows: deps.SyncRows,
+		JobTTL:   deps.JobTTL,
+		MaxJobs:  deps.MaxJobs,
+		jobs:     make(map[string]*payload.BulkJob),
+	}
+}
+
+// Start создаёт ссылки владельца из строк запроса. Запрос не больше SyncRows
+// строк без async выполняется сразу, и возвращается завершённая задача без ID.
+// Иначе задача выполняется в фоне и возвращается в статусе pending,
+// её состояние читается через Job. Если у владельца уже MaxJobs незавершённых
+// фоновых задач, новая не запускается: ErrBulkTooManyJobs.
+func (s *BulkService) Start(ctx context.Context, ownerID uint, mode string, rows []payload.BulkLinkRow, async bool) (*payload.BulkJob, error) {
+	if len(rows) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	job := &payload.BulkJob{
+		OwnerID:   ownerID,
+		Mode:      mode,
+		Status:    payload.BulkJobPending,
+		Total:     len(rows),
+		CreatedAt: time.Now(),
+	}
+	if !async && len(rows) <= s.SyncRows {
+		s.run(ctx, job, rows)
+		return job, nil
+	}
+
+	job.ID = newJobID()
+	s.mu.Lock()
+	s.prune(job.CreatedAt)
+	if s.MaxJobs > 0 && s.running(ownerID) >= s.MaxJobs {
+		s.mu.Unlock()
+		return nil, ErrBulkTooManyJobs
+	}
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	// Задача переживает запрос, но сохраняет его значения: язык, ID запроса.
+	go s.run(context.WithoutCancel(ctx), job, rows)
+	logger.Info("Запущена фоновая задача создания ссылок", zap.String("job", job.ID), zap.Uint("user_id", ownerID), zap.Int("rows", len(rows)))
+	return &snapshot, nil
+}
+
+// Job возвращает снимок задачи владельца. Чужая задача не отличается от несуществующей.
+func (s *BulkService) Job(ownerID uint, id string) (*payload.BulkJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	job, ok := s.jobs[id]
+	if !ok || job.OwnerID != ownerID {
+		return nil, ErrBulkJobNotFound
+	}
+	snapshot := *job
+	snapshot.Results = slices.Clone(job.Results)
+	return &snapshot, nil
+}
+
+// running возвращает число незавершённых задач владельца. Вызывается под mu.
+func (s *BulkService) running(ownerID uint) int {
+	n := 0
+	for _, job := range s.jobs {
+		if job.OwnerID == ownerID && job.FinishedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// prune удаляет задачи, завершённые раньше чем JobTTL назад. Вызывается под mu.
+func (s *BulkService) prune(now time.Time) {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && now.Sub(*job.FinishedAt) > s.JobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// run выполняет задачу. Строки с ошибкой разбора сразу попадают в результаты.
+func (s *BulkService) run(ctx context.Context, job *payload.BulkJob, rows []payload.BulkLinkRow) {
+	s.update(job, func() { job.Status = payload.BulkJobRunning })
+	if job.Mode == payload.BulkModeAtomic {
+		s.runAtomic(ctx, job, rows)
+	} else {
+		s.runPartial(ctx, job, rows)
+	}
+	s.update(job, func() {
+		now := time.Now()
+		job.FinishedAt = &now
+		if job.Err != nil {
+			job.Status = payload.BulkJobFailed
+		} else {
+			job.Status = payload.BulkJobDone
+		}
+	})
+	logger.Info("Задача создания ссылок завершена", zap.String("job", job.ID), zap.String("status", job.Status),
+		zap.Int("created", job.Created), zap.Int("failed", job.Failed))
+}
+
+// runPartial создаёт строки по одной, ошибка строки не мешает остальным.
+func (s *BulkService) runPartial(ctx context.Context, job *payload.BulkJob, rows []payload.BulkLinkRow) {
+	for _, row := range rows {
+		result := payload.BulkLinkResult{Row: row.Row, Err: row.Err}
+		if result.Err == nil {
+			result.Link, result.Err = s.Links.Create(ctx, row.Link.Model(job.OwnerID))
+			result.Err = bulkRowError(result.Err)
+		}
+		s.update(job, func() { s.record(job, result) })
+	}
+}
+
+// runAtomic создаёт все строки одной транзакцией. Если хоть одна строка с
+// ошибкой, не создаётся ни одна, и задача завершается с ErrBulkRolledBack.
+func (s *BulkService) runAtomic(ctx context.Context, job *payload.BulkJob, rows []payload.BulkLinkRow) {
+	results := make([]payload.BulkLinkResult, len(rows))
+	var links []*models.Link
+	var index []int // номер строки в rows для каждой ссылки из links
+	unparsed := false
+	for i, row := range rows {
+		results[i] = payload.BulkLinkResult{Row: row.Row, Err: row.Err}
+		if row.Err == nil {
+			links = append(links, row.Link.Model(job.OwnerID))
+			index = append(index, i)
+		} else {
+			unparsed = true
+		}
+	}
+
+	// С неразобранной строкой пачка не создаётся: CreateAll сохранил бы
+	// остальные строки, ничего не зная о ней.
+	var jobErr error
+	if len(links) > 0 && !unparsed {
+		errs, err := s.Links.CreateAll(ctx, links)
+		for j, i := range index {
+			results[i].Err = bulkRowError(errs[j])
+		}
+		if err != nil {
+			jobErr = err
+		}
+	}
+	if jobErr == nil && slices.ContainsFunc(results, func(r payload.BulkLinkResult) bool { return r.Err != nil }) {
+		jobErr = ErrBulkRolledBack
+	}
+	if jobErr == nil {
+		for j, i := range index {
+			results[i].Link = links[j]
+		}
+	}
+
+	s.update(job, func() {
+		for _, result := range results {
+			s.record(job, result)
+		}
+		job.Err = jobErr
+	})
+}
+
+// record добавляет результат строки к задаче. Правильная строка откатанной
+// пачки не считается ни созданной, ни ошибочной. Вызывается под mu.
+func (s *BulkService) record(job *payload.BulkJob, result payload.BulkLinkResult) {
+	job.Results = append(job.Results, result)
+	job.Processed++
+	switch {
+	case result.Err != nil:
+		job.Failed++
+	case result.Link != nil:
+		job.Created++
+	}
+}
+
+// update меняет задачу под mu, чтобы Job видел согласованный снимок.
+func (s *BulkService) update(job *payload.BulkJob, change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change()
+}
+
+// bulkRowError называет поле занятого кода так же, как в строке запроса: alias.
+func bulkRowError(err error) error {
+	if errors.Is(err, ErrLinkHashTaken) {
+		return ErrLinkHashTaken.WithField("alias", "taken", "").Wrap(err)
+	}
+	return err
+}
+
+// newJobID возвращает случайный ID задачи.
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Error("Ошибка генерации ID задачи", zap.Error(err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+)
+
+// fakeBulkLinks creates links in memory. A URL containing "bad" fails the
+// link checks and the alias "taken" is already in use. Create waits for
+// release to be closed before creating a URL containing "slow".
+type fakeBulkLinks struct {
+	LinkServ
+	release chan struct{}
+
+	mu      sync.Mutex
+	created []*models.Link
+}
+
+func (s *fakeBulkLinks) check(link *models.Link) error {
+	switch {
+	case strings.Contains(link.Url, "bad"):
+		return common.ErrURLRejected
+	case link.Hash == "taken":
+		return ErrLinkHashTaken
+	}
+	return nil
+}
+
+func (s *fakeBulkLinks) Create(_ context.Context, link *models.Link) (*models.Link, error) {
+	if strings.Contains(link.Url, "slow") {
+		<-s.release
+	}
+	if err := s.check(link); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, link)
+	return link, nil
+}
+
+func (s *fakeBulkLinks) CreateAll(_ context.Context, links []*models.Link) ([]error, error) {
+	errs := make([]error, len(links))
+	failed := false
+	for i, link := range links {
+		if errs[i] = s.check(link); errs[i] != nil {
+			failed = true
+		}
+	}
+	if failed {
+		return errs, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.created = append(s.created, links...)
+	return errs, nil
+}
+
+func bulkRows(urls ...string) []payload.BulkLinkRow {
+	rows := make([]payload.BulkLinkRow, len(urls))
+	for i, url := range urls {
+		rows[i] = payload.BulkLinkRow{Row: i + 1, Link: payload.BulkLinkRequest{URL: url}}
+	}
+	return rows
+}
+
+// waitBulkJob polls the job until it is finished.
+func waitBulkJob(t *testing.T, s *BulkService, ownerID uint, id string) *payload.BulkJob {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		job, err := s.Job(ownerID, id)
+		if err != nil {
+			t.Fatalf("Job: %v", err)
+		}
+		if job.FinishedAt != nil {
+			return job
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("job did not finish")
+	return nil
+}
+
+func TestBulkPartial(t *testing.T) {
+	links := &fakeBulkLinks{}
+	s := NewBulkService(&BulkServiceDeps{Links: links, SyncRows: 10, JobTTL: time.Hour})
+	rows := bulkRows("https://a.example", "https://bad.example", "https://c.example", "")
+	rows[2].Link.Alias = "taken"
+	rows[3].Err = common.ErrInvalidRequest.WithField("url", "required", "")
+
+	job, err := s.Start(context.Background(), 1, payload.BulkModePartial, append(rows, bulkRows("https://e.example")...), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != "" || job.Status != payload.BulkJobDone || job.Err != nil {
+		t.Fatalf("job = %+v, want a finished synchronous job", job)
+	}
+	if job.Total != 5 || job.Processed != 5 || job.Created != 2 || job.Failed != 3 {
+		t.Errorf("counts = %d/%d created %d failed %d, want 5/5 created 2 failed 3", job.Processed, job.Total, job.Created, job.Failed)
+	}
+	if len(links.created) != 2 {
+		t.Errorf("created links = %d, want 2", len(links.created))
+	}
+	for i, want := range []bool{true, false, false, false, true} {
+		if got := job.Results[i].Link != nil; got != want {
+			t.Errorf("row %d created = %v, want %v (%v)", job.Results[i].Row, got, want, job.Results[i].Err)
+		}
+	}
+	if fields := common.AsAppError(job.Results[2].Err).Fields; len(fields) != 1 || fields[0].Field != "alias" {
+		t.Errorf("taken alias error fields = %+v, want alias", fields)
+	}
+	if links.created[0].UserID == nil || *links.created[0].UserID != 1 {
+		t.Error("created link does not belong to the owner")
+	}
+}
+
+func TestBulkAtomic(t *testing.T) {
+	tests := []struct {
+		name        string
+		rows        []payload.BulkLinkRow
+		wantErr     error
+		wantCreated int
+		wantFailed  int
+	}{
+		{name: "all valid", rows: bulkRows("https://a.example", "https://b.example"), wantCreated: 2},
+		{name: "rejected row", rows: bulkRows("https://a.example", "https://bad.example"), wantErr: ErrBulkRolledBack, wantFailed: 1},
+		{
+			name:    "unparsed row",
+			rows:    append(bulkRows("https://a.example"), payload.BulkLinkRow{Row: 2, Err: common.ErrInvalidRequest}),
+			wantErr: ErrBulkRolledBack, wantFailed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links := &fakeBulkLinks{}
+			s := NewBulkService(&BulkServiceDeps{Links: links, SyncRows: 10, JobTTL: time.Hour})
+			job, err := s.Start(context.Background(), 1, payload.BulkModeAtomic, tt.rows, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !errors.Is(job.Err, tt.wantErr) && job.Err != tt.wantErr {
+				t.Errorf("job error = %v, want %v", job.Err, tt.wantErr)
+			}
+			if len(links.created) != tt.wantCreated || job.Created != tt.wantCreated || job.Failed != tt.wantFailed {
+				t.Errorf("created %d (job %d), failed %d; want %d created, %d failed",
+					len(links.created), job.Created, job.Failed, tt.wantCreated, tt.wantFailed)
+			}
+			wantStatus := payload.BulkJobDone
+			if tt.wantErr != nil {
+				wantStatus = payload.BulkJobFailed
+				for _, result := range job.Results {
+					if result.Link != nil {
+						t.Errorf("row %d reports a link after the rollback", result.Row)
+					}
+				}
+			}
+			if job.Status != wantStatus {
+				t.Errorf("status = %q, want %q", job.Status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestBulkEmpty(t *testing.T) {
+	s := NewBulkService(&BulkServiceDeps{Links: &fakeBulkLinks{}, SyncRows: 10})
+	if _, err := s.Start(context.Background(), 1, payload.BulkModePartial, nil, false); !errors.Is(err, ErrBulkEmpty) {
+		t.Errorf("Start(no rows) = %v, want ErrBulkEmpty", err)
+	}
+}
+
+func TestBulkJobOwnership(t *testing.T) {
+	s := NewBulkService(&BulkServiceDeps{Links: &fakeBulkLinks{}, SyncRows: 1, JobTTL: time.Hour})
+	job, err := s.Start(context.Background(), 1, payload.BulkModePartial, bulkRows("https://a.example", "https://b.example"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == "" || job.Status != payload.BulkJobPending {
+		t.Fatalf("job = %+v, want a pending background job", job)
+	}
+
+	done := waitBulkJob(t, s, 1, job.ID)
+	if done.Status != payload.BulkJobDone || done.Created != 2 || len(done.Results) != 2 {
+		t.Errorf("finished job = %+v", done)
+	}
+	if _, err := s.Job(2, job.ID); !errors.Is(err, ErrBulkJobNotFound) {
+		t.Errorf("Job(other owner) = %v, want ErrBulkJobNotFound", err)
+	}
+	if _, err := s.Job(1, "missing"); !errors.Is(err, ErrBulkJobNotFound) {
+		t.Errorf("Job(missing) = %v, want ErrBulkJobNotFound", err)
+	}
+}
+
+func TestBulkJobExpires(t *testing.T) {
+	s := NewBulkService(&BulkServiceDeps{Links: &fakeBulkLinks{}, SyncRows: 10, JobTTL: 50 * time.Millisecond})
+	job, err := s.Start(context.Background(), 1, payload.BulkModePartial, bulkRows("https://a.example"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitBulkJob(t, s, 1, job.ID)
+	time.Sleep(100 * time.Millisecond)
+	if _, err := s.Job(1, job.ID); !errors.Is(err, ErrBulkJobNotFound) {
+		t.Errorf("Job(expired) = %v, want ErrBulkJobNotFound", err)
+	}
+}
+
+func TestBulkMaxJobsPerOwner(t *testing.T) {
+	links := &fakeBulkLinks{release: make(chan struct{})}
+	s := NewBulkService(&BulkServiceDeps{Links: links, SyncRows: 10, JobTTL: time.Hour, MaxJobs: 2})
+	ctx := context.Background()
+	start := func(ownerID uint) (*payload.BulkJob, error) {
+		return s.Start(ctx, ownerID, payload.BulkModePartial, bulkRows("https://slow.example"), true)
+	}
+
+	var jobs []*payload.BulkJob
+	for i := 0; i < 2; i++ {
+		job, err := start(1)
+		if err != nil {
+			t.Fatalf("job %d: %v", i, err)
+		}
+		jobs = append(jobs, job)
+	}
+	if _, err := start(1); !errors.Is(err, ErrBulkTooManyJobs) {
+		t.Errorf("third job = %v, want ErrBulkTooManyJobs", err)
+	}
+	other, err := start(2)
+	if err != nil {
+		t.Errorf("job of another owner: %v", err)
+	}
+	// Synchronous requests are not limited.
+	if _, err := s.Start(ctx, 1, payload.BulkModePartial, bulkRows("https://a.example"), false); err != nil {
+		t.Errorf("synchronous request: %v", err)
+	}
+
+	close(links.release)
+	for _, job := range jobs {
+		waitBulkJob(t, s, 1, job.ID)
+	}
+	waitBulkJob(t, s, 2, other.ID)
+	if _, err := start(1); err != nil {
+		t.Errorf("job after the others finished: %v", err)
+	}
+}
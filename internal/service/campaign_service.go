@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+var (
+	ErrCampaignNotFound     = common.ErrCampaignNotFound
+	ErrCampaignNameTaken    = common.ErrCampaignNameTaken
+	ErrCampaignCreateFailed = common.ErrCampaignCreateFailed
+	ErrCampaignDeleteFailed = common.ErrCampaignDeleteFailed
+	ErrCampaignListFailed   = common.ErrCampaignListFailed
+)
+
+// CampaignService предоставляет методы для работы с кампаниями пользователя.
+// Кампании видит и меняет только их владелец.
+type CampaignService struct {
+	Repo repository.CampaignRepo
+}
+
+// NewCampaignService создаёт новый экземпляр CampaignService.
+func NewCampaignService(repo repository.CampaignRepo) *CampaignService {
+	return &CampaignService{Repo: repo}
+}
+
+// Create создаёт кампанию владельца. Название должно быть уникальным среди его кампаний.
+func (s *CampaignService) Create(ctx context.Context, ownerID uint, name, description string) (*models.Campaign, error) {
+	campaign := &models.Campaign{UserID: ownerID, Name: name, Description: description}
+	if err := s.Repo.CreateCampaign(ctx, campaign); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCampaignNameTaken.WithField("name", "taken", "").Wrap(err)
+		}
+		return nil, ErrCampaignCreateFailed.Wrap(err)
+	}
+	logger.Info("Кампания создана", zap.Uint("id", campaign.ID), zap.Uint("user_id", ownerID))
+	return campaign, nil
+}
+
+// GetAll возвращает кампании владельца.
+func (s *CampaignService) GetAll(ctx context.Context, ownerID uint) ([]models.Campaign, error) {
+	campaigns, err := s.Repo.GetCampaigns(ctx, ownerID)
+	if err != nil {
+		return nil, ErrCampaignListFailed.Wrap(err)
+	}
+	return campaigns, nil
+}
+
+// Get возвращает кампанию владельца по ID.
+func (s *CampaignService) Get(ctx context.Context, id, ownerID uint) (*models.Campaign, error) {
+	campaign, err := s.Repo.FindCampaign(ctx, id, ownerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCampaignNotFound
+		}
+		return nil, ErrCampaignListFailed.Wrap(err)
+	}
+	return campaign, nil
+}
+
+// Delete удаляет кампанию владельца, её ссылки остаются без кампании.
+func (s *CampaignService) Delete(ctx context.Context, id, ownerID uint) error {
+	if err := s.Repo.DeleteCampaign(ctx, id, ownerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCampaignNotFound
+		}
+		return ErrCampaignDeleteFailed.Wrap(err)
+	}
+	logger.Info("Кампания удалена", zap.Uint("id", id), zap.Uint("user_id", ownerID))
+	return nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+var (
+	ErrFolderNotFound     = common.ErrFolderNotFound
+	ErrFolderNameTaken    = common.ErrFolderNameTaken
+	ErrFolderCreateFailed = common.ErrFolderCreateFailed
+	ErrFolderDeleteFailed = common.ErrFolderDeleteFailed
+	ErrFolderListFailed   = common.ErrFolderListFailed
+	ErrTagsFailed         = common.ErrTagsFailed
+)
+
+// FolderService предоставляет методы для работы с папками и тегами пользователя.
+// Папки и теги видит и меняет только их владелец.
+type FolderService struct {
+	Repo    repository.FolderRepo
+	TagRepo repository.TagRepo
+}
+
+// NewFolderService создаёт новый экземпляр FolderService.
+func NewFolderService(repo repository.FolderRepo, tags repository.TagRepo) *FolderService {
+	return &FolderService{Repo: repo, TagRepo: tags}
+}
+
+// Create создаёт папку владельца. Название должно быть уникальным среди его папок.
+func (s *FolderService) Create(ctx context.Context, ownerID uint, name string) (*models.Folder, error) {
+	folder := &models.Folder{UserID: ownerID, Name: name}
+	if err := s.Repo.CreateFolder(ctx, folder); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrFolderNameTaken.WithField("name", "taken", "").Wrap(err)
+		}
+		return nil, ErrFolderCreateFailed.Wrap(err)
+	}
+	logger.Info("Папка создана", zap.Uint("id", folder.ID), zap.Uint("user_id", ownerID))
+	return folder, nil
+}
+
+// GetAll возвращает папки владельца.
+func (s *FolderService) GetAll(ctx context.Context, ownerID uint) ([]models.Folder, error) {
+	folders, err := s.Repo.GetFolders(ctx, ownerID)
+	if err != nil {
+		return nil, ErrFolderListFailed.Wrap(err)
+	}
+	return folders, nil
+}
+
+// Delete удаляет папку владельца, её ссылки остаются без папки.
+func (s *FolderService) Delete(ctx context.Context, id, ownerID uint) error {
+	if err := s.Repo.DeleteFolder(ctx, id, ownerID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFolderNotFound
+		}
+		return ErrFolderDeleteFailed.Wrap(err)
+	}
+	logger.Info("Папка удалена", zap.Uint("id", id), zap.Uint("user_id", ownerID))
+	return nil
+}
+
+// Tags возвращает теги владельца с числом ссылок у каждого.
+func (s *FolderService) Tags(ctx context.Context, ownerID uint) ([]payload.TagListItem, error) {
+	tags, err := s.TagRepo.GetTags(ctx, ownerID)
+	if err != nil {
+		return nil, ErrTagsFailed.Wrap(err)
+	}
+	return tags, nil
+}
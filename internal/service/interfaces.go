@@ -4,13 +4,18 @@ import (
 	"context"
 	"shorty/internal/models"
 	"shorty/internal/payload"
+	"shorty/pkg/urlpolicy"
 	"time"
 )
 
 type LinkServ interface {
 	Create(ctx context.Context, link *models.Link) (*models.Link, error)
+	CreateAll(ctx context.Context, links []*models.Link) (errs []error, err error)
 	GetAll(ctx context.Context, limit, offset int) ([]models.Link, error)
 	GetByHash(ctx context.Context, hash string) (*models.Link, error)
+	Lookup(ctx context.Context, hash string) (*models.Link, error)
+	Search(ctx context.Context, filter payload.LinkListFilter, limit, offset int) ([]payload.LinkListItem, error)
+	CountSearch(ctx context.Context, filter payload.LinkListFilter) (int64, error)
 	Update(ctx context.Context, link *models.Link) (*models.Link, error)
 	Delete(ctx context.Context, linkID uint) error
 	Count(ctx context.Context) (int64, error)
@@ -25,7 +30,11 @@ type LinkServ interface {
 type StatServ interface {
 	AddClick(ctx context.Context)
 	GetClickedLinkStats(ctx context.Context, by string, from, to time.Time) []payload.GetStatsResponse
-	GetAllLinksStats(ctx context.Context, from, to time.Time) []payload.LinkStatsResponse
+	GetAllLinksStats(ctx context.Context, filter payload.LinkStatsFilter) []payload.LinkStatsResponse
+	CampaignStats(ctx context.Context, ownerID *uint, from, to time.Time) ([]payload.CampaignStatsResponse, error)
+	RecordConversion(ctx context.Context, conversion *models.Conversion) error
+	VariantStats(ctx context.Context, link *models.Link) ([]payload.VariantStatsResponse, error)
+	DailyClicks(ctx context.Context, linkIDs []uint, days int) (map[uint][]int64, error)
 }
 
 type UserServ interface {
@@ -43,3 +52,66 @@ type AuthServ interface {
 	Registration(ctx context.Context, name, email, password string, role models.Role, isBlocked bool) (*models.User, error)
 	Login(ctx context.Context, email, password string, role models.Role) (*models.User, error)
 }
+
+type LoginGuardServ interface {
+	Check(ctx context.Context, email, ip string) (time.Duration, error)
+	RegisterFailure(ctx context.Context, email, ip string) (time.Duration, error)
+	RegisterSuccess(ctx context.Context, email string) error
+	GetActive(ctx context.Context, limit, offset int) ([]models.LoginLockout, error)
+	CountActive(ctx context.Context) (int64, error)
+	Clear(ctx context.Context, id uint) error
+}
+
+type SessionServ interface {
+	Create(ctx context.Context, user *models.User, ip, userAgent string) (string, *models.Session, error)
+	Resolve(ctx context.Context, token string) (*models.Session, *models.User, error)
+	Destroy(ctx context.Context, token string) error
+}
+
+type AuditServ interface {
+	Record(ctx context.Context, action, targetType string, targetID uint, before, after any)
+	GetAll(ctx context.Context, filter payload.AuditLogFilter, limit, offset int) ([]models.AuditLog, error)
+	Count(ctx context.Context, filter payload.AuditLogFilter) (int64, error)
+}
+
+type CampaignServ interface {
+	Create(ctx context.Context, ownerID uint, name, description string) (*models.Campaign, error)
+	GetAll(ctx context.Context, ownerID uint) ([]models.Campaign, error)
+	Get(ctx context.Context, id, ownerID uint) (*models.Campaign, error)
+	Delete(ctx context.Context, id, ownerID uint) error
+}
+
+type FolderServ interface {
+	Create(ctx context.Context, ownerID uint, name string) (*models.Folder, error)
+	GetAll(ctx context.Context, ownerID uint) ([]models.Folder, error)
+	Delete(ctx context.Context, id, ownerID uint) error
+	Tags(ctx context.Context, ownerID uint) ([]payload.TagListItem, error)
+}
+
+type BulkServ interface {
+	Start(ctx context.Context, ownerID uint, mode string, rows []payload.BulkLinkRow, async bool) (*payload.BulkJob, error)
+	Job(ownerID uint, id string) (*payload.BulkJob, error)
+}
+
+type ReportServ interface {
+	Report(ctx context.Context, hash string, reason models.ReportReason, comment, reporterIP string) (*models.Report, error)
+	Queue(ctx context.Context, limit, offset int) ([]payload.ReportQueueItem, error)
+	CountQueue(ctx context.Context) (int64, error)
+	Dismiss(ctx context.Context, linkID uint) error
+	BlockLink(ctx context.Context, linkID uint) (*models.Link, error)
+	BlockOwner(ctx context.Context, linkID uint) (*models.User, error)
+}
+
+type QRServ interface {
+	Render(content string, req payload.QRRequest) (*payload.QRImage, error)
+}
+
+type URLPolicyServ interface {
+	Check(ctx context.Context, rawURL string, userID *uint) error
+	Resolve(ctx context.Context, rawURL string, userID *uint) (string, error)
+	Domains(list urlpolicy.List) ([]string, error)
+	AddDomain(ctx context.Context, list urlpolicy.List, domain string) (string, error)
+	RemoveDomain(ctx context.Context, list urlpolicy.List, domain string) error
+	Rejections(ctx context.Context, limit, offset int) ([]models.URLRejection, error)
+	CountRejections(ctx context.Context) (int64, error)
+}
@@ -4,44 +4,320 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"shorty/internal/common"
 	"shorty/internal/models"
+	"shorty/internal/payload"
 	"shorty/internal/repository"
 	"shorty/pkg/logger"
+	"shorty/pkg/shortcode"
+	"shorty/pkg/urlpolicy"
 )
 
 var (
-	ErrLinkNotFound = errors.New("ссылка не найдена")
-	ErrLinkCreation = errors.New("не удалось создать ссылку")
-	ErrLinkUpdate   = errors.New("не удалось обновить ссылку")
-	ErrLinkDeletion = errors.New("не удалось удалить ссылку")
-	ErrLinkNotValid = errors.New("ссылка некорректна")
+	ErrLinkNotFound  = common.ErrLinkNotFound
+	ErrLinkCreation  = common.ErrLinkCreateUR
+	ErrLinkUpdate    = common.ErrLinkUpdateLinkFailed
+	ErrLinkDeletion  = common.ErrLinkDeleteFailed
+	ErrLinkNotValid  = common.ErrBadRequest
+	ErrLinkBlocked   = common.ErrLinkBlocked
+	ErrLinkExpired   = common.ErrLinkExpired
+	ErrLinkHashTaken = common.ErrLinkHashTaken
 )
 
+// defaultCodeAttempts - сколько кодов пробуется при коллизиях, если в зависимостях не задано.
+const defaultCodeAttempts = 5
+
+// LinkServiceDeps - зависимости для создания экземпляра LinkService.
+type LinkServiceDeps struct {
+	Repo        repository.LinkRepo
+	Audit       AuditServ
+	Policy      URLPolicyServ
+	Codes       *shortcode.Pool
+	MaxAttempts int // попыток подобрать свободный код, по умолчанию defaultCodeAttempts
+	Campaigns   repository.CampaignRepo
+	Folders     repository.FolderRepo
+	Tags        repository.TagRepo
+
+	// Dedupe включает режим, в котором Create возвращает уже существующую ссылку
+	// владельца на тот же канонический адрес вместо создания новой.
+	Dedupe bool
+	// StripTracking убирает параметры отслеживания (utm_*, fbclid...) из канонического адреса.
+	StripTracking bool
+	// SubstringSearch дополняет полнотекстовый поиск поиском подстроки в адресе,
+	// коде и заголовке. Требует триграммных индексов из migrations/search.
+	SubstringSearch bool
+}
+
 // LinkService предоставляет методы для работы с ссылками.
+// Действия администраторов записываются в журнал аудита,
+// адрес назначения проверяется политикой URL при создании и изменении.
+// Короткий код новой ссылки выдаёт пул кодов, при коллизии код подбирается заново.
 type LinkService struct {
-	Repo repository.LinkRepo
+	Repo            repository.LinkRepo
+	Audit           AuditServ
+	Policy          URLPolicyServ
+	Codes           *shortcode.Pool
+	MaxAttempts     int
+	Campaigns       repository.CampaignRepo
+	Folders         repository.FolderRepo
+	Tags            repository.TagRepo
+	Dedupe          bool
+	StripTracking   bool
+	SubstringSearch bool
 }
 
 // NewLinkService создаёт новый экземпляр LinkService
-func NewLinkService(repo repository.LinkRepo) *LinkService {
-	return &LinkService{Repo: repo}
+func NewLinkService(deps *LinkServiceDeps) *LinkService {
+	attempts := deps.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultCodeAttempts
+	}
+	return &LinkService{
+		Repo:            deps.Repo,
+		Audit:           deps.Audit,
+		Policy:          deps.Policy,
+		Codes:           deps.Codes,
+		MaxAttempts:     attempts,
+		Campaigns:       deps.Campaigns,
+		Folders:         deps.Folders,
+		Tags:            deps.Tags,
+		Dedupe:          deps.Dedupe,
+		StripTracking:   deps.StripTracking,
+		SubstringSearch: deps.SubstringSearch,
+	}
 }
 
-// Create создаёт новую ссылку
+// Create создаёт новую ссылку. В режиме Dedupe для ссылки владельца без своего
+// алиаса и других опций (см. isPlainLink) возвращается его действующая ссылка на
+// тот же канонический адрес, если она есть.
 func (s *LinkService) Create(ctx context.Context, link *models.Link) (*models.Link, error) {
-	newLink, err := s.Repo.CreateLink(ctx, link)
+	existing, err := s.prepare(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+	if err := s.applyTags(ctx, link); err != nil {
+		return nil, err
+	}
+	newLink, err := s.store(ctx, link)
 	if err != nil {
-		logger.Error("Ошибка при создании ссылки", zap.Error(err))
-		return nil, ErrLinkCreation
+		return nil, err
 	}
 	logger.Info("Ссылка успешно создана", zap.Uint("id", newLink.ID), zap.String("hash", newLink.Hash))
 	return newLink, nil
 }
 
+// CreateAll создаёт ссылки одной транзакцией: сохраняются либо все, либо ни одной.
+// errs[i] - ошибка проверки i-й ссылки; если она есть хоть у одной, ничего не
+// сохраняется. err - ошибка сохранения всей пачки. Существующие ссылки, найденные
+// в режиме Dedupe, подставляются в links вместо новых и повторно не сохраняются.
+func (s *LinkService) CreateAll(ctx context.Context, links []*models.Link) (errs []error, err error) {
+	errs = make([]error, len(links))
+	failed := false
+	aliases := make(map[string]bool)
+	fresh := make([]*models.Link, 0, len(links))
+	for i, link := range links {
+		existing, err := s.prepare(ctx, link)
+		if err == nil && existing == nil && link.Hash != "" {
+			err = s.checkAlias(ctx, link.Hash, aliases)
+		}
+		switch {
+		case err != nil:
+			errs[i], failed = err, true
+		case existing != nil:
+			links[i] = existing
+		default:
+			fresh = append(fresh, link)
+		}
+	}
+	if failed {
+		return errs, nil
+	}
+	for _, link := range fresh {
+		if err := s.applyTags(ctx, link); err != nil {
+			return errs, err
+		}
+	}
+	if err := s.storeAll(ctx, fresh); err != nil {
+		return errs, err
+	}
+	logger.Info("Ссылки успешно созданы", zap.Int("count", len(fresh)))
+	return errs, nil
+}
+
+// prepare проверяет новую ссылку политикой URL и заполняет канонический и
+// конечный адреса. В режиме Dedupe возвращает действующую ссылку владельца на
+// тот же адрес, если она есть; тогда новая ссылка не нужна.
+func (s *LinkService) prepare(ctx context.Context, link *models.Link) (*models.Link, error) {
+	// Опции проверяются до applyCampaign, который дописывает UTM кампании.
+	dedupe := s.Dedupe && link.UserID != nil && isPlainLink(link)
+	if err := s.Policy.Check(ctx, link.Url, link.UserID); err != nil {
+		return nil, err
+	}
+	if err := s.canonicalize(link); err != nil {
+		return nil, err
+	}
+	if err := s.applyCampaign(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.applyFolder(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.checkTargets(ctx, link, link.UserID); err != nil {
+		return nil, err
+	}
+	if dedupe {
+		existing, err := s.Repo.FindOwnedLinkByCanonicalURL(ctx, *link.UserID, link.CanonicalUrl)
+		if err == nil {
+			logger.Info("Возвращена существующая ссылка на тот же адрес", zap.Uint("id", existing.ID), zap.Uint("user_id", *link.UserID))
+			return existing, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error("Ошибка поиска ссылки по каноническому адресу", zap.Error(err))
+			return nil, ErrLinkCreation.Wrap(err)
+		}
+	}
+	finalURL, err := s.Policy.Resolve(ctx, link.Url, link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	link.FinalUrl = finalURL
+	return nil, nil
+}
+
+// isPlainLink сообщает, что в запросе нет ничего, кроме адреса: ни своего
+// алиаса, ни кампании и UTM, ни правил, вариантов, срока, папки, тегов и
+// прочих опций. Только такую ссылку можно заменить существующей: иначе опции
+// запроса молча потерялись бы.
+func isPlainLink(link *models.Link) bool {
+	return link.Hash == "" &&
+		link.CampaignID == nil && link.UTM.IsZero() &&
+		len(link.Rules) == 0 && len(link.Variants) == 0 &&
+		link.ExpiresAt == nil &&
+		link.FolderID == nil && len(link.Tags) == 0 && link.Notes == "" &&
+		(link.ForwardQuery == "" || link.ForwardQuery == models.QueryForwardOff) && !link.ForwardPath &&
+		link.Title == "" && link.Description == "" && !link.AlwaysPreview
+}
+
+// checkAlias проверяет, что свой алиас не занят другой ссылкой и не повторяется
+// среди алиасов seen той же пачки.
+func (s *LinkService) checkAlias(ctx context.Context, hash string, seen map[string]bool) error {
+	if seen[hash] {
+		return ErrLinkHashTaken.WithField("hash", "taken", "")
+	}
+	seen[hash] = true
+	_, err := s.Repo.FindLinkByHash(ctx, hash)
+	if err == nil {
+		return ErrLinkHashTaken.WithField("hash", "taken", "")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logger.Error("Ошибка проверки алиаса", zap.String("hash", hash), zap.Error(err))
+		return ErrLinkCreation.Wrap(err)
+	}
+	return nil
+}
+
+// store сохраняет новую ссылку. Заданный заранее хеш (свой алиас) сохраняется как есть,
+// занятый возвращает ErrLinkHashTaken. Иначе код берётся из пула и при коллизии
+// подбирается заново, не больше MaxAttempts раз.
+func (s *LinkService) store(ctx context.Context, link *models.Link) (*models.Link, error) {
+	if link.Hash != "" {
+		newLink, err := s.Repo.CreateLink(ctx, link)
+		if err != nil {
+			return nil, linkSaveError(err, ErrLinkCreation)
+		}
+		return newLink, nil
+	}
+	for attempt := 1; ; attempt++ {
+		code, err := s.Codes.Next(ctx)
+		if err != nil {
+			logger.Error("Ошибка генерации кода ссылки", zap.Error(err))
+			return nil, ErrLinkCreation.Wrap(err)
+		}
+		link.Hash = code
+		newLink, err := s.Repo.CreateLink(ctx, link)
+		if err == nil {
+			s.Codes.Taken(code)
+			return newLink, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) || attempt >= s.MaxAttempts {
+			link.Hash = ""
+			logger.Error("Ошибка при создании ссылки", zap.Int("attempt", attempt), zap.Error(err))
+			return nil, ErrLinkCreation.Wrap(err)
+		}
+		logger.Warn("Коллизия кода ссылки, пробуем другой", zap.String("hash", code), zap.Int("attempt", attempt))
+		s.Codes.Collided()
+	}
+}
+
+// storeAll сохраняет новые ссылки одной транзакцией. Ссылкам без своего алиаса
+// коды выдаёт пул; при коллизии вся пачка сохраняется заново с новыми кодами,
+// не больше MaxAttempts раз.
+func (s *LinkService) storeAll(ctx context.Context, links []*models.Link) error {
+	taken := make(map[string]bool, len(links))
+	var generated []*models.Link
+	for _, link := range links {
+		if link.Hash == "" {
+			generated = append(generated, link)
+		} else {
+			taken[link.Hash] = true
+		}
+	}
+	for attempt := 1; ; attempt++ {
+		codes := maps.Clone(taken)
+		for _, link := range generated {
+			for link.Hash == "" || codes[link.Hash] {
+				code, err := s.Codes.Next(ctx)
+				if err != nil {
+					logger.Error("Ошибка генерации кода ссылки", zap.Error(err))
+					return ErrLinkCreation.Wrap(err)
+				}
+				link.Hash = code
+			}
+			codes[link.Hash] = true
+		}
+		err := s.Repo.CreateLinks(ctx, links)
+		if err == nil {
+			for _, link := range generated {
+				s.Codes.Taken(link.Hash)
+			}
+			return nil
+		}
+		// Откатанная транзакция могла успеть выдать ссылкам ID.
+		for _, link := range links {
+			link.ID = 0
+		}
+		for _, link := range generated {
+			link.Hash = ""
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) || len(generated) == 0 || attempt >= s.MaxAttempts {
+			logger.Error("Ошибка при создании ссылок", zap.Int("attempt", attempt), zap.Error(err))
+			return linkSaveError(err, ErrLinkCreation)
+		}
+		logger.Warn("Коллизия кода в пачке ссылок, пробуем другие", zap.Int("attempt", attempt))
+		s.Codes.Collided()
+	}
+}
+
+// linkSaveError переводит ошибку сохранения ссылки: занятый хеш - в ErrLinkHashTaken
+// с ошибкой поля hash, остальные - в переданную ошибку.
+func linkSaveError(err error, fallback *common.AppError) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrLinkHashTaken.WithField("hash", "taken", "").Wrap(err)
+	}
+	logger.Error("Ошибка при сохранении ссылки", zap.Error(err))
+	return fallback.Wrap(err)
+}
+
 // GetAll возвращает список ссылок с пагинацией
 func (s *LinkService) GetAll(ctx context.Context, limit, offset int) ([]models.Link, error) {
 	links, err := s.Repo.GetLinks(ctx, limit, offset)
@@ -68,24 +344,195 @@ func (s *LinkService) GetByHash(ctx context.Context, hash string) (*models.Link,
 	return link, nil
 }
 
-// Update обновляет ссылку
+// Lookup ищет ссылку для перехода по хешу. Для заблокированной ссылки
+// возвращается ErrLinkBlocked, для просроченной - ErrLinkExpired.
+func (s *LinkService) Lookup(ctx context.Context, hash string) (*models.Link, error) {
+	link, err := s.Repo.FindLinkByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLinkNotFound
+		}
+		logger.Error("Ошибка получения ссылки по хешу", zap.String("hash", hash), zap.Error(err))
+		return nil, fmt.Errorf("не удалось найти ссылку с хешем %s: %w", hash, err)
+	}
+	if link.IsBlocked {
+		return nil, ErrLinkBlocked
+	}
+	if link.IsExpired(time.Now()) {
+		return nil, ErrLinkExpired
+	}
+	return link, nil
+}
+
+// Update обновляет ссылку. Теги заменяются, только если link.Tags не nil.
 func (s *LinkService) Update(ctx context.Context, link *models.Link) (*models.Link, error) {
+	if link.Url != "" {
+		if err := s.checkUpdatedURL(ctx, link); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.applyCampaign(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.applyFolder(ctx, link); err != nil {
+		return nil, err
+	}
+	if err := s.applyTags(ctx, link); err != nil {
+		return nil, err
+	}
 	updatedLink, err := s.Repo.UpdateLink(ctx, link)
 	if err != nil {
-		logger.Error("Ошибка при обновлении ссылки", zap.Uint("id", link.ID), zap.Error(err))
-		return nil, ErrLinkUpdate
+		logger.Warn("Ссылка не обновлена", zap.Uint("id", link.ID), zap.Error(err))
+		return nil, linkSaveError(err, ErrLinkUpdate)
+	}
+	if link.Tags != nil {
+		if err := s.Tags.ReplaceLinkTags(ctx, updatedLink, link.Tags); err != nil {
+			return nil, ErrTagsFailed.Wrap(err)
+		}
 	}
 	logger.Info("Ссылка успешно обновлена", zap.Uint("id", updatedLink.ID), zap.String("hash", updatedLink.Hash))
 	return updatedLink, nil
 }
 
+// checkUpdatedURL проверяет новый адрес ссылки политикой URL
+// и обновляет конечный адрес после редиректов.
+// Отклонённая попытка записывается на владельца ссылки.
+func (s *LinkService) checkUpdatedURL(ctx context.Context, link *models.Link) error {
+	ownerID := link.UserID
+	if ownerID == nil {
+		if existing, err := s.Repo.FindLinkByID(ctx, link.ID); err == nil && existing != nil {
+			ownerID = existing.UserID
+		}
+	}
+	if err := s.Policy.Check(ctx, link.Url, ownerID); err != nil {
+		return err
+	}
+	if err := s.checkTargets(ctx, link, ownerID); err != nil {
+		return err
+	}
+	finalURL, err := s.Policy.Resolve(ctx, link.Url, ownerID)
+	if err != nil {
+		return err
+	}
+	link.FinalUrl = finalURL
+	return s.canonicalize(link)
+}
+
+// checkTargets проверяет политикой URL адреса правил таргетинга и вариантов
+// A/B-теста; ошибки полей указывают на элемент, например "rules[1].url".
+func (s *LinkService) checkTargets(ctx context.Context, link *models.Link, ownerID *uint) error {
+	for i, rule := range link.Rules {
+		if err := s.Policy.Check(ctx, rule.Url, ownerID); err != nil {
+			return common.AsAppError(err).WithFieldPrefix(fmt.Sprintf("rules[%d]", i))
+		}
+	}
+	for i, variant := range link.Variants {
+		if err := s.Policy.Check(ctx, variant.Url, ownerID); err != nil {
+			return common.AsAppError(err).WithFieldPrefix(fmt.Sprintf("variants[%d]", i))
+		}
+	}
+	return nil
+}
+
+// applyCampaign проверяет, что кампания ссылки принадлежит её владельцу, и
+// подставляет название кампании в utm_campaign, если он не задан явно.
+func (s *LinkService) applyCampaign(ctx context.Context, link *models.Link) error {
+	if link.CampaignID == nil {
+		return nil
+	}
+	if link.UserID == nil {
+		return common.ErrInvalidRequest.WithField("campaign_id", "invalid", "")
+	}
+	campaign, err := s.Campaigns.FindCampaign(ctx, *link.CampaignID, *link.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvalidRequest.WithField("campaign_id", "invalid", "")
+		}
+		return ErrCampaignListFailed.Wrap(err)
+	}
+	if link.UTM.Campaign == "" {
+		link.UTM.Campaign = campaign.Name
+	}
+	return nil
+}
+
+// applyFolder проверяет, что папка ссылки принадлежит её владельцу.
+func (s *LinkService) applyFolder(ctx context.Context, link *models.Link) error {
+	if link.FolderID == nil {
+		return nil
+	}
+	if link.UserID == nil {
+		return common.ErrInvalidRequest.WithField("folder_id", "invalid", "")
+	}
+	if _, err := s.Folders.FindFolder(ctx, *link.FolderID, *link.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return common.ErrInvalidRequest.WithField("folder_id", "invalid", "")
+		}
+		return ErrFolderListFailed.Wrap(err)
+	}
+	return nil
+}
+
+// applyTags заменяет теги ссылки, заданные только названиями, на теги владельца,
+// создавая недостающие. nil теги не трогаются.
+func (s *LinkService) applyTags(ctx context.Context, link *models.Link) error {
+	if link.Tags == nil {
+		return nil
+	}
+	names := normalizeTags(link.Tags)
+	if len(names) == 0 {
+		link.Tags = []models.Tag{}
+		return nil
+	}
+	if link.UserID == nil {
+		return ErrLinkNotValid.WithField("tags", "invalid", "")
+	}
+	tags, err := s.Tags.EnsureTags(ctx, *link.UserID, names)
+	if err != nil {
+		return ErrTagsFailed.Wrap(err)
+	}
+	link.Tags = tags
+	return nil
+}
+
+// normalizeTags возвращает названия тегов без пробелов по краям, в нижнем регистре
+// и без повторов, так что "Go" и "go " - один тег.
+func normalizeTags(tags []models.Tag) []string {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		name := strings.ToLower(strings.TrimSpace(tag.Name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// canonicalize заполняет канонический адрес ссылки, по которому ищутся дубликаты.
+func (s *LinkService) canonicalize(link *models.Link) error {
+	canonical, err := urlpolicy.Canonical(link.Url, s.StripTracking)
+	if err != nil {
+		return ErrLinkNotValid.Wrap(err)
+	}
+	link.CanonicalUrl = canonical
+	return nil
+}
+
 // Delete удаляет ссылку по ID
 func (s *LinkService) Delete(ctx context.Context, linkID uint) error {
-	err := s.Repo.DeleteLink(ctx, linkID)
+	before, err := s.FindByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	err = s.Repo.DeleteLink(ctx, linkID)
 	if err != nil {
 		logger.Error("Ошибка удаления ссылки", zap.Uint("id", linkID), zap.Error(err))
-		return ErrLinkDeletion
+		return ErrLinkDeletion.Wrap(err)
 	}
+	s.Audit.Record(ctx, AuditLinkDelete, models.AuditTargetLink, linkID, before, nil)
 	logger.Info("Ссылка успешно удалена", zap.Uint("id", linkID))
 	return nil
 }
@@ -112,46 +559,48 @@ func (s *LinkService) FindByID(ctx context.Context, linkID uint) (*models.Link,
 		logger.Error("Ошибка при поиске ссылки", zap.Uint("id", linkID), zap.Error(err))
 		return nil, fmt.Errorf("не удалось найти ссылку: %w", err)
 	}
+	if link == nil {
+		logger.Warn("Ссылка не найдена", zap.Uint("id", linkID))
+		return nil, ErrLinkNotFound
+	}
 	logger.Info("Ссылка найдена по ID", zap.Uint("id", linkID), zap.String("hash", link.Hash))
 	return link, nil
 }
 
 // Block блокирует ссылку
 func (s *LinkService) Block(ctx context.Context, linkID uint) (*models.Link, error) {
-	link, err := s.Repo.FindLinkByID(ctx, linkID)
+	link, err := s.FindByID(ctx, linkID)
 	if err != nil {
 		return nil, err
 	}
-	if link == nil {
-		return nil, ErrLinkNotFound
-	}
 
+	before := *link
 	link.IsBlocked = true
 	updatedLink, err := s.Repo.BlockLink(ctx, link)
 	if err != nil {
 		logger.Error("Ошибка при блокировки ссылки", zap.Uint("id", linkID), zap.Error(err))
-		return nil, ErrLinkUpdate
+		return nil, common.ErrLinkBlockFailed.Wrap(err)
 	}
+	s.Audit.Record(ctx, AuditLinkBlock, models.AuditTargetLink, linkID, &before, updatedLink)
 
 	return updatedLink, nil
 }
 
 // UnBlock разблокирует ссылку
 func (s *LinkService) UnBlock(ctx context.Context, linkID uint) (*models.Link, error) {
-	link, err := s.Repo.FindLinkByID(ctx, linkID)
+	link, err := s.FindByID(ctx, linkID)
 	if err != nil {
 		return nil, err
 	}
-	if link == nil {
-		return nil, ErrLinkNotFound
-	}
 
+	before := *link
 	link.IsBlocked = false
 	updatedLink, err := s.Repo.UnBlockLink(ctx, link)
 	if err != nil {
 		logger.Error("Ошибка при снятии блокировки с ссылки", zap.Uint("id", linkID), zap.Error(err))
-		return nil, ErrLinkUpdate
+		return nil, common.ErrUnBlockFailed.Wrap(err)
 	}
+	s.Audit.Record(ctx, AuditLinkUnblock, models.AuditTargetLink, linkID, &before, updatedLink)
 
 	return updatedLink, nil
 }
@@ -188,3 +637,37 @@ func (s *LinkService) GetTotalLinks(ctx context.Context) (int64, error) {
 	logger.Info("Количество созданных ссылок получено", zap.Int64("count", count))
 	return count, nil
 }
+
+// Search возвращает страницу ссылок по фильтру вместе с числом кликов и тегами.
+func (s *LinkService) Search(ctx context.Context, filter payload.LinkListFilter, limit, offset int) ([]payload.LinkListItem, error) {
+	filter.Substring = s.SubstringSearch
+	items, err := s.Repo.SearchLinks(ctx, filter, limit, offset)
+	if err != nil {
+		logger.Error("Ошибка при поиске ссылок", zap.Error(err))
+		return nil, err
+	}
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	tags, err := s.Tags.GetLinkTags(ctx, ids)
+	if err != nil {
+		logger.Error("Ошибка получения тегов ссылок", zap.Error(err))
+		return nil, ErrTagsFailed.Wrap(err)
+	}
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+	}
+	return items, nil
+}
+
+// CountSearch возвращает количество ссылок, подходящих под фильтр.
+func (s *LinkService) CountSearch(ctx context.Context, filter payload.LinkListFilter) (int64, error) {
+	filter.Substring = s.SubstringSearch
+	count, err := s.Repo.CountSearchLinks(ctx, filter)
+	if err != nil {
+		logger.Error("Ошибка при подсчёте ссылок по фильтру", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
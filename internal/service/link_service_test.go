@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/repository"
+)
+
+func TestIsPlainLink(t *testing.T) {
+	id := uint(1)
+	now := time.Now()
+	if !isPlainLink(&models.Link{Url: "https://example.com", UserID: &id, ForwardQuery: models.QueryForwardOff}) {
+		t.Error("a link with only a URL and an owner is not plain")
+	}
+	options := map[string]models.Link{
+		"alias":          {Hash: "mine"},
+		"campaign":       {CampaignID: &id},
+		"utm":            {UTM: models.UTM{Source: "news"}},
+		"rules":          {Rules: models.TargetRules{{Url: "https://example.com/m"}}},
+		"variants":       {Variants: models.Variants{{Name: "A", Weight: 1}}},
+		"expiry":         {ExpiresAt: &now},
+		"folder":         {FolderID: &id},
+		"tags":           {Tags: []models.Tag{{Name: "a"}}},
+		"notes":          {Notes: "n"},
+		"forward query":  {ForwardQuery: models.QueryForwardMerge},
+		"forward path":   {ForwardPath: true},
+		"title":          {Title: "t"},
+		"description":    {Description: "d"},
+		"always preview": {AlwaysPreview: true},
+	}
+	for name, link := range options {
+		link.Url = "https://example.com"
+		if isPlainLink(&link) {
+			t.Errorf("a link with %s counts as plain and would be deduplicated", name)
+		}
+	}
+}
+
+// fakeOwnedRepo finds campaigns and folders only for their owner.
+type fakeOwnedRepo struct {
+	repository.CampaignRepo
+	repository.FolderRepo
+	owner uint
+}
+
+func (r *fakeOwnedRepo) FindCampaign(_ context.Context, id, ownerID uint) (*models.Campaign, error) {
+	if id != 1 || ownerID != r.owner {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Campaign{Name: "spring"}, nil
+}
+
+func (r *fakeOwnedRepo) FindFolder(_ context.Context, id, ownerID uint) (*models.Folder, error) {
+	if id != 1 || ownerID != r.owner {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.Folder{}, nil
+}
+
+func TestApplyCampaignAndFolderValidation(t *testing.T) {
+	owner, stranger, known, unknown := uint(1), uint(2), uint(1), uint(9)
+	repo := &fakeOwnedRepo{owner: owner}
+	s := &LinkService{Campaigns: repo, Folders: repo}
+	tests := []struct {
+		name    string
+		userID  *uint
+		id      *uint
+		wantErr bool
+	}{
+		{name: "own", userID: &owner, id: &known},
+		{name: "unknown", userID: &owner, id: &unknown, wantErr: true},
+		{name: "foreign", userID: &stranger, id: &known, wantErr: true},
+		{name: "anonymous", id: &known, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := func(field string, err error) {
+				t.Helper()
+				if !tt.wantErr {
+					if err != nil {
+						t.Errorf("%s: %v", field, err)
+					}
+					return
+				}
+				appErr := common.AsAppError(err)
+				if appErr.Kind != common.KindValidation {
+					t.Errorf("%s: kind = %v, want validation", field, appErr.Kind)
+				}
+				if len(appErr.Fields) != 1 || appErr.Fields[0].Field != field {
+					t.Errorf("%s: fields = %+v", field, appErr.Fields)
+				}
+			}
+			check("campaign_id", s.applyCampaign(context.Background(), &models.Link{UserID: tt.userID, CampaignID: tt.id}))
+			check("folder_id", s.applyFolder(context.Background(), &models.Link{UserID: tt.userID, FolderID: tt.id}))
+		})
+	}
+}
+
+// fakeSearchRepo records the filters the service searches with.
+type fakeSearchRepo struct {
+	repository.LinkRepo
+	repository.TagRepo
+	filters []payload.LinkListFilter
+}
+
+func (r *fakeSearchRepo) SearchLinks(_ context.Context, filter payload.LinkListFilter, _, _ int) ([]payload.LinkListItem, error) {
+	r.filters = append(r.filters, filter)
+	return nil, nil
+}
+
+func (r *fakeSearchRepo) CountSearchLinks(_ context.Context, filter payload.LinkListFilter) (int64, error) {
+	r.filters = append(r.filters, filter)
+	return 0, nil
+}
+
+func (r *fakeSearchRepo) GetLinkTags(context.Context, []uint) (map[uint][]models.Tag, error) {
+	return nil, nil
+}
+
+func TestSearchSubstringOptIn(t *testing.T) {
+	ctx := context.Background()
+	for _, enabled := range []bool{false, true} {
+		repo := &fakeSearchRepo{}
+		s := &LinkService{Repo: repo, Tags: repo, SubstringSearch: enabled}
+		// The caller cannot turn substring search on by itself.
+		filter := payload.LinkListFilter{Query: "promo", Substring: true}
+		if _, err := s.Search(ctx, filter, 20, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CountSearch(ctx, filter); err != nil {
+			t.Fatal(err)
+		}
+		for _, got := range repo.filters {
+			if got.Substring != enabled {
+				t.Errorf("SubstringSearch %v: repository searched with Substring %v", enabled, got.Substring)
+			}
+		}
+	}
+}
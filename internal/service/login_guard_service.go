@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+// Login guard errors
+var (
+	ErrLoginLocked     = common.ErrTooManyLoginAttempts
+	ErrLockoutNotFound = common.ErrLockoutNotFound
+)
+
+// LoginGuardService tracks failed sign-in attempts per account and per IP
+// and applies an exponentially growing lockout once a threshold is reached.
+type LoginGuardService struct {
+	Repo   repository.LockoutRepo
+	Config config.LoginGuardConfig
+
+	now func() time.Time
+}
+
+// NewLoginGuardService creates a new instance of LoginGuardService.
+func NewLoginGuardService(repo repository.LockoutRepo, cfg config.LoginGuardConfig) *LoginGuardService {
+	return &LoginGuardService{Repo: repo, Config: cfg, now: time.Now}
+}
+
+// Check returns ErrLoginLocked together with the remaining lockout time
+// if either the account or the IP address is currently locked.
+func (s *LoginGuardService) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := s.now()
+	var retryAfter time.Duration
+
+	for scope, key := range s.keys(email, ip) {
+		lockouts, err := s.Repo.GetLockouts(ctx, scope, key)
+		if err != nil {
+			return 0, err
+		}
+		for _, l := range lockouts {
+			if l.IsLocked(now) {
+				retryAfter = max(retryAfter, l.LockedUntil.Sub(now))
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, ErrLoginLocked
+	}
+	return 0, nil
+}
+
+// RegisterFailure records a failed attempt for the account and the IP address.
+// It returns the lockout duration if this attempt triggered a lockout.
+func (s *LoginGuardService) RegisterFailure(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := s.now()
+	var retryAfter time.Duration
+
+	for scope, key := range s.keys(email, ip) {
+		threshold := s.Config.MaxAccountAttempts
+		if scope == models.LockoutScopeIP {
+			threshold = s.Config.MaxIPAttempts
+		}
+
+		lockout, err := s.Repo.RegisterFailure(ctx, scope, key, func(l *models.LoginLockout) {
+			s.applyFailure(l, threshold, now)
+		})
+		if err != nil {
+			return 0, err
+		}
+		if lockout.IsLocked(now) {
+			logger.Warn("Sign-in locked after repeated failures",
+				zap.String("scope", string(scope)),
+				zap.Int("lockouts", lockout.Lockouts),
+				zap.Time("locked_until", *lockout.LockedUntil))
+			retryAfter = max(retryAfter, lockout.LockedUntil.Sub(now))
+		}
+	}
+	return retryAfter, nil
+}
+
+// RegisterSuccess clears the failure history of the account. The IP counter
+// is intentionally kept, otherwise an attacker could reset it by signing in
+// to an account of their own between guesses.
+func (s *LoginGuardService) RegisterSuccess(ctx context.Context, email string) error {
+	return s.Repo.ResetLockout(ctx, models.LockoutScopeAccount, normalizeEmail(email))
+}
+
+// GetActive returns a page of currently active lockouts.
+func (s *LoginGuardService) GetActive(ctx context.Context, limit, offset int) ([]models.LoginLockout, error) {
+	lockouts, err := s.Repo.GetActiveLockouts(ctx, s.now(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active lockouts: %w", err)
+	}
+	return lockouts, nil
+}
+
+// CountActive returns the number of currently active lockouts.
+func (s *LoginGuardService) CountActive(ctx context.Context) (int64, error) {
+	return s.Repo.CountActiveLockouts(ctx, s.now())
+}
+
+// Clear removes a lockout together with its failure history.
+func (s *LoginGuardService) Clear(ctx context.Context, id uint) error {
+	if err := s.Repo.DeleteLockout(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLockoutNotFound
+		}
+		return fmt.Errorf("failed to clear lockout: %w", err)
+	}
+	return nil
+}
+
+// applyFailure increments the failure counter and locks the record once
+// threshold failures happened within the configured window.
+func (s *LoginGuardService) applyFailure(l *models.LoginLockout, threshold int, now time.Time) {
+	if now.Sub(l.LastFailureAt) > s.Config.ResetAfter {
+		l.Lockouts = 0
+	}
+	if now.Sub(l.LastFailureAt) > s.Config.Window {
+		l.Failures = 0
+	}
+
+	l.Failures++
+	l.LastFailureAt = now
+
+	if threshold > 0 && l.Failures >= threshold {
+		l.Lockouts++
+		l.Failures = 0
+		until := now.Add(s.lockoutDuration(l.Lockouts))
+		l.LockedUntil = &until
+	}
+}
+
+// lockoutDuration returns BaseLockout doubled for every previous lockout, capped at MaxLockout.
+func (s *LoginGuardService) lockoutDuration(lockouts int) time.Duration {
+	d := s.Config.BaseLockout
+	for i := 1; i < lockouts && d < s.Config.MaxLockout; i++ {
+		d *= 2
+	}
+	return min(d, s.Config.MaxLockout)
+}
+
+// keys returns the lockout keys to track for an attempt.
+func (s *LoginGuardService) keys(email, ip string) map[models.LockoutScope]string {
+	keys := map[models.LockoutScope]string{
+		models.LockoutScopeAccount: normalizeEmail(email),
+	}
+	if ip != "" {
+		keys[models.LockoutScopeIP] = ip
+	}
+	return keys
+}
+
+// normalizeEmail brings an email to the form used as an account key.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
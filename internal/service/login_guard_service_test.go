@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+)
+
+// fakeLockoutRepo keeps lockouts in memory, keyed by scope and key.
+type fakeLockoutRepo struct {
+	lockouts map[models.LockoutScope]map[string]*models.LoginLockout
+}
+
+func newFakeLockoutRepo() *fakeLockoutRepo {
+	return &fakeLockoutRepo{lockouts: make(map[models.LockoutScope]map[string]*models.LoginLockout)}
+}
+
+func (r *fakeLockoutRepo) GetLockouts(_ context.Context, scope models.LockoutScope, keys ...string) ([]models.LoginLockout, error) {
+	var out []models.LoginLockout
+	for _, key := range keys {
+		if l, ok := r.lockouts[scope][key]; ok {
+			out = append(out, *l)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeLockoutRepo) RegisterFailure(_ context.Context, scope models.LockoutScope, key string, apply func(l *models.LoginLockout)) (*models.LoginLockout, error) {
+	if r.lockouts[scope] == nil {
+		r.lockouts[scope] = make(map[string]*models.LoginLockout)
+	}
+	l, ok := r.lockouts[scope][key]
+	if !ok {
+		l = &models.LoginLockout{ID: uint(len(r.lockouts[scope]) + 1), Scope: scope, Key: key}
+		r.lockouts[scope][key] = l
+	}
+	apply(l)
+	copied := *l
+	return &copied, nil
+}
+
+func (r *fakeLockoutRepo) ResetLockout(_ context.Context, scope models.LockoutScope, key string) error {
+	delete(r.lockouts[scope], key)
+	return nil
+}
+
+func (r *fakeLockoutRepo) GetActiveLockouts(_ context.Context, now time.Time, _, _ int) ([]models.LoginLockout, error) {
+	var out []models.LoginLockout
+	for _, byKey := range r.lockouts {
+		for _, l := range byKey {
+			if l.IsLocked(now) {
+				out = append(out, *l)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeLockoutRepo) CountActiveLockouts(ctx context.Context, now time.Time) (int64, error) {
+	active, _ := r.GetActiveLockouts(ctx, now, 0, 0)
+	return int64(len(active)), nil
+}
+
+func (r *fakeLockoutRepo) DeleteLockout(_ context.Context, id uint) error {
+	for _, byKey := range r.lockouts {
+		for key, l := range byKey {
+			if l.ID == id {
+				delete(byKey, key)
+				return nil
+			}
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// newTestLoginGuard returns a guard with a clock that the test moves by hand.
+func newTestLoginGuard() (*LoginGuardService, *fakeLockoutRepo, *time.Time) {
+	repo := newFakeLockoutRepo()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := NewLoginGuardService(repo, config.LoginGuardConfig{
+		MaxAccountAttempts: 3,
+		MaxIPAttempts:      5,
+		Window:             15 * time.Minute,
+		BaseLockout:        time.Minute,
+		MaxLockout:         5 * time.Minute,
+		ResetAfter:         time.Hour,
+	})
+	s.now = func() time.Time { return now }
+	return s, repo, &now
+}
+
+// failUntilLocked registers failures until one of them locks sign-in and
+// returns the lockout duration.
+func failUntilLocked(t *testing.T, s *LoginGuardService, email, ip string, attempts int) time.Duration {
+	t.Helper()
+	ctx := context.Background()
+	for i := 1; i <= attempts; i++ {
+		retryAfter, err := s.RegisterFailure(ctx, email, ip)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < attempts && retryAfter != 0 {
+			t.Fatalf("attempt %d locked for %v, want no lockout before %d attempts", i, retryAfter, attempts)
+		}
+		if i == attempts {
+			return retryAfter
+		}
+	}
+	return 0
+}
+
+func TestLoginGuardAccountBackoff(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestLoginGuard()
+
+	// Each lockout of the account doubles, up to MaxLockout.
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+		// A different IP each round, so that only the account counter locks.
+		ip := fmt.Sprintf("10.0.0.%d", i+1)
+		if got := failUntilLocked(t, s, " User@Example.com ", ip, 3); got != want {
+			t.Fatalf("lockout = %v, want %v", got, want)
+		}
+		retryAfter, err := s.Check(ctx, "user@example.com", "")
+		if !errors.Is(err, ErrLoginLocked) || retryAfter != want {
+			t.Fatalf("Check = %v, %v; want %v, ErrLoginLocked", retryAfter, err, want)
+		}
+		*now = now.Add(want)
+		if _, err := s.Check(ctx, "user@example.com", ""); err != nil {
+			t.Fatalf("Check after the lockout = %v, want nil", err)
+		}
+	}
+}
+
+func TestLoginGuardIPBackoff(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestLoginGuard()
+
+	// Different accounts from one IP lock the IP after MaxIPAttempts.
+	spray := func(round int) time.Duration {
+		t.Helper()
+		var retryAfter time.Duration
+		for i := 1; i <= 5; i++ {
+			var err error
+			retryAfter, err = s.RegisterFailure(ctx, fmt.Sprintf("user%d-%d@example.com", round, i), "203.0.113.7")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i < 5 && retryAfter != 0 {
+				t.Fatalf("attempt %d locked for %v", i, retryAfter)
+			}
+		}
+		return retryAfter
+	}
+	if got := spray(1); got != time.Minute {
+		t.Fatalf("first IP lockout = %v, want 1m", got)
+	}
+	if retryAfter, err := s.Check(ctx, "new@example.com", "203.0.113.7"); !errors.Is(err, ErrLoginLocked) || retryAfter != time.Minute {
+		t.Errorf("Check from the locked IP = %v, %v; want 1m, ErrLoginLocked", retryAfter, err)
+	}
+	if _, err := s.Check(ctx, "new@example.com", "198.51.100.1"); err != nil {
+		t.Errorf("Check from another IP = %v, want nil", err)
+	}
+
+	// A successful sign-in resets the account, not the IP.
+	*now = now.Add(time.Minute)
+	if err := s.RegisterSuccess(ctx, "user1-5@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if got := spray(2); got != 2*time.Minute {
+		t.Errorf("second IP lockout = %v, want 2m", got)
+	}
+}
+
+func TestLoginGuardWindowAndReset(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestLoginGuard()
+
+	// Failures older than Window are forgotten.
+	for i := 0; i < 2; i++ {
+		if _, err := s.RegisterFailure(ctx, "user@example.com", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*now = now.Add(16 * time.Minute)
+	if got := failUntilLocked(t, s, "user@example.com", "", 3); got != time.Minute {
+		t.Fatalf("first lockout = %v, want 1m", got)
+	}
+
+	// Within ResetAfter the next lockout escalates.
+	*now = now.Add(30 * time.Minute)
+	if got := failUntilLocked(t, s, "user@example.com", "", 3); got != 2*time.Minute {
+		t.Fatalf("second lockout = %v, want 2m", got)
+	}
+
+	// After ResetAfter without failures it starts over.
+	*now = now.Add(time.Hour + time.Second)
+	if got := failUntilLocked(t, s, "user@example.com", "", 3); got != time.Minute {
+		t.Errorf("lockout after ResetAfter = %v, want 1m", got)
+	}
+}
+
+func TestLoginGuardRetryAfterIsTheLongestLockout(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestLoginGuard()
+
+	// Lock the account twice so that its lockout is longer than the IP's.
+	failUntilLocked(t, s, "user@example.com", "", 3)
+	*now = now.Add(time.Minute)
+	failUntilLocked(t, s, "user@example.com", "", 3)
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		if _, err := s.RegisterFailure(ctx, email, "203.0.113.7"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	*now = now.Add(30 * time.Second)
+	retryAfter, err := s.Check(ctx, "user@example.com", "203.0.113.7")
+	if !errors.Is(err, ErrLoginLocked) || retryAfter != 90*time.Second {
+		t.Errorf("Check = %v, %v; want 1m30s, ErrLoginLocked", retryAfter, err)
+	}
+}
+
+func TestLoginGuardActiveAndClear(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestLoginGuard()
+	failUntilLocked(t, s, "user@example.com", "", 3)
+
+	active, err := s.GetActive(ctx, 10, 0)
+	if err != nil || len(active) != 1 {
+		t.Fatalf("GetActive = %v, %v; want one lockout", active, err)
+	}
+	if err := s.Clear(ctx, active[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Check(ctx, "user@example.com", ""); err != nil {
+		t.Errorf("Check after Clear = %v, want nil", err)
+	}
+	if err := s.Clear(ctx, active[0].ID); !errors.Is(err, ErrLockoutNotFound) {
+		t.Errorf("Clear(missing) = %v, want ErrLockoutNotFound", err)
+	}
+
+	failUntilLocked(t, s, "user@example.com", "", 3)
+	*now = now.Add(time.Minute)
+	if n, _ := s.CountActive(ctx); n != 0 {
+		t.Errorf("CountActive after the lockout = %d, want 0", n)
+	}
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"shorty/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/payload"
+	"shorty/pkg/logger"
+	"shorty/pkg/qrcode"
+)
+
+var (
+	ErrQRFailed      = common.ErrQRFailed
+	ErrQRLogoMissing = common.ErrQRLogoMissing
+)
+
+// QRServiceDeps - зависимости для создания экземпляра QRService.
+type QRServiceDeps struct {
+	Logo      image.Image // логотип для центра кода, nil если не настроен
+	CacheSize int         // сколько готовых картинок держать в памяти
+}
+
+// QRService рисует QR-коды ссылок и кеширует готовые картинки: код ссылки
+// меняется только вместе с её хешем, а запрашивают его часто.
+type QRService struct {
+	Logo  image.Image
+	cache *qrCache
+}
+
+// NewQRService создаёт новый экземпляр QRService.
+func NewQRService(deps *QRServiceDeps) *QRService {
+	return &QRService{Logo: deps.Logo, cache: newQRCache(deps.CacheSize)}
+}
+
+// Render возвращает картинку QR-кода с содержимым content. С логотипом
+// уровень коррекции ошибок поднимается до H, чтобы код читался под ним.
+func (s *QRService) Render(content string, req payload.QRRequest) (*payload.QRImage, error) {
+	if req.Logo && s.Logo == nil {
+		return nil, ErrQRLogoMissing
+	}
+	key := fmt.Sprintf("%s|%s|%d|%d|%s|%s|%s|%t", content, req.Format, req.Size, req.Margin, req.ECC, req.FG, req.BG, req.Logo)
+	if img, ok := s.cache.get(key); ok {
+		return img, nil
+	}
+
+	level, err := qrcode.ParseLevel(req.ECC)
+	if err != nil {
+		return nil, common.ErrInvalidParam.Wrap(err).WithField("ecc", "oneof", "L M Q H")
+	}
+	opts := qrcode.Options{Size: req.Size, Margin: req.Margin}
+	if opts.Foreground, err = parseColor(req.FG); err != nil {
+		return nil, common.ErrInvalidParam.Wrap(err).WithField("fg", "hexadecimal", "")
+	}
+	if opts.Background, err = parseColor(req.BG); err != nil {
+		return nil, common.ErrInvalidParam.Wrap(err).WithField("bg", "hexadecimal", "")
+	}
+	if req.Logo {
+		opts.Logo, level = s.Logo, qrcode.High
+	}
+
+	code, err := qrcode.Encode([]byte(content), level)
+	if err != nil {
+		logger.Error("Ошибка кодирования QR-кода", zap.String("content", content), zap.Error(err))
+		return nil, ErrQRFailed.Wrap(err)
+	}
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if req.Format == "svg" {
+		contentType = "image/svg+xml"
+		err = code.SVG(&buf, opts)
+	} else {
+		err = code.PNG(&buf, opts)
+	}
+	if err != nil {
+		logger.Error("Ошибка рисования QR-кода", zap.String("content", content), zap.Error(err))
+		return nil, ErrQRFailed.Wrap(err)
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	img := &payload.QRImage{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		ETag:        `"` + hex.EncodeToString(sum[:12]) + `"`,
+	}
+	s.cache.put(key, img)
+	return img, nil
+}
+
+// parseColor разбирает цвет "rrggbb"; пустая строка - цвет по умолчанию.
+func parseColor(s string) (color.Color, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return qrcode.ParseColor(s)
+}
+
+// qrCache - LRU-кеш готовых картинок.
+type qrCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // от недавно использованных к давним
+	items map[string]*list.Element
+}
+
+// qrCacheEntry - элемент qrCache.
+type qrCacheEntry struct {
+	key string
+	img *payload.QRImage
+}
+
+// newQRCache создаёт кеш на size картинок; при size <= 0 кеш ничего не хранит.
+func newQRCache(size int) *qrCache {
+	return &qrCache{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get возвращает картинку по ключу и отмечает её как недавно использованную.
+func (c *qrCache) get(key string) (*payload.QRImage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*qrCacheEntry).img, true
+}
+
+// put сохраняет картинку, вытесняя давно не использованные.
+func (c *qrCache) put(key string, img *payload.QRImage) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*qrCacheEntry).img = img
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&qrCacheEntry{key: key, img: img})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*qrCacheEntry).key)
+	}
+}
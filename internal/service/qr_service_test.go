@@ -0,0 +1,142 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"testing"
+
+	"shorty/internal/common"
+	"shorty/internal/payload"
+)
+
+func qrOptions() payload.QRRequest {
+	return payload.QRRequest{Format: "png", Size: 256, Margin: 4, ECC: "M"}
+}
+
+func TestQRServiceRenderCaches(t *testing.T) {
+	s := NewQRService(&QRServiceDeps{CacheSize: 8})
+	first, err := s.Render("https://sho.rt/abc?source=qr", qrOptions())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if first.ContentType != "image/png" || !bytes.HasPrefix(first.Data, []byte("\x89PNG")) {
+		t.Errorf("Render = %s, %.8q, want a PNG image", first.ContentType, first.Data)
+	}
+	again, err := s.Render("https://sho.rt/abc?source=qr", qrOptions())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if again != first {
+		t.Error("the same code was rendered twice instead of coming from the cache")
+	}
+}
+
+func TestQRServiceETag(t *testing.T) {
+	s := NewQRService(&QRServiceDeps{})
+	base, err := s.Render("https://sho.rt/abc", qrOptions())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if len(base.ETag) < 3 || base.ETag[0] != '"' || base.ETag[len(base.ETag)-1] != '"' {
+		t.Errorf("ETag %s is not a quoted string", base.ETag)
+	}
+
+	// Without the cache the same request still gets the same ETag.
+	same, err := s.Render("https://sho.rt/abc", qrOptions())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if same == base {
+		t.Error("a service without a cache returned a cached image")
+	}
+	if same.ETag != base.ETag {
+		t.Errorf("ETag changed between identical requests: %s and %s", base.ETag, same.ETag)
+	}
+
+	// Any change of the content or the options changes the ETag.
+	variants := map[string]func(*payload.QRRequest) string{
+		"content": func(*payload.QRRequest) string { return "https://sho.rt/abd" },
+		"format":  func(o *payload.QRRequest) string { o.Format = "svg"; return "" },
+		"size":    func(o *payload.QRRequest) string { o.Size = 512; return "" },
+		"margin":  func(o *payload.QRRequest) string { o.Margin = 2; return "" },
+		"ecc":     func(o *payload.QRRequest) string { o.ECC = "H"; return "" },
+		"fg":      func(o *payload.QRRequest) string { o.FG = "ff0000"; return "" },
+		"bg":      func(o *payload.QRRequest) string { o.BG = "eeeeee"; return "" },
+	}
+	for name, change := range variants {
+		opts := qrOptions()
+		content := "https://sho.rt/abc"
+		if c := change(&opts); c != "" {
+			content = c
+		}
+		img, err := s.Render(content, opts)
+		if err != nil {
+			t.Fatalf("Render with another %s: %v", name, err)
+		}
+		if img.ETag == base.ETag {
+			t.Errorf("changing the %s kept the ETag", name)
+		}
+	}
+}
+
+func TestQRServiceCacheEviction(t *testing.T) {
+	s := NewQRService(&QRServiceDeps{CacheSize: 2})
+	render := func(content string) *payload.QRImage {
+		t.Helper()
+		img, err := s.Render(content, qrOptions())
+		if err != nil {
+			t.Fatalf("Render(%s): %v", content, err)
+		}
+		return img
+	}
+	a := render("a")
+	b := render("b")
+	if render("a") != a {
+		t.Error("a was not cached")
+	}
+	render("c") // evicts b, the least recently used
+	if render("a") != a {
+		t.Error("a was evicted although it was used recently")
+	}
+	if render("b") == b {
+		t.Error("b was not evicted")
+	}
+}
+
+func TestQRServiceErrors(t *testing.T) {
+	s := NewQRService(&QRServiceDeps{})
+	opts := qrOptions()
+	opts.Logo = true
+	if _, err := s.Render("a", opts); !errors.Is(err, ErrQRLogoMissing) {
+		t.Errorf("logo without a configured logo: %v, want ErrQRLogoMissing", err)
+	}
+
+	opts = qrOptions()
+	opts.FG = "zzzzzz"
+	if _, err := s.Render("a", opts); !errors.Is(err, common.ErrInvalidParam) {
+		t.Errorf("invalid colour: %v, want ErrInvalidParam", err)
+	}
+
+	if _, err := s.Render(string(make([]byte, 3000)), qrOptions()); !errors.Is(err, ErrQRFailed) {
+		t.Errorf("content too long: %v, want ErrQRFailed", err)
+	}
+}
+
+func TestQRServiceLogo(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	s := NewQRService(&QRServiceDeps{Logo: logo})
+	opts := qrOptions()
+	opts.Logo = true
+	img, err := s.Render("https://sho.rt/abc", opts)
+	if err != nil {
+		t.Fatalf("Render with logo: %v", err)
+	}
+	plain, err := s.Render("https://sho.rt/abc", qrOptions())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if img.ETag == plain.ETag || bytes.Equal(img.Data, plain.Data) {
+		t.Error("the logo did not change the image")
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+// Report service errors
+var (
+	ErrReportSaveFailed = common.ErrReportFailed
+	ErrLinkHasNoOwner   = common.ErrLinkHasNoOwner
+)
+
+// ReportServiceDeps holds the dependencies of ReportService.
+type ReportServiceDeps struct {
+	Config      *config.Config
+	Repo        repository.ReportRepo
+	LinkService LinkServ
+	UserService UserServ
+}
+
+// ReportService handles abuse reports and the moderation queue.
+type ReportService struct {
+	Config      *config.Config
+	Repo        repository.ReportRepo
+	LinkService LinkServ
+	UserService UserServ
+}
+
+// NewReportService creates a new instance of ReportService.
+func NewReportService(deps *ReportServiceDeps) *ReportService {
+	return &ReportService{
+		Config:      deps.Config,
+		Repo:        deps.Repo,
+		LinkService: deps.LinkService,
+		UserService: deps.UserService,
+	}
+}
+
+// Report files a report for the link with the given hash. Once the number of
+// distinct reporters reaches the configured threshold the link is blocked and
+// its reports are flagged as auto-blocked. They stay open in the moderation
+// queue so that a moderator can confirm or dismiss the block.
+func (s *ReportService) Report(ctx context.Context, hash string, reason models.ReportReason, comment, reporterIP string) (*models.Report, error) {
+	link, err := s.LinkService.GetByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		LinkID:      link.ID,
+		Reason:      reason,
+		Comment:     comment,
+		ReporterKey: s.reporterKey(reporterIP),
+		Status:      models.ReportStatusOpen,
+	}
+	if err := s.Repo.SaveReport(ctx, report); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrReportSaveFailed, err)
+	}
+	logger.Info("Link reported", zap.Uint("linkID", link.ID), zap.String("reason", string(reason)))
+
+	threshold := s.Config.Report.AutoBlockThreshold
+	if threshold <= 0 {
+		return report, nil
+	}
+	reporters, err := s.Repo.CountOpenReporters(ctx, link.ID)
+	if err != nil {
+		// The report itself is saved; auto-blocking will be retried with the next one.
+		return report, nil
+	}
+	if reporters >= int64(threshold) {
+		if _, err := s.LinkService.Block(ctx, link.ID); err != nil {
+			logger.Error("Failed to auto-block reported link", zap.Uint("linkID", link.ID), zap.Error(err))
+			return report, nil
+		}
+		if err := s.Repo.MarkAutoBlocked(ctx, link.ID); err != nil {
+			// The link stays blocked; the moderator still sees it in the queue.
+			logger.Error("Failed to flag reports of auto-blocked link", zap.Uint("linkID", link.ID), zap.Error(err))
+		}
+		logger.Warn("Link auto-blocked after reports", zap.Uint("linkID", link.ID), zap.Int64("reporters", reporters))
+	}
+	return report, nil
+}
+
+// Queue returns a page of the moderation queue.
+func (s *ReportService) Queue(ctx context.Context, limit, offset int) ([]payload.ReportQueueItem, error) {
+	return s.Repo.GetReportQueue(ctx, limit, offset)
+}
+
+// CountQueue returns the number of links waiting for moderation.
+func (s *ReportService) CountQueue(ctx context.Context) (int64, error) {
+	return s.Repo.CountReportQueue(ctx)
+}
+
+// Dismiss closes the open reports of a link without taking action. If the
+// reports blocked the link automatically, the block is lifted.
+func (s *ReportService) Dismiss(ctx context.Context, linkID uint) error {
+	link, err := s.LinkService.FindByID(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	autoBlocked, err := s.Repo.IsAutoBlocked(ctx, linkID)
+	if err != nil {
+		return err
+	}
+	if autoBlocked && link.IsBlocked {
+		if _, err := s.LinkService.UnBlock(ctx, linkID); err != nil {
+			return err
+		}
+	}
+	_, err = s.Repo.ResolveReports(ctx, linkID, models.ReportStatusDismissed)
+	return err
+}
+
+// BlockLink blocks the reported link and closes its reports.
+func (s *ReportService) BlockLink(ctx context.Context, linkID uint) (*models.Link, error) {
+	link, err := s.LinkService.Block(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Repo.ResolveReports(ctx, linkID, models.ReportStatusActioned); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// BlockOwner blocks the owner of the reported link and closes its reports.
+func (s *ReportService) BlockOwner(ctx context.Context, linkID uint) (*models.User, error) {
+	link, err := s.LinkService.FindByID(ctx, linkID)
+	if err != nil {
+		return nil, err
+	}
+	if link.UserID == nil {
+		return nil, ErrLinkHasNoOwner
+	}
+	user, err := s.UserService.Block(ctx, *link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Repo.ResolveReports(ctx, linkID, models.ReportStatusActioned); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// reporterKey returns a keyed hash of the reporter's IP so that distinct
+// reporters can be counted without storing their addresses.
+func (s *ReportService) reporterKey(ip string) string {
+	mac := hmac.New(sha256.New, []byte(s.Config.Auth.Secret))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
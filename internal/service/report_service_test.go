@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/payload"
+)
+
+// fakeReportRepo keeps reports in memory and deduplicates open reports per
+// reporter the same way ReportRepository does.
+type fakeReportRepo struct {
+	reports  []*models.Report
+	markErr  error
+	resolved map[uint]models.ReportStatus
+}
+
+func newFakeReportRepo() *fakeReportRepo {
+	return &fakeReportRepo{resolved: make(map[uint]models.ReportStatus)}
+}
+
+func (r *fakeReportRepo) SaveReport(_ context.Context, report *models.Report) error {
+	for _, existing := range r.reports {
+		if existing.LinkID == report.LinkID && existing.ReporterKey == report.ReporterKey && existing.Status == models.ReportStatusOpen {
+			return nil
+		}
+	}
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *fakeReportRepo) CountOpenReporters(_ context.Context, linkID uint) (int64, error) {
+	keys := make(map[string]struct{})
+	for _, report := range r.open(linkID) {
+		keys[report.ReporterKey] = struct{}{}
+	}
+	return int64(len(keys)), nil
+}
+
+func (r *fakeReportRepo) GetReportQueue(context.Context, int, int) ([]payload.ReportQueueItem, error) {
+	return nil, nil
+}
+
+func (r *fakeReportRepo) CountReportQueue(context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeReportRepo) ResolveReports(_ context.Context, linkID uint, status models.ReportStatus) (int64, error) {
+	open := r.open(linkID)
+	for _, report := range open {
+		report.Status = status
+	}
+	r.resolved[linkID] = status
+	return int64(len(open)), nil
+}
+
+func (r *fakeReportRepo) MarkAutoBlocked(_ context.Context, linkID uint) error {
+	if r.markErr != nil {
+		return r.markErr
+	}
+	for _, report := range r.open(linkID) {
+		report.AutoBlocked = true
+	}
+	return nil
+}
+
+func (r *fakeReportRepo) IsAutoBlocked(_ context.Context, linkID uint) (bool, error) {
+	for _, report := range r.open(linkID) {
+		if report.AutoBlocked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeReportRepo) open(linkID uint) []*models.Report {
+	var open []*models.Report
+	for _, report := range r.reports {
+		if report.LinkID == linkID && report.Status == models.ReportStatusOpen {
+			open = append(open, report)
+		}
+	}
+	return open
+}
+
+// fakeReportLinks serves a single link and records blocks.
+type fakeReportLinks struct {
+	LinkServ
+	link *models.Link
+}
+
+func (s *fakeReportLinks) GetByHash(_ context.Context, hash string) (*models.Link, error) {
+	if hash != s.link.Hash {
+		return nil, ErrLinkNotFound
+	}
+	return s.link, nil
+}
+
+func (s *fakeReportLinks) FindByID(_ context.Context, id uint) (*models.Link, error) {
+	if id != s.link.ID {
+		return nil, ErrLinkNotFound
+	}
+	return s.link, nil
+}
+
+func (s *fakeReportLinks) Block(_ context.Context, id uint) (*models.Link, error) {
+	s.link.IsBlocked = true
+	return s.link, nil
+}
+
+func (s *fakeReportLinks) UnBlock(_ context.Context, id uint) (*models.Link, error) {
+	s.link.IsBlocked = false
+	return s.link, nil
+}
+
+// fakeReportUsers records which users were blocked.
+type fakeReportUsers struct {
+	UserServ
+	blocked []uint
+}
+
+func (s *fakeReportUsers) Block(_ context.Context, id uint) (*models.User, error) {
+	s.blocked = append(s.blocked, id)
+	return &models.User{ID: id, IsBlocked: true}, nil
+}
+
+func newTestReportService(threshold int, link *models.Link) (*ReportService, *fakeReportRepo, *fakeReportUsers) {
+	repo := newFakeReportRepo()
+	users := &fakeReportUsers{}
+	s := NewReportService(&ReportServiceDeps{
+		Config: &config.Config{
+			Auth:   config.AuthConfig{Secret: "secret"},
+			Report: config.ReportConfig{AutoBlockThreshold: threshold},
+		},
+		Repo:        repo,
+		LinkService: &fakeReportLinks{link: link},
+		UserService: users,
+	})
+	return s, repo, users
+}
+
+func TestReportAutoBlockThreshold(t *testing.T) {
+	ctx := context.Background()
+	link := &models.Link{Hash: "abc"}
+	link.ID = 1
+	s, repo, _ := newTestReportService(3, link)
+
+	// The same reporter counts once, however often they report.
+	for i := 0; i < 5; i++ {
+		if _, err := s.Report(ctx, "abc", models.ReportReasonSpam, "", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := repo.CountOpenReporters(ctx, link.ID); n != 1 {
+		t.Fatalf("distinct reporters = %d, want 1", n)
+	}
+	if _, err := s.Report(ctx, "abc", models.ReportReasonPhishing, "", "10.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+	if link.IsBlocked {
+		t.Fatal("link blocked below the threshold")
+	}
+
+	if _, err := s.Report(ctx, "abc", models.ReportReasonPhishing, "", "10.0.0.3"); err != nil {
+		t.Fatal(err)
+	}
+	if !link.IsBlocked {
+		t.Fatal("link not blocked at the threshold")
+	}
+	// The reports wait for a moderator instead of being resolved.
+	open := repo.open(link.ID)
+	if len(open) != 3 {
+		t.Fatalf("open reports = %d, want 3", len(open))
+	}
+	for _, report := range open {
+		if !report.AutoBlocked {
+			t.Error("open report not flagged as auto-blocked")
+		}
+	}
+}
+
+func TestReportAutoBlockDisabled(t *testing.T) {
+	ctx := context.Background()
+	link := &models.Link{Hash: "abc"}
+	link.ID = 1
+	s, _, _ := newTestReportService(0, link)
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if _, err := s.Report(ctx, "abc", models.ReportReasonSpam, "", ip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if link.IsBlocked {
+		t.Error("link blocked with auto-blocking disabled")
+	}
+}
+
+func TestReportAutoBlockFlagError(t *testing.T) {
+	ctx := context.Background()
+	link := &models.Link{Hash: "abc"}
+	link.ID = 1
+	s, repo, _ := newTestReportService(1, link)
+	repo.markErr = errors.New("connection reset")
+
+	report, err := s.Report(ctx, "abc", models.ReportReasonMalware, "", "10.0.0.1")
+	if err != nil || report == nil {
+		t.Fatalf("Report() = %v, %v; the saved report should be returned", report, err)
+	}
+	if !link.IsBlocked {
+		t.Error("link not blocked")
+	}
+	if report.Status != models.ReportStatusOpen {
+		t.Errorf("report status = %q, want open", report.Status)
+	}
+}
+
+func TestReportDismiss(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		threshold   int
+		blocked     bool
+		wantBlocked bool
+	}{
+		{name: "auto-blocked link is unblocked", threshold: 1, wantBlocked: false},
+		{name: "open reports only", threshold: 0, wantBlocked: false},
+		{name: "manual block is kept", threshold: 0, blocked: true, wantBlocked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := &models.Link{Hash: "abc", IsBlocked: tt.blocked}
+			link.ID = 1
+			s, repo, _ := newTestReportService(tt.threshold, link)
+			if _, err := s.Report(ctx, "abc", models.ReportReasonSpam, "", "10.0.0.1"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.Dismiss(ctx, link.ID); err != nil {
+				t.Fatal(err)
+			}
+			if link.IsBlocked != tt.wantBlocked {
+				t.Errorf("blocked = %v, want %v", link.IsBlocked, tt.wantBlocked)
+			}
+			if repo.resolved[link.ID] != models.ReportStatusDismissed || len(repo.open(link.ID)) != 0 {
+				t.Errorf("reports not dismissed: %v", repo.resolved)
+			}
+		})
+	}
+
+	link := &models.Link{Hash: "abc"}
+	link.ID = 1
+	s, _, _ := newTestReportService(0, link)
+	if err := s.Dismiss(ctx, 2); !errors.Is(err, ErrLinkNotFound) {
+		t.Errorf("Dismiss(unknown) = %v, want ErrLinkNotFound", err)
+	}
+}
+
+func TestReportBlockOwner(t *testing.T) {
+	ctx := context.Background()
+	owner := uint(7)
+	link := &models.Link{Hash: "abc", UserID: &owner}
+	link.ID = 1
+	s, repo, users := newTestReportService(0, link)
+	if _, err := s.Report(ctx, "abc", models.ReportReasonSpam, "", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := s.BlockOwner(ctx, link.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != owner || len(users.blocked) != 1 || users.blocked[0] != owner {
+		t.Errorf("blocked users = %v, want [%d]", users.blocked, owner)
+	}
+	if repo.resolved[link.ID] != models.ReportStatusActioned {
+		t.Errorf("reports resolved as %q, want actioned", repo.resolved[link.ID])
+	}
+
+	anonymous := &models.Link{Hash: "anon"}
+	anonymous.ID = 2
+	s, _, users = newTestReportService(0, anonymous)
+	if _, err := s.BlockOwner(ctx, anonymous.ID); !errors.Is(err, ErrLinkHasNoOwner) {
+		t.Errorf("BlockOwner(no owner) = %v, want ErrLinkHasNoOwner", err)
+	}
+	if len(users.blocked) != 0 {
+		t.Errorf("blocked users = %v, want none", users.blocked)
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+)
+
+// Session service errors
+var (
+	ErrSessionNotFound = common.ErrSessionNotFound
+	ErrSessionExpired  = common.ErrSessionExpired
+)
+
+// sessionTouchInterval limits how often the last activity time is written.
+const sessionTouchInterval = time.Minute
+
+// SessionService manages server-side sessions of the web UI.
+type SessionService struct {
+	Repo     repository.SessionRepo
+	UserRepo repository.UserRepo
+	Config   config.SessionConfig
+}
+
+// NewSessionService creates a new instance of SessionService and starts
+// the periodic removal of expired sessions.
+func NewSessionService(repo repository.SessionRepo, userRepo repository.UserRepo, cfg config.SessionConfig) *SessionService {
+	service := &SessionService{Repo: repo, UserRepo: userRepo, Config: cfg}
+	go service.purgeExpired(context.Background())
+	return service
+}
+
+// Create starts a new session for the user. It returns the token to be put
+// into the session cookie; only its hash is stored.
+func (s *SessionService) Create(ctx context.Context, user *models.User, ip, userAgent string) (string, *models.Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:         hashToken(token),
+		UserID:     user.ID,
+		CSRFToken:  csrfToken,
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.Config.AbsoluteTimeout),
+	}
+	if err := s.Repo.CreateSession(ctx, session); err != nil {
+		return "", nil, err
+	}
+
+	logger.Info("Session created", zap.Uint("userID", user.ID))
+	return token, session, nil
+}
+
+// Resolve returns the session for a cookie token together with its user.
+// Expired sessions and sessions of blocked or deleted users are destroyed.
+func (s *SessionService) Resolve(ctx context.Context, token string) (*models.Session, *models.User, error) {
+	id := hashToken(token)
+	session, err := s.Repo.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSessionNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	now := time.Now()
+	if !now.Before(session.ExpiresAt) || now.Sub(session.LastSeenAt) >= s.Config.IdleTimeout {
+		_ = s.Repo.DeleteSession(ctx, id)
+		return nil, nil, ErrSessionExpired
+	}
+
+	// Only a deleted or blocked user ends the session: a transient database
+	// error must not log the user out.
+	user, err := s.UserRepo.GetUserByID(ctx, session.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, fmt.Errorf("failed to get session user: %w", err)
+	}
+	if err != nil || user.IsBlocked {
+		_ = s.Repo.DeleteSession(ctx, id)
+		return nil, nil, ErrSessionNotFound
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := s.Repo.TouchSession(ctx, id, now); err == nil {
+			session.LastSeenAt = now
+		}
+	}
+	return session, user, nil
+}
+
+// Destroy ends the session identified by a cookie token.
+func (s *SessionService) Destroy(ctx context.Context, token string) error {
+	return s.Repo.DeleteSession(ctx, hashToken(token))
+}
+
+// purgeExpired periodically removes expired sessions from the storage.
+func (s *SessionService) purgeExpired(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			count, err := s.Repo.DeleteExpiredSessions(ctx, now, now.Add(-s.Config.IdleTimeout))
+			if err != nil {
+				continue
+			}
+			logger.Debug("Expired sessions removed", zap.Int64("count", count))
+		}
+	}
+}
+
+// randomToken returns a URL-safe random token with 256 bits of entropy.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the storage ID of a session token.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/internal/repository"
+)
+
+// fakeSessionRepo keeps sessions in memory.
+type fakeSessionRepo struct {
+	sessions map[string]*models.Session
+}
+
+func (r *fakeSessionRepo) CreateSession(_ context.Context, session *models.Session) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepo) GetSession(_ context.Context, id string) (*models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return session, nil
+}
+
+func (r *fakeSessionRepo) TouchSession(_ context.Context, id string, seenAt time.Time) error {
+	r.sessions[id].LastSeenAt = seenAt
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteSession(_ context.Context, id string) error {
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteExpiredSessions(context.Context, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakeUserRepo answers GetUserByID with a fixed user or error.
+type fakeUserRepo struct {
+	repository.UserRepo
+	user *models.User
+	err  error
+}
+
+func (r *fakeUserRepo) GetUserByID(context.Context, uint) (*models.User, error) {
+	return r.user, r.err
+}
+
+func TestSessionResolveUserLookup(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        *models.User
+		err         error
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "active user", user: &models.User{}},
+		{name: "blocked user", user: &models.User{IsBlocked: true}, wantErr: ErrSessionNotFound, wantDeleted: true},
+		{name: "deleted user", err: gorm.ErrRecordNotFound, wantErr: ErrSessionNotFound, wantDeleted: true},
+		{name: "database error", err: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessionRepo{sessions: make(map[string]*models.Session)}
+			s := &SessionService{
+				Repo:     sessions,
+				UserRepo: &fakeUserRepo{user: tt.user, err: tt.err},
+				Config:   config.SessionConfig{AbsoluteTimeout: time.Hour, IdleTimeout: time.Hour},
+			}
+			token, _, err := s.Create(context.Background(), &models.User{}, "", "")
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			_, user, err := s.Resolve(context.Background(), token)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("Resolve error = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && tt.err != nil && !errors.Is(err, tt.err):
+				t.Errorf("Resolve error = %v, want the database error", err)
+			case tt.err == nil && tt.wantErr == nil && (err != nil || user != tt.user):
+				t.Errorf("Resolve = %v, %v, want the user", user, err)
+			}
+			if deleted := len(sessions.sessions) == 0; deleted != tt.wantDeleted {
+				t.Errorf("session deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
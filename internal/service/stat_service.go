@@ -6,51 +6,84 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
+	"shorty/internal/common"
+	"shorty/internal/models"
 	"shorty/internal/payload"
 	"shorty/internal/repository"
 	"shorty/pkg/event"
 	"shorty/pkg/logger"
 )
 
-var ErrInvalidLinkID = errors.New("неверный идентификатор ссылки")
+var (
+	ErrInvalidLinkID    = common.ErrInvalidID
+	ErrStatsFailed      = common.ErrStatsFailed
+	ErrConversionFailed = common.ErrConversionFailed
+	ErrClickNotFound    = common.ErrClickNotFound
+)
 
 type StatServiceDeps struct {
 	Repo     repository.StatRepo
 	EventBus *event.EventBus
+	// AttributionWindow - сколько после перехода конверсия засчитывается ему.
+	AttributionWindow time.Duration
 }
 
 // StatService предоставляет методы для работы с статистикой.
 type StatService struct {
-	Repo     repository.StatRepo
-	EventBus *event.EventBus
+	Repo              repository.StatRepo
+	EventBus          *event.EventBus
+	AttributionWindow time.Duration
 }
 
 // NewUserService создаёт новый экземпляр StatService.
 func NewStatService(deps *StatServiceDeps) *StatService {
 	ctx := context.Background()
-	service := &StatService{Repo: deps.Repo, EventBus: deps.EventBus}
+	service := &StatService{Repo: deps.Repo, EventBus: deps.EventBus, AttributionWindow: deps.AttributionWindow}
 	go service.AddClick(ctx)
 	return service
 }
 
+// AddClick читает события о переходах и записывает клик в дневную статистику
+// и отдельную запись о переходе с пробрасываемыми параметрами.
 func (s *StatService) AddClick(ctx context.Context) {
 	for msg := range s.EventBus.Subscribe() {
 		if msg.Type == event.EventLinkVisited {
-			linkID, ok := msg.Data.(uint)
+			click, ok := msg.Data.(payload.ClickEvent)
 			if !ok {
 				logger.Error("Неверные данные при получении события EventLinkVisited", zap.Any("data", msg.Data))
 				continue
 			}
-			if err := s.Repo.AddClick(context.Background(), linkID); err != nil {
-				logger.Error("Ошибка при добавлении клика", zap.Uint("linkID", linkID), zap.Error(err))
-			} else {
-				logger.Info("Добавлен клик", zap.Uint("linkID", linkID))
+			if err := s.Repo.AddClick(context.Background(), click.LinkID); err != nil {
+				logger.Error("Ошибка при добавлении клика", zap.Uint("linkID", click.LinkID), zap.Error(err))
+				continue
+			}
+			if err := s.Repo.SaveClick(context.Background(), newClick(click)); err != nil {
+				logger.Error("Ошибка при записи перехода", zap.Uint("linkID", click.LinkID), zap.Error(err))
+				continue
 			}
+			logger.Info("Добавлен клик", zap.Uint("linkID", click.LinkID))
 		}
 	}
 }
 
+// newClick собирает запись о переходе из события.
+func newClick(e payload.ClickEvent) *models.Click {
+	return &models.Click{
+		LinkID:         e.LinkID,
+		ClickID:        e.ClickID,
+		CreatedAt:      e.At,
+		Referrer:       e.Referrer,
+		UserAgent:      e.UserAgent,
+		ForwardedQuery: e.ForwardedQuery.Encode(),
+		ForwardedPath:  e.ForwardedPath,
+		MatchedRule:    e.MatchedRule,
+		Variant:        e.Variant,
+		Source:         e.Source,
+	}
+}
+
 // GetClickedLinkStats метод для получения статистики.
 func (s *StatService) GetClickedLinkStats(ctx context.Context, by string, from, to time.Time) []payload.GetStatsResponse {
 	logger.Info("Запрос статистики", zap.String("by", by), zap.Time("from", from), zap.Time("to", to))
@@ -59,8 +92,129 @@ func (s *StatService) GetClickedLinkStats(ctx context.Context, by string, from,
 	return stats
 }
 
-func (s *StatService) GetAllLinksStats(ctx context.Context, from, to time.Time) []payload.LinkStatsResponse {
-	logger.Info("Запрос статистики по всем ссылкам", zap.Time("from", from), zap.Time("to", to))
-	stats := s.Repo.GetAllLinksStats(ctx, from, to)
+// GetAllLinksStats возвращает статистику по ссылкам за период, при необходимости только по одной кампании.
+func (s *StatService) GetAllLinksStats(ctx context.Context, filter payload.LinkStatsFilter) []payload.LinkStatsResponse {
+	logger.Info("Запрос статистики по всем ссылкам", zap.Time("from", filter.From), zap.Time("to", filter.To))
+	stats := s.Repo.GetAllLinksStats(ctx, filter)
 	return stats
 }
+
+// CampaignStats возвращает сводную статистику кампаний за период.
+// Без ownerID возвращаются кампании всех владельцев.
+func (s *StatService) CampaignStats(ctx context.Context, ownerID *uint, from, to time.Time) ([]payload.CampaignStatsResponse, error) {
+	stats, err := s.Repo.GetCampaignStats(ctx, ownerID, from, to)
+	if err != nil {
+		return nil, ErrStatsFailed.Wrap(err)
+	}
+	return stats, nil
+}
+
+// RecordConversion записывает конверсию по переходу с ClickID, если он был
+// не раньше окна атрибуции. Ссылка и вариант берутся из перехода, а повторная
+// конверсия по нему не записывается. Неизвестный переход, переход за окном
+// атрибуции или по другой ссылке, чем LinkID, дают ErrClickNotFound.
+func (s *StatService) RecordConversion(ctx context.Context, conversion *models.Conversion) error {
+	if conversion.ClickID == "" {
+		return ErrClickNotFound
+	}
+	click, err := s.attributedClick(ctx, conversion.ClickID)
+	if err != nil {
+		return err
+	}
+	if click == nil || (conversion.LinkID != 0 && conversion.LinkID != click.LinkID) {
+		return ErrClickNotFound
+	}
+	converted, err := s.Repo.ClickConverted(ctx, click.ClickID)
+	if err != nil {
+		return ErrConversionFailed.Wrap(err)
+	}
+	if converted {
+		logger.Info("Повторная конверсия по переходу", zap.String("clickID", click.ClickID))
+		return nil
+	}
+	conversion.LinkID, conversion.Variant = click.LinkID, click.Variant
+	if err := s.Repo.SaveConversion(ctx, conversion); err != nil {
+		return ErrConversionFailed.Wrap(err)
+	}
+	logger.Info("Добавлена конверсия", zap.Uint("linkID", conversion.LinkID), zap.String("variant", conversion.Variant))
+	return nil
+}
+
+// attributedClick ищет переход по ID; nil, если его нет или окно атрибуции прошло.
+func (s *StatService) attributedClick(ctx context.Context, clickID string) (*models.Click, error) {
+	click, err := s.Repo.FindClick(ctx, clickID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, ErrConversionFailed.Wrap(err)
+	}
+	if s.AttributionWindow > 0 && time.Since(click.CreatedAt) > s.AttributionWindow {
+		return nil, nil
+	}
+	return click, nil
+}
+
+// VariantStats возвращает переходы, конверсии и долю конверсий для каждого
+// варианта A/B-теста ссылки, в порядке вариантов ссылки. Варианты, удалённые
+// из ссылки, но успевшие получить переходы, идут в конце.
+func (s *StatService) VariantStats(ctx context.Context, link *models.Link) ([]payload.VariantStatsResponse, error) {
+	clicks, err := s.Repo.CountVariantClicks(ctx, link.ID)
+	if err != nil {
+		return nil, ErrStatsFailed.Wrap(err)
+	}
+	conversions, err := s.Repo.CountVariantConversions(ctx, link.ID)
+	if err != nil {
+		return nil, ErrStatsFailed.Wrap(err)
+	}
+
+	stats := make([]payload.VariantStatsResponse, 0, len(link.Variants))
+	index := make(map[string]int, len(link.Variants))
+	row := func(variant string) *payload.VariantStatsResponse {
+		i, ok := index[variant]
+		if !ok {
+			i = len(stats)
+			index[variant] = i
+			stats = append(stats, payload.VariantStatsResponse{Variant: variant})
+		}
+		return &stats[i]
+	}
+	for _, v := range link.Variants {
+		row(v.Name).Weight = v.Weight
+	}
+	for _, c := range clicks {
+		row(c.Variant).Clicks = c.Count
+	}
+	for _, c := range conversions {
+		row(c.Variant).Conversions = c.Count
+	}
+	for i := range stats {
+		if stats[i].Clicks > 0 {
+			stats[i].ConversionRate = float64(stats[i].Conversions) / float64(stats[i].Clicks)
+		}
+	}
+	return stats, nil
+}
+
+// DailyClicks возвращает клики по дням за последние days дней для каждой
+// ссылки, от старых к новым. Дни без кликов заполняются нулями.
+func (s *StatService) DailyClicks(ctx context.Context, linkIDs []uint, days int) (map[uint][]int64, error) {
+	today := time.Now().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(days - 1))
+	rows, err := s.Repo.GetDailyClicks(ctx, linkIDs, from, today)
+	if err != nil {
+		return nil, err
+	}
+
+	series := make(map[uint][]int64, len(linkIDs))
+	for _, id := range linkIDs {
+		series[id] = make([]int64, days)
+	}
+	for _, row := range rows {
+		day := int(row.Date.Truncate(24*time.Hour).Sub(from) / (24 * time.Hour))
+		if values, ok := series[row.LinkID]; ok && day >= 0 && day < days {
+			values[day] += row.Clicks
+		}
+	}
+	return series, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/models"
+	"shorty/internal/repository"
+	"shorty/pkg/logger"
+	"shorty/pkg/urlpolicy"
+)
+
+// ErrURLRejected is returned when a destination URL is refused by the URL policy.
+// The returned error also wraps the *urlpolicy.Violation with the reason.
+var ErrURLRejected = common.ErrURLRejected
+
+// URLPolicyServiceDeps holds the dependencies of URLPolicyService.
+type URLPolicyServiceDeps struct {
+	Policy   *urlpolicy.Policy
+	Resolver *urlpolicy.Resolver // nil disables following redirects
+	Repo     repository.URLRejectionRepo
+	Audit    AuditServ
+}
+
+// URLPolicyService checks destination URLs, follows their redirects,
+// keeps a log of rejected ones for moderation and manages the domain
+// block- and allowlists.
+type URLPolicyService struct {
+	Policy   *urlpolicy.Policy
+	Resolver *urlpolicy.Resolver
+	Repo     repository.URLRejectionRepo
+	Audit    AuditServ
+}
+
+// NewURLPolicyService creates a new instance of URLPolicyService.
+func NewURLPolicyService(deps *URLPolicyServiceDeps) *URLPolicyService {
+	return &URLPolicyService{Policy: deps.Policy, Resolver: deps.Resolver, Repo: deps.Repo, Audit: deps.Audit}
+}
+
+// Check validates rawURL against the policy. A rejected URL is logged
+// together with the user who tried to shorten it.
+func (s *URLPolicyService) Check(ctx context.Context, rawURL string, userID *uint) error {
+	return s.reject(ctx, rawURL, userID, s.Policy.Check(ctx, rawURL))
+}
+
+// Resolve follows the redirects of rawURL and returns the final URL, which
+// is checked against the policy as well. It returns an empty string when
+// the resolver is disabled. A destination that cannot be reached is not
+// rejected: the last URL reached is returned instead.
+func (s *URLPolicyService) Resolve(ctx context.Context, rawURL string, userID *uint) (string, error) {
+	if s.Resolver == nil {
+		return "", nil
+	}
+
+	finalURL, err := s.Resolver.Resolve(ctx, rawURL)
+	var v *urlpolicy.Violation
+	if errors.As(err, &v) {
+		return "", s.reject(ctx, rawURL, userID, v)
+	}
+	if err != nil {
+		logger.Warn("Failed to resolve redirects", zap.String("url", rawURL), zap.Error(err))
+	}
+	if finalURL == rawURL {
+		return finalURL, nil
+	}
+	if err := s.Check(ctx, finalURL, userID); err != nil {
+		return "", err
+	}
+	return finalURL, nil
+}
+
+// reject logs a policy violation and wraps it into ErrURLRejected.
+// Errors other than *urlpolicy.Violation are returned unchanged.
+func (s *URLPolicyService) reject(ctx context.Context, rawURL string, userID *uint, err error) error {
+	var v *urlpolicy.Violation
+	if !errors.As(err, &v) {
+		return err
+	}
+
+	rejection := &models.URLRejection{
+		URL:    rawURL,
+		Reason: v.Reason,
+		Detail: v.Detail,
+		UserID: userID,
+		IP:     common.ClientIPFromContext(ctx),
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		rejection.Host = strings.ToLower(u.Hostname())
+	}
+	// The rejection stands even if it cannot be logged.
+	if err := s.Repo.CreateURLRejection(context.WithoutCancel(ctx), rejection); err != nil {
+		logger.Error("URL rejection was not logged", zap.String("url", rawURL), zap.Error(err))
+	}
+	logger.Warn("URL rejected by policy", zap.String("url", rawURL), zap.String("reason", v.Reason))
+	return ErrURLRejected.WithField("url", v.Reason, "").Wrap(v)
+}
+
+// Domains returns the domains on a list.
+func (s *URLPolicyService) Domains(list urlpolicy.List) ([]string, error) {
+	domains, err := s.Policy.Domains(list)
+	if err != nil {
+		return nil, domainListError(err)
+	}
+	return domains, nil
+}
+
+// AddDomain adds a domain to a list.
+func (s *URLPolicyService) AddDomain(ctx context.Context, list urlpolicy.List, domain string) (string, error) {
+	added, err := s.Policy.AddDomain(list, domain)
+	if err != nil {
+		return "", domainListError(err)
+	}
+	s.Audit.Record(ctx, AuditURLPolicyAdd, models.AuditTargetURLPolicy, 0, nil, map[string]string{
+		"list":   string(list),
+		"domain": added,
+	})
+	return added, nil
+}
+
+// RemoveDomain removes a domain from a list.
+func (s *URLPolicyService) RemoveDomain(ctx context.Context, list urlpolicy.List, domain string) error {
+	domain, err := urlpolicy.NormalizeDomain(domain)
+	if err != nil {
+		return domainListError(err)
+	}
+	if err := s.Policy.RemoveDomain(list, domain); err != nil {
+		return domainListError(err)
+	}
+	s.Audit.Record(ctx, AuditURLPolicyRemove, models.AuditTargetURLPolicy, 0, map[string]string{
+		"list":   string(list),
+		"domain": domain,
+	}, nil)
+	return nil
+}
+
+// domainListError maps urlpolicy list errors to application errors.
+func domainListError(err error) error {
+	switch {
+	case errors.Is(err, urlpolicy.ErrUnknownList):
+		return common.ErrUnknownDomainList.Wrap(err)
+	case errors.Is(err, urlpolicy.ErrInvalidDomain):
+		return common.ErrInvalidDomain.WithField("domain", "invalid_domain", "").Wrap(err)
+	default:
+		return common.ErrInternal.Wrap(err)
+	}
+}
+
+// Rejections returns a page of rejected URLs, newest first.
+func (s *URLPolicyService) Rejections(ctx context.Context, limit, offset int) ([]models.URLRejection, error) {
+	return s.Repo.GetURLRejections(ctx, limit, offset)
+}
+
+// CountRejections returns the number of rejected URLs.
+func (s *URLPolicyService) CountRejections(ctx context.Context) (int64, error) {
+	return s.Repo.CountURLRejections(ctx)
+}
@@ -8,32 +8,36 @@ import (
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"shorty/internal/common"
 	"shorty/internal/models"
 	"shorty/internal/repository"
+	"shorty/pkg/i18n"
 	"shorty/pkg/logger"
 )
 
 // User service errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUsersFetchFailed   = errors.New("failed to fetch users")
-	ErrUserUpdateFailed   = errors.New("failed to update user")
-	ErrUserDeleteFailed   = errors.New("failed to delete user")
-	ErrUserBlockFailed    = errors.New("failed to block user")
-	ErrUserUnblockFailed  = errors.New("failed to unblock user")
-	ErrUserAlreadyBlocked = errors.New("user is already blocked")
-	ErrUserNotBlocked     = errors.New("user is not blocked")
-	ErrInvalidUserData    = errors.New("invalid user data")
+	ErrUserNotFound       = common.ErrUserNotFound
+	ErrUsersFetchFailed   = common.ErrorGetUsers
+	ErrUserUpdateFailed   = common.ErrUserUpdateFailed
+	ErrUserDeleteFailed   = common.ErrUserDeleteFailed
+	ErrUserBlockFailed    = common.ErrUserBlockFailed
+	ErrUserUnblockFailed  = common.ErrUserUnblockFailed
+	ErrUserAlreadyBlocked = common.ErrUserAlreadyBlocked
+	ErrUserNotBlocked     = common.ErrUserNotBlocked
+	ErrInvalidUserData    = common.ErrInvalidUserData
 )
 
 // UserService provides methods for working with users.
+// Changes made by administrators are written to the audit log.
 type UserService struct {
-	Repo repository.UserRepo
+	Repo  repository.UserRepo
+	Audit AuditServ
 }
 
 // NewUserService creates a new instance of UserService.
-func NewUserService(repo repository.UserRepo) *UserService {
-	return &UserService{Repo: repo}
+func NewUserService(repo repository.UserRepo, audit AuditServ) *UserService {
+	return &UserService{Repo: repo, Audit: audit}
 }
 
 // GetAll retrieves a list of users with pagination.
@@ -91,6 +95,7 @@ func (s *UserService) Update(ctx context.Context, user *models.User) (*models.Us
 	if err != nil {
 		return nil, err
 	}
+	before := *existingUser
 
 	if user.Email != "" {
 		existingUser.Email = user.Email
@@ -100,6 +105,14 @@ func (s *UserService) Update(ctx context.Context, user *models.User) (*models.Us
 		existingUser.Name = user.Name
 	}
 
+	if user.Locale != "" {
+		locale, ok := i18n.Normalize(user.Locale)
+		if !ok {
+			return nil, ErrInvalidUserData
+		}
+		user.Locale = locale
+	}
+
 	updatedUser, err := s.Repo.UpdateUser(ctx, user)
 	if err != nil {
 		logger.Error("Failed to update user",
@@ -108,6 +121,11 @@ func (s *UserService) Update(ctx context.Context, user *models.User) (*models.Us
 		return nil, fmt.Errorf("%w: %v", ErrUserUpdateFailed, err)
 	}
 
+	// Re-read the stored state so the audit diff reflects what was actually saved.
+	if after, err := s.Repo.GetUserByID(ctx, user.ID); err == nil {
+		s.Audit.Record(ctx, AuditUserUpdate, models.AuditTargetUser, user.ID, &before, after)
+	}
+
 	logger.Info("User updated successfully",
 		zap.Uint("userID", updatedUser.ID),
 		zap.String("email", updatedUser.Email))
@@ -120,7 +138,7 @@ func (s *UserService) Delete(ctx context.Context, userID uint) error {
 		return ErrInvalidUserData
 	}
 
-	_, err := s.GetByID(ctx, userID)
+	before, err := s.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -133,6 +151,8 @@ func (s *UserService) Delete(ctx context.Context, userID uint) error {
 		return fmt.Errorf("%w: %v", ErrUserDeleteFailed, err)
 	}
 
+	s.Audit.Record(ctx, AuditUserDelete, models.AuditTargetUser, userID, before, nil)
+
 	logger.Info("User deleted successfully", zap.Uint("userID", userID))
 	return nil
 }
@@ -154,6 +174,7 @@ func (s *UserService) Block(ctx context.Context, userID uint) (*models.User, err
 		return nil, err
 	}
 
+	before := *user
 	user.IsBlocked = true
 	updatedUser, err := s.Repo.BlockUsers(ctx, user)
 	if err != nil {
@@ -163,6 +184,8 @@ func (s *UserService) Block(ctx context.Context, userID uint) (*models.User, err
 		return nil, fmt.Errorf("%w: %v", ErrUserBlockFailed, err)
 	}
 
+	s.Audit.Record(ctx, AuditUserBlock, models.AuditTargetUser, userID, &before, updatedUser)
+
 	logger.Info("User blocked successfully", zap.Uint("userID", updatedUser.ID))
 	return updatedUser, nil
 }
@@ -189,6 +212,7 @@ func (s *UserService) UnBlock(ctx context.Context, userID uint) (*models.User, e
 		return nil, ErrUserNotBlocked
 	}
 
+	before := *user
 	user.IsBlocked = false
 	updatedUser, err := s.Repo.UnBlockUsers(ctx, user)
 	if err != nil {
@@ -198,6 +222,8 @@ func (s *UserService) UnBlock(ctx context.Context, userID uint) (*models.User, e
 		return nil, fmt.Errorf("%w: %v", ErrUserUnblockFailed, err)
 	}
 
+	s.Audit.Record(ctx, AuditUserUnblock, models.AuditTargetUser, userID, &before, updatedUser)
+
 	logger.Info("User unblocked successfully", zap.Uint("userID", updatedUser.ID))
 	return updatedUser, nil
 }
@@ -22,5 +22,36 @@ func main() {
 	db.Migrator().DropTable(&models.User{})
 	db.Migrator().DropTable(&models.Link{})
 	db.Migrator().DropTable(&models.Stat{})
-	db.AutoMigrate(&models.Link{}, &models.User{}, &models.Stat{})
+	db.Migrator().DropTable(&models.LoginLockout{})
+	db.Migrator().DropTable(&models.Session{})
+	db.Migrator().DropTable(&models.Report{})
+	db.Migrator().DropTable(&models.URLRejection{})
+	db.Migrator().DropTable(&models.Campaign{})
+	db.Migrator().DropTable(&models.Click{})
+	db.Migrator().DropTable(&models.Conversion{})
+	db.Migrator().DropTable("link_tags")
+	db.Migrator().DropTable(&models.Tag{})
+	db.Migrator().DropTable(&models.Folder{})
+	// models.AuditLog is not dropped: the audit log is append-only and survives
+	// re-running the migration; AutoMigrate below only adds what is missing to it.
+	db.AutoMigrate(
+		&models.Link{},
+		&models.User{},
+		&models.Stat{},
+		&models.LoginLockout{},
+		&models.Session{},
+		&models.AuditLog{},
+		&models.Report{},
+		&models.URLRejection{},
+		&models.Campaign{},
+		&models.Click{},
+		&models.Conversion{},
+		&models.Folder{},
+		&models.Tag{},
+	)
+	db.Exec("CREATE SEQUENCE IF NOT EXISTS " + models.LinkCodeSequence)
+	db.Exec(models.LinkSearchIndex)
+	for _, stmt := range models.LinkSubstringIndexes {
+		db.Exec(stmt)
+	}
 }
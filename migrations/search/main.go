@@ -0,0 +1,28 @@
+// Command search creates the indexes of link search on an existing
+// database without touching its data:
+//
+//	go run ./migrations/search
+//
+// With LINK_SEARCH_SUBSTRING=true it also creates the trigram indexes
+// that substring search needs.
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+
+	"shorty/internal/models"
+	"shorty/pkg/migrations"
+)
+
+func main() {
+	// The flag may come from .env, like the DSN.
+	_ = godotenv.Load()
+	statements := []string{models.LinkSearchIndex}
+	if substring, _ := strconv.ParseBool(os.Getenv("LINK_SEARCH_SUBSTRING")); substring {
+		statements = append(statements, models.LinkSubstringIndexes...)
+	}
+	migrations.RunSQL(statements...)
+}
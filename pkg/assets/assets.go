@@ -0,0 +1,221 @@
+// Package assets раздаёт статические файлы по адресам с хешем содержимого,
+// с долгим кешированием, ETag и заранее сжатыми вариантами.
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Кодировки сжатых вариантов и расширения их файлов.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// Options - настройки Assets.
+type Options struct {
+	// Prefix - путь, по которому раздаются файлы, например "/static/".
+	Prefix string
+	// Dev отключает отпечатки и кеширование: файлы читаются при каждом
+	// запросе, чтобы правки были видны сразу.
+	Dev bool
+}
+
+// asset - файл со сжатыми вариантами.
+type asset struct {
+	name        string // имя с отпечатком, например "css/style.1a2b3c4d5e.css"
+	contentType string
+	hash        string
+	variants    map[string][]byte // кодировка -> содержимое, "" - без сжатия
+}
+
+// Assets раздаёт статические файлы. Имя файла с отпечатком содержит хеш
+// содержимого, поэтому такой ответ кешируется навсегда; по исходному имени
+// файл тоже доступен, но браузер должен проверять его актуальность.
+//
+// Gzip-вариант берётся из файла "<имя>.gz" или сжимается при старте,
+// brotli - только из заранее подготовленного файла "<имя>.br".
+type Assets struct {
+	fsys    fs.FS
+	opts    Options
+	files   map[string]*asset // по имени с отпечатком и по исходному имени
+	names   map[string]string // исходное имя -> имя с отпечатком
+	started time.Time
+}
+
+// New читает все файлы из fsys и готовит их к раздаче.
+func New(fsys fs.FS, opts Options) (*Assets, error) {
+	a := &Assets{
+		fsys:    fsys,
+		opts:    opts,
+		files:   make(map[string]*asset),
+		names:   make(map[string]string),
+		started: time.Now(),
+	}
+	if opts.Dev {
+		return a, nil
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		// Сжатые варианты подхватываются вместе с исходным файлом.
+		if ext := path.Ext(name); ext == ".gz" || ext == ".br" {
+			return nil
+		}
+		return a.add(name)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("assets: %w", err)
+	}
+	return a, nil
+}
+
+// add загружает файл и его сжатые варианты.
+func (a *Assets) add(name string) error {
+	content, err := fs.ReadFile(a.fsys, name)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])[:10]
+	ext := path.Ext(name)
+	contentType := mime.TypeByExtension(ext)
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	f := &asset{
+		name:        strings.TrimSuffix(name, ext) + "." + hash + ext,
+		contentType: contentType,
+		hash:        hash,
+		variants:    map[string][]byte{"": content},
+	}
+	if br, err := fs.ReadFile(a.fsys, name+".br"); err == nil {
+		f.variants[encodingBrotli] = br
+	}
+	if gz, err := fs.ReadFile(a.fsys, name+".gz"); err == nil {
+		f.variants[encodingGzip] = gz
+	} else if compressible(contentType) {
+		gz, err := gzipBytes(content)
+		if err != nil {
+			return err
+		}
+		if len(gz) < len(content) {
+			f.variants[encodingGzip] = gz
+		}
+	}
+
+	a.files[f.name] = f
+	a.files[name] = f
+	a.names[name] = f.name
+	return nil
+}
+
+// Path возвращает адрес файла с отпечатком для использования в шаблонах.
+// Для неизвестного файла и в режиме разработки возвращается обычный адрес.
+func (a *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if fingerprinted, ok := a.names[name]; ok {
+		return a.opts.Prefix + fingerprinted
+	}
+	return a.opts.Prefix + name
+}
+
+// ServeHTTP раздаёт файл. Путь запроса должен быть без префикса (http.StripPrefix).
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.opts.Dev {
+		w.Header().Set("Cache-Control", "no-cache")
+		http.FileServerFS(a.fsys).ServeHTTP(w, r)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	f, ok := a.files[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	header := w.Header()
+	if name == f.name {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+	header.Add("Vary", "Accept-Encoding")
+	header.Set("Content-Type", f.contentType)
+
+	encoding := negotiate(r.Header.Get("Accept-Encoding"), f.variants)
+	etag := f.hash
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+		etag += "-" + encoding
+	}
+	header.Set("ETag", strconv.Quote(etag))
+
+	// ServeContent отвечает 304 по If-None-Match, поддерживает HEAD и Range.
+	http.ServeContent(w, r, name, a.started, bytes.NewReader(f.variants[encoding]))
+}
+
+// negotiate выбирает лучшую из доступных кодировок, принимаемых клиентом.
+func negotiate(acceptEncoding string, variants map[string][]byte) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(coding)] = true
+	}
+	for _, encoding := range []string{encodingBrotli, encodingGzip} {
+		if _, ok := variants[encoding]; ok && accepted[encoding] {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressible сообщает, имеет ли смысл сжимать файл такого типа.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/javascript", mediaType == "application/json",
+		mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+// gzipBytes сжимает данные с максимальной степенью сжатия.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
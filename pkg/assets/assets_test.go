@@ -0,0 +1,188 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var css = strings.Repeat("body { color: black; }\n", 50)
+
+func testFiles() fstest.MapFS {
+	return fstest.MapFS{
+		"css/style.css":    {Data: []byte(css)},
+		"css/style.css.br": {Data: []byte("brotli bytes")},
+		"js/app.js":        {Data: []byte(strings.Repeat("console.log(1);\n", 50))},
+		"img/logo.png":     {Data: []byte("\x89PNG\r\n\x1a\nnot really")},
+		"tiny.txt":         {Data: []byte("a")},
+	}
+}
+
+func newTestAssets(t *testing.T) *Assets {
+	t.Helper()
+	a, err := New(testFiles(), Options{Prefix: "/static/"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+func serve(a *Assets, path string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, r)
+	return w
+}
+
+func TestPath(t *testing.T) {
+	a := newTestAssets(t)
+	fingerprinted := regexp.MustCompile(`^/static/css/style\.[0-9a-f]{10}\.css$`)
+	if p := a.Path("css/style.css"); !fingerprinted.MatchString(p) {
+		t.Errorf("Path(css/style.css) = %q, want a fingerprinted path", p)
+	}
+	if a.Path("/css/style.css") != a.Path("css/style.css") {
+		t.Error("a leading slash changes the path")
+	}
+	if p := a.Path("missing.css"); p != "/static/missing.css" {
+		t.Errorf("Path(missing.css) = %q", p)
+	}
+
+	// The fingerprint follows the content.
+	other := testFiles()
+	other["css/style.css"] = &fstest.MapFile{Data: []byte(css + "p {}\n")}
+	b, err := New(other, Options{Prefix: "/static/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path("css/style.css") == b.Path("css/style.css") {
+		t.Error("changed content has the same fingerprint")
+	}
+	if a.Path("js/app.js") != b.Path("js/app.js") {
+		t.Error("unchanged content has a new fingerprint")
+	}
+
+	dev, err := New(testFiles(), Options{Prefix: "/static/", Dev: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := dev.Path("css/style.css"); p != "/static/css/style.css" {
+		t.Errorf("Path in dev mode = %q, want the plain path", p)
+	}
+}
+
+func TestServeEncodingAndCaching(t *testing.T) {
+	a := newTestAssets(t)
+	fingerprinted := strings.TrimPrefix(a.Path("css/style.css"), "/static")
+	jsPath := strings.TrimPrefix(a.Path("js/app.js"), "/static")
+
+	tests := []struct {
+		name         string
+		path         string
+		accept       string
+		wantEncoding string
+		wantCache    string
+		wantType     string
+	}{
+		{name: "brotli preferred", path: fingerprinted, accept: "gzip, deflate, br", wantEncoding: "br", wantCache: "public, max-age=31536000, immutable", wantType: "text/css"},
+		{name: "gzip", path: fingerprinted, accept: "gzip", wantEncoding: "gzip", wantCache: "public, max-age=31536000, immutable", wantType: "text/css"},
+		{name: "brotli refused", path: fingerprinted, accept: "br;q=0, gzip", wantEncoding: "gzip", wantCache: "public, max-age=31536000, immutable", wantType: "text/css"},
+		{name: "identity", path: fingerprinted, wantCache: "public, max-age=31536000, immutable", wantType: "text/css"},
+		{name: "no brotli file", path: jsPath, accept: "br, gzip", wantEncoding: "gzip", wantCache: "public, max-age=31536000, immutable", wantType: "javascript"},
+		{name: "original name", path: "/css/style.css", accept: "br", wantEncoding: "br", wantCache: "no-cache", wantType: "text/css"},
+		{name: "not worth compressing", path: "/tiny.txt", accept: "gzip", wantCache: "no-cache", wantType: "text/plain"},
+		{name: "binary", path: "/img/logo.png", accept: "gzip", wantCache: "no-cache", wantType: "image/png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(a, tt.path, http.Header{"Accept-Encoding": {tt.accept}})
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d", w.Code)
+			}
+			h := w.Header()
+			if got := h.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := h.Get("Vary"); got != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", got)
+			}
+			if got := h.Get("Cache-Control"); got != tt.wantCache {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCache)
+			}
+			if got := h.Get("Content-Type"); !strings.Contains(got, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			etag := h.Get("ETag")
+			if !strings.HasPrefix(etag, `"`) || (tt.wantEncoding != "") != strings.HasSuffix(etag, "-"+tt.wantEncoding+`"`) {
+				t.Errorf("ETag = %s for encoding %q", etag, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestServeContent(t *testing.T) {
+	a := newTestAssets(t)
+	path := strings.TrimPrefix(a.Path("css/style.css"), "/static")
+
+	if w := serve(a, path, nil); w.Body.String() != css {
+		t.Error("identity body differs from the file")
+	}
+	if w := serve(a, path, http.Header{"Accept-Encoding": {"br"}}); w.Body.String() != "brotli bytes" {
+		t.Error("brotli body is not the prepared .br file")
+	}
+	w := serve(a, path, http.Header{"Accept-Encoding": {"gzip"}})
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); !bytes.Equal(body, []byte(css)) {
+		t.Error("gzip body does not decompress to the file")
+	}
+
+	// The variants are not served as files of their own.
+	if w := serve(a, "/css/style.css.br", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET /css/style.css.br = %d, want 404", w.Code)
+	}
+	if w := serve(a, "/missing.css", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET /missing.css = %d, want 404", w.Code)
+	}
+}
+
+func TestServeNotModified(t *testing.T) {
+	a := newTestAssets(t)
+	path := strings.TrimPrefix(a.Path("css/style.css"), "/static")
+	gzipETag := serve(a, path, http.Header{"Accept-Encoding": {"gzip"}}).Header().Get("ETag")
+
+	if w := serve(a, path, http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {gzipETag}}); w.Code != http.StatusNotModified {
+		t.Errorf("matching ETag: status %d, want 304", w.Code)
+	}
+	// The ETag of another encoding does not match.
+	if w := serve(a, path, http.Header{"Accept-Encoding": {"br"}, "If-None-Match": {gzipETag}}); w.Code != http.StatusOK {
+		t.Errorf("ETag of another encoding: status %d, want 200", w.Code)
+	}
+}
+
+func TestServeDev(t *testing.T) {
+	a, err := New(testFiles(), Options{Prefix: "/static/", Dev: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serve(a, "/css/style.css", http.Header{"Accept-Encoding": {"br"}})
+	if w.Code != http.StatusOK || w.Body.String() != css {
+		t.Errorf("dev mode: status %d, body of %d bytes", w.Code, w.Body.Len())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q, want none", got)
+	}
+}
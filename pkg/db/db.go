@@ -19,7 +19,8 @@ type DB struct {
 // NewDatabase initializes and returns a new database connection
 func NewDatabase(cfg *config.Config) (*DB, error) {
 	// Attempt to open a connection to the database using GORM and the provided DSN
-	db, err := gorm.Open(postgres.Open(cfg.Db.Dsn), &gorm.Config{})
+	// TranslateError turns driver errors such as unique violations into gorm errors (gorm.ErrDuplicatedKey)
+	db, err := gorm.Open(postgres.Open(cfg.Db.Dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		logger.Error("Failed to connect to the database", zap.Error(err))
 		return nil, fmt.Errorf("failed to connect to the database: %w", err)
@@ -0,0 +1,142 @@
+// Package i18n хранит каталоги сообщений и выбирает язык ответа.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые языки.
+const (
+	Russian = "ru"
+	English = "en"
+)
+
+// DefaultLocale используется, когда клиент не указал понятный нам язык.
+const DefaultLocale = Russian
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// catalogs: язык -> ключ -> сообщение.
+var catalogs = mustLoad()
+
+func mustLoad() map[string]map[string]string {
+	entries, err := localesFS.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	out := make(map[string]map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := localesFS.ReadFile("locales/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", e.Name(), err))
+		}
+		out[strings.TrimSuffix(e.Name(), ".json")] = messages
+	}
+	return out
+}
+
+// Locales возвращает список поддерживаемых языков в алфавитном порядке.
+func Locales() []string {
+	out := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		out = append(out, locale)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// T переводит ключ на указанный язык. Если перевода нет, берётся язык по
+// умолчанию, а затем сам ключ. Аргументы подставляются через fmt.Sprintf.
+func T(locale, key string, args ...any) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Has сообщает, есть ли ключ в каталоге языка по умолчанию.
+func Has(key string) bool {
+	_, ok := catalogs[DefaultLocale][key]
+	return ok
+}
+
+// Normalize приводит языковой тег ("en-US", "RU") к поддерживаемому языку.
+func Normalize(tag string) (string, bool) {
+	base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	base = strings.ToLower(base)
+	if _, ok := catalogs[base]; !ok {
+		return "", false
+	}
+	return base, true
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учётом q-весов.
+func Negotiate(header string) (string, bool) {
+	best := bestLanguage(header, func(base string) bool {
+		_, ok := catalogs[base]
+		return ok
+	})
+	return best, best != ""
+}
+
+// Preferred возвращает базовый тег самого предпочтительного языка из заголовка
+// Accept-Language ("en" для "en-US"), даже если каталога для него нет.
+func Preferred(header string) string {
+	return bestLanguage(header, func(base string) bool { return base != "" && base != "*" })
+}
+
+// bestLanguage возвращает базовый тег с наибольшим q-весом среди принятых accept.
+func bestLanguage(header string, accept func(base string) bool) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		base, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+		base = strings.ToLower(base)
+		if !accept(base) || q <= bestQ {
+			continue
+		}
+		best, bestQ = base, q
+	}
+	return best
+}
+
+type localeKey struct{}
+
+// WithLocale сохраняет выбранный язык в контексте запроса.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext возвращает язык запроса или язык по умолчанию.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{header: "en-US,en;q=0.9", want: "en", ok: true},
+		{header: "ru-RU", want: "ru", ok: true},
+		{header: "ru;q=0.5, en;q=0.8", want: "en", ok: true},
+		{header: "de-DE, en;q=0.3, ru;q=0.7", want: "ru", ok: true},
+		{header: "EN", want: "en", ok: true},
+		{header: "en;q=bad, ru;q=0.1", want: "ru", ok: true},
+		{header: "en;q=0, ru;q=0", ok: false},
+		{header: "de, fr;q=0.9", ok: false},
+		{header: "*", ok: false},
+		{header: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Negotiate(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	tests := map[string]string{
+		"de-DE, en;q=0.9": "de",
+		"*, fr;q=0.5":     "fr",
+		"":                "",
+	}
+	for header, want := range tests {
+		if got := Preferred(header); got != want {
+			t.Errorf("Preferred(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{tag: "en-US", want: "en", ok: true},
+		{tag: " RU ", want: "ru", ok: true},
+		{tag: "de", ok: false},
+		{tag: "", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	base := catalogs[DefaultLocale]
+	for _, locale := range Locales() {
+		for key := range catalogs[locale] {
+			if _, ok := base[key]; !ok {
+				t.Errorf("%s: key %q is missing in %s", locale, key, DefaultLocale)
+			}
+		}
+		for key := range base {
+			if _, ok := catalogs[locale][key]; !ok {
+				t.Errorf("%s: key %q is missing", locale, key)
+			}
+		}
+	}
+}
+
+func TestTFallback(t *testing.T) {
+	saved := catalogs
+	t.Cleanup(func() { catalogs = saved })
+	catalogs = map[string]map[string]string{
+		Russian: {"greeting": "привет, %s", "only.default": "только по умолчанию"},
+		English: {"greeting": "hello, %s"},
+	}
+
+	tests := []struct {
+		locale, key string
+		args        []any
+		want        string
+	}{
+		{locale: English, key: "greeting", args: []any{"Ann"}, want: "hello, Ann"},
+		{locale: Russian, key: "greeting", args: []any{"Ann"}, want: "привет, Ann"},
+		{locale: English, key: "only.default", want: "только по умолчанию"},
+		{locale: "de", key: "greeting", args: []any{"Ann"}, want: "привет, Ann"},
+		{locale: English, key: "missing.key", want: "missing.key"},
+	}
+	for _, tt := range tests {
+		if got := T(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+	if !Has("only.default") || Has("missing.key") {
+		t.Error("Has does not follow the default catalog")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != DefaultLocale {
+		t.Errorf("FromContext(empty) = %q, want %q", got, DefaultLocale)
+	}
+	if got := FromContext(WithLocale(context.Background(), English)); got != English {
+		t.Errorf("FromContext = %q, want en", got)
+	}
+}
@@ -14,6 +14,7 @@ type JWTData struct {
 	Email     string
 	Role      models.Role
 	IsBlocked bool
+	Locale    string // язык из профиля пользователя; пустой, если не выбран
 }
 
 // JWT - структура для работы с токенами
@@ -33,6 +34,7 @@ func (j *JWT) CreateToken(user *models.User) (string, error) {
 		"email":      user.Email,
 		"role":       user.Role,
 		"is_blocked": user.IsBlocked,
+		"locale":     user.Locale,
 		"exp":        time.Now().Add(24 * time.Hour).Unix(), // Токен живёт 24 часа
 	}
 
@@ -78,10 +80,14 @@ func (j *JWT) ParseToken(token string) (*JWTData, error) {
 		return nil, errors.New("invalid is_blocked")
 	}
 
+	// В токенах, выданных до появления языка в профиле, его нет.
+	locale, _ := claims["locale"].(string)
+
 	return &JWTData{
 		UserID:    uint(userID),
 		Email:     email,
 		Role:      models.Role(role), // Приводим строку обратно в `models.Role`
 		IsBlocked: isBlocked,
+		Locale:    locale,
 	}, nil
 }
@@ -22,7 +22,7 @@ func AdminMiddleware(jwtService *jwt.JWT, userService service.UserServ) func(htt
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				logger.Warn("Отсутствует заголовок Authorization")
-				res.ERROR(w, common.ErrUnauthorized, http.StatusUnauthorized)
+				res.ERROR(w, r, common.ErrUnauthorized)
 				return
 			}
 
@@ -30,7 +30,7 @@ func AdminMiddleware(jwtService *jwt.JWT, userService service.UserServ) func(htt
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
 				logger.Warn("Неверный формат токена")
-				res.ERROR(w, common.ErrInvalidToken, http.StatusUnauthorized)
+				res.ERROR(w, r, common.ErrInvalidToken)
 				return
 			}
 
@@ -38,7 +38,7 @@ func AdminMiddleware(jwtService *jwt.JWT, userService service.UserServ) func(htt
 			claims, err := jwtService.ParseToken(parts[1])
 			if err != nil {
 				logger.Warn("Ошибка парсинга JWT", zap.Error(err))
-				res.ERROR(w, common.ErrInvalidToken, http.StatusUnauthorized)
+				res.ERROR(w, r, common.ErrInvalidToken)
 				return
 			}
 
@@ -47,14 +47,14 @@ func AdminMiddleware(jwtService *jwt.JWT, userService service.UserServ) func(htt
 			user, err := userService.GetByID(ctx, claims.UserID)
 			if err != nil {
 				logger.Warn("Пользователь не найден", zap.Uint("userID", claims.UserID))
-				res.ERROR(w, common.ErrUserNotFound, http.StatusUnauthorized)
+				res.ERROR(w, r, common.ErrInvalidToken)
 				return
 			}
 
 			// Проверяем, является ли пользователь администратором
 			if user.Role != models.RoleAdmin {
 				logger.Warn("Доступ запрещён: недостаточно прав", zap.Uint("userID", user.ID))
-				res.ERROR(w, common.ErrForbidden, http.StatusForbidden)
+				res.ERROR(w, r, common.ErrForbidden)
 				return
 			}
 
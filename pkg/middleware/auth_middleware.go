@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"strings"
 
+	"shorty/internal/common"
 	"shorty/internal/config"
 	"shorty/pkg/jwt"
+	"shorty/pkg/res"
 )
 
 type key string
@@ -15,23 +17,22 @@ const (
 	ContextUserKey key = "ContextUserKey"
 )
 
-func writeUnauthorized(w http.ResponseWriter) {
-	w.WriteHeader(http.StatusUnauthorized)
-	w.Write([]byte(http.StatusText(http.StatusUnauthorized)))
+func writeUnauthorized(w http.ResponseWriter, r *http.Request) {
+	res.ERROR(w, r, common.ErrUnauthorized)
 }
 
 func IsAuth(next http.Handler, cfg *config.Config) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		bearerToken := r.Header.Get("Authorization")
 		if !strings.HasPrefix(bearerToken, "Bearer ") {
-			writeUnauthorized(w)
+			writeUnauthorized(w, r)
 			return
 		}
 		token := strings.TrimPrefix(bearerToken, "Bearer ")
 
 		data, err := jwt.NewJWT(cfg.Auth.Secret).ParseToken(token)
 		if err != nil {
-			writeUnauthorized(w)
+			writeUnauthorized(w, r)
 			return
 		}
 
@@ -40,3 +41,22 @@ func IsAuth(next http.Handler, cfg *config.Config) http.Handler {
 		next.ServeHTTP(w, req)
 	})
 }
+
+// OptionalAuth кладёт данные токена в контекст, если передан валидный Bearer-токен,
+// но, в отличие от IsAuth, пропускает и анонимные запросы.
+func OptionalAuth(next http.Handler, cfg *config.Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		data, err := jwt.NewJWT(cfg.Auth.Secret).ParseToken(token)
+		if err != nil {
+			writeUnauthorized(w, r)
+			return
+		}
+		ctx := context.WithValue(r.Context(), ContextUserKey, data)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
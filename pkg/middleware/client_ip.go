@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/pkg/req"
+)
+
+// ClientIP сохраняет IP-адрес клиента в контексте запроса для сервисного слоя.
+func ClientIP(cfg *config.Config) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := common.WithClientIP(r.Context(), req.ClientIP(r, cfg.TrustProxy))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
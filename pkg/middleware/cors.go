@@ -10,12 +10,15 @@ func CORS(next http.Handler) http.Handler {
 			return
 		}
 		header := w.Header()
+		// Credentials не разрешаются: иначе любой сайт мог бы читать ответы
+		// от имени пользователя с cookie-сессией. API работает по Bearer-токену.
 		header.Set("Access-Control-Allow-Origin", origin)
-		header.Set("Access-Control-Allow-Credentials", "true")
+		header.Add("Vary", "Origin")
+		header.Set("Access-Control-Expose-Headers", "RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if r.Method == http.MethodOptions {
 			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			header.Set("Access-Control-Allow-Headers", "authorization, content-type, content-length")
+			header.Set("Access-Control-Allow-Headers", "authorization, content-type, content-length, x-api-key")
 			header.Set("Access-Control-Max-Age", "86400")
 			return
 		}
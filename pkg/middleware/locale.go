@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"shorty/internal/config"
+	"shorty/pkg/i18n"
+	"shorty/pkg/jwt"
+)
+
+const (
+	// LocaleCookie хранит язык, выбранный переключателем в веб-интерфейсе.
+	LocaleCookie = "lang"
+	// LocaleParam - параметр запроса для явного выбора языка (?lang=en).
+	LocaleParam = "lang"
+)
+
+// Locale определяет язык ответа и сохраняет его в контексте запроса.
+// Приоритет: параметр ?lang= (запоминается в cookie), язык из профиля
+// пользователя сессии или Bearer-токена, cookie, затем Accept-Language.
+// Язык в токене записывается при входе, так что смена языка в профиле
+// применяется к API с новым токеном.
+func Locale(cfg *config.Config) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale, ok := i18n.Normalize(r.URL.Query().Get(LocaleParam))
+			if ok {
+				http.SetCookie(w, &http.Cookie{
+					Name:     LocaleCookie,
+					Value:    locale,
+					Path:     "/",
+					MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+					HttpOnly: true,
+					Secure:   cfg.Session.CookieSecure,
+					SameSite: http.SameSiteLaxMode,
+				})
+			}
+			if !ok {
+				if data, found := SessionFromContext(r.Context()); found {
+					locale, ok = i18n.Normalize(data.User.Locale)
+				}
+			}
+			if !ok {
+				locale, ok = tokenLocale(r, cfg)
+			}
+			if !ok {
+				if cookie, err := r.Cookie(LocaleCookie); err == nil {
+					locale, ok = i18n.Normalize(cookie.Value)
+				}
+			}
+			if !ok {
+				locale, ok = i18n.Negotiate(r.Header.Get("Accept-Language"))
+			}
+			if !ok {
+				locale = i18n.DefaultLocale
+			}
+
+			w.Header().Set("Content-Language", locale)
+			w.Header().Add("Vary", "Accept-Language")
+			next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+		})
+	}
+}
+
+// tokenLocale возвращает язык из профиля пользователя, записанный в валидный
+// Bearer-токен запроса.
+func tokenLocale(r *http.Request, cfg *config.Config) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	data, err := jwt.NewJWT(cfg.Auth.Secret).ParseToken(token)
+	if err != nil {
+		return "", false
+	}
+	return i18n.Normalize(data.Locale)
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/pkg/i18n"
+	"shorty/pkg/jwt"
+)
+
+func TestLocaleOrder(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{Secret: "secret"}}
+	token := func(locale string) string {
+		t.Helper()
+		s, err := jwt.NewJWT(cfg.Auth.Secret).CreateToken(&models.User{ID: 1, Locale: locale})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + s
+	}
+	forged, err := jwt.NewJWT("other").CreateToken(&models.User{ID: 1, Locale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		session       string // язык пользователя сессии; "-" - без сессии
+		authorization string
+		cookie        string
+		accept        string
+		want          string
+		wantCookie    bool
+	}{
+		{name: "query wins", query: "?lang=en", session: "ru", authorization: token("ru"), cookie: "ru", accept: "ru", want: "en", wantCookie: true},
+		{name: "session profile", session: "en", authorization: token("ru"), cookie: "ru", accept: "ru", want: "en"},
+		{name: "token profile", session: "-", authorization: token("en"), cookie: "ru", accept: "ru", want: "en"},
+		{name: "session without locale", session: "", authorization: token("en"), cookie: "ru", want: "en"},
+		{name: "invalid token", session: "-", authorization: "Bearer " + forged, cookie: "ru", accept: "en", want: "ru"},
+		{name: "token without locale", session: "-", authorization: token(""), cookie: "en", accept: "ru", want: "en"},
+		{name: "cookie", session: "-", cookie: "en", accept: "ru", want: "en"},
+		{name: "accept-language", session: "-", accept: "de, en;q=0.5", want: "en"},
+		{name: "unsupported query", query: "?lang=de", session: "-", accept: "en", want: "en"},
+		{name: "default", session: "-", want: i18n.DefaultLocale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := Locale(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = i18n.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/links"+tt.query, nil)
+			if tt.session != "-" {
+				data := &SessionData{Session: &models.Session{}, User: &models.User{Locale: tt.session}}
+				r = r.WithContext(context.WithValue(r.Context(), ContextSessionKey, data))
+			}
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: LocaleCookie, Value: tt.cookie})
+			}
+			if tt.accept != "" {
+				r.Header.Set("Accept-Language", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got != tt.want {
+				t.Errorf("locale = %q, want %q", got, tt.want)
+			}
+			if cl := w.Header().Get("Content-Language"); cl != tt.want {
+				t.Errorf("Content-Language = %q, want %q", cl, tt.want)
+			}
+			if set := len(w.Result().Cookies()) > 0; set != tt.wantCookie {
+				t.Errorf("cookie set = %v, want %v", set, tt.wantCookie)
+			}
+		})
+	}
+}
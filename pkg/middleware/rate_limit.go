@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/pkg/jwt"
+	"shorty/pkg/logger"
+	"shorty/pkg/req"
+	"shorty/pkg/res"
+)
+
+// RateLimitDeps - зависимости промежуточного ПО ограничения частоты запросов.
+type RateLimitDeps struct {
+	Config *config.Config
+	Store  RateLimitStore
+	JWT    *jwt.JWT
+	// Route возвращает шаблон маршрута, который обработает запрос.
+	Route func(r *http.Request) string
+	// OnLimit отвечает на запрос сверх лимита. По умолчанию - JSON-ошибка.
+	OnLimit func(w http.ResponseWriter, r *http.Request)
+}
+
+// RateLimit ограничивает частоту запросов по политикам из конфигурации.
+// Политика выбирается по шаблону маршрута, запросы без политики не ограничиваются.
+func RateLimit(deps RateLimitDeps) Middleware {
+	policies := make(map[string]config.RateLimitPolicy, len(deps.Config.RateLimit.Policies))
+	for _, p := range deps.Config.RateLimit.Policies {
+		policies[p.Route] = p
+	}
+
+	onLimit := deps.OnLimit
+	if onLimit == nil {
+		onLimit = func(w http.ResponseWriter, r *http.Request) {
+			res.ERROR(w, r, common.ErrTooManyRequests)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if !deps.Config.RateLimit.Enabled || len(policies) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy, ok := policies[deps.Route(r)]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limit := Limit{Capacity: policy.Limit, Period: policy.Period}
+			key := policy.Route + "|" + rateLimitKey(r, policy.KeyBy, deps)
+			result, err := deps.Store.Take(r.Context(), key, limit)
+			if err != nil {
+				// Недоступное хранилище не должно ронять сервис.
+				logger.Error("Ошибка хранилища ограничения частоты запросов", zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Period.Seconds())))
+			header.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.ResetAfter))
+
+			if !result.Allowed {
+				logger.Warn("Превышен лимит запросов", zap.String("route", policy.Route), zap.String("key_by", policy.KeyBy))
+				header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				onLimit(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey возвращает ключ клиента согласно keyBy. Ключом служит только
+// проверенная личность: пользователь из подписанного токена. Если его нет,
+// используется IP-адрес, иначе клиент получал бы новый лимит с каждым
+// новым значением заголовка.
+func rateLimitKey(r *http.Request, keyBy string, deps RateLimitDeps) string {
+	switch keyBy {
+	case "user":
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && deps.JWT != nil {
+			if data, err := deps.JWT.ParseToken(token); err == nil {
+				return "user:" + strconv.FormatUint(uint64(data.UserID), 10)
+			}
+		}
+	}
+	return "ip:" + req.ClientIP(r, deps.Config.TrustProxy)
+}
+
+// ceilSeconds форматирует длительность в целых секундах с округлением вверх.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit describes a token bucket: Capacity tokens that refill evenly over Period.
+type Limit struct {
+	Capacity int
+	Period   time.Duration
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	ResetAfter time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next token is available, zero if allowed
+}
+
+// RateLimitStore keeps token buckets. The in-memory implementation is local to
+// one process; a shared implementation (e.g. Redis) can be plugged in for
+// several replicas.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+// bucket is the state of a single token bucket.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryStore is an in-memory RateLimitStore.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes one token from the bucket identified by key.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.Capacity)
+	rate := capacity / limit.Period.Seconds() // tokens per second
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, period: limit.Period}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.ResetAfter = secondsToDuration((capacity - b.tokens) / rate)
+	return res, nil
+}
+
+// sweep drops buckets that have been idle long enough to be full again.
+// It runs at most once a minute.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// secondsToDuration converts fractional seconds to a duration.
+func secondsToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/pkg/jwt"
+	"shorty/pkg/logger"
+)
+
+func init() {
+	logger.Logger = zap.NewNop()
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Capacity: 3, Period: 3 * time.Second}
+	take := func() RateLimitResult {
+		t.Helper()
+		res, err := s.Take(context.Background(), "k", limit)
+		if err != nil {
+			t.Fatalf("Take: %v", err)
+		}
+		return res
+	}
+
+	// A full bucket allows a burst of Capacity requests.
+	for want := 2; want >= 0; want-- {
+		if res := take(); !res.Allowed || res.Remaining != want {
+			t.Fatalf("burst: %+v, want allowed with %d remaining", res, want)
+		}
+	}
+	res := take()
+	if res.Allowed || res.RetryAfter != time.Second || res.ResetAfter != 3*time.Second {
+		t.Fatalf("over the limit: %+v, want denied, retry after 1s, reset after 3s", res)
+	}
+
+	// One token refills per second.
+	now = now.Add(time.Second)
+	if res := take(); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("after 1s: %+v, want one allowed request", res)
+	}
+	if res := take(); res.Allowed {
+		t.Errorf("after 1s: second request allowed: %+v", res)
+	}
+
+	// The bucket never holds more than Capacity tokens.
+	now = now.Add(time.Hour)
+	if res := take(); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("after an hour: %+v, want a full bucket", res)
+	}
+
+	// Buckets are independent.
+	other, _ := s.Take(context.Background(), "other", limit)
+	if !other.Allowed || other.Remaining != 2 {
+		t.Errorf("another key: %+v, want a full bucket", other)
+	}
+}
+
+// newRateLimited returns two routes behind RateLimit with the given policies.
+func newRateLimited(j *jwt.JWT, policies ...config.RateLimitPolicy) http.Handler {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	mux.HandleFunc("POST /links", ok)
+	mux.HandleFunc("GET /links", ok)
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{Enabled: true, Policies: policies}}
+	limit := RateLimit(RateLimitDeps{
+		Config: cfg,
+		Store:  NewMemoryStore(),
+		JWT:    j,
+		Route: func(r *http.Request) string {
+			_, pattern := mux.Handler(r)
+			return pattern
+		},
+	})
+	return limit(mux)
+}
+
+func serve(h http.Handler, method string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/links", nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	h := newRateLimited(nil, config.RateLimitPolicy{Route: "POST /links", Limit: 2, Period: time.Minute, KeyBy: "ip"})
+
+	w := serve(h, http.MethodPost, nil)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", w.Code)
+	}
+	want := map[string]string{
+		"RateLimit-Policy":    "2;w=60",
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "1",
+		"RateLimit-Reset":     "30",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if w.Header().Get("Retry-After") != "" {
+		t.Error("Retry-After set on an allowed request")
+	}
+
+	serve(h, http.MethodPost, nil)
+	w = serve(h, http.MethodPost, nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want 0", got)
+	}
+}
+
+func TestRateLimitPolicyPerRoute(t *testing.T) {
+	h := newRateLimited(nil, config.RateLimitPolicy{Route: "POST /links", Limit: 1, Period: time.Minute, KeyBy: "ip"})
+
+	serve(h, http.MethodPost, nil)
+	if w := serve(h, http.MethodPost, nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("limited route: status %d, want 429", w.Code)
+	}
+	for i := 0; i < 5; i++ {
+		w := serve(h, http.MethodGet, nil)
+		if w.Code != http.StatusNoContent || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("route without a policy: status %d, headers %v", w.Code, w.Header())
+		}
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	j := jwt.NewJWT("secret")
+	h := newRateLimited(j, config.RateLimitPolicy{Route: "POST /links", Limit: 1, Period: time.Minute, KeyBy: "user"})
+
+	// Headers the server cannot verify do not give a new bucket.
+	serve(h, http.MethodPost, http.Header{"X-Api-Key": {"a"}})
+	if w := serve(h, http.MethodPost, http.Header{"X-Api-Key": {"b"}}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("new X-API-Key: status %d, want 429", w.Code)
+	}
+	if w := serve(h, http.MethodPost, http.Header{"Authorization": {"Bearer forged"}}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("invalid token: status %d, want 429", w.Code)
+	}
+
+	// A verified user has a bucket of their own.
+	for _, id := range []uint{1, 2} {
+		token, err := j.CreateToken(&models.User{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth := http.Header{"Authorization": {"Bearer " + token}}
+		if w := serve(h, http.MethodPost, auth); w.Code != http.StatusNoContent {
+			t.Errorf("user %d: status %d, want 204", id, w.Code)
+		}
+		if w := serve(h, http.MethodPost, auth); w.Code != http.StatusTooManyRequests {
+			t.Errorf("user %d again: status %d, want 429", id, w.Code)
+		}
+	}
+}
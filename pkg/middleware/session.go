@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"shorty/internal/common"
+	"shorty/internal/config"
+	"shorty/internal/models"
+	"shorty/pkg/logger"
+	"shorty/pkg/res"
+)
+
+const (
+	ContextSessionKey key = "ContextSessionKey"
+
+	// CSRFHeader и CSRFFormField - где клиент передаёт CSRF-токен сессии.
+	CSRFHeader    = "X-CSRF-Token"
+	CSRFFormField = "csrf_token"
+)
+
+// SessionResolver находит сессию и её пользователя по токену из cookie.
+type SessionResolver interface {
+	Resolve(ctx context.Context, token string) (*models.Session, *models.User, error)
+}
+
+// SessionData - сессия веб-интерфейса, сохранённая в контексте запроса.
+type SessionData struct {
+	Session *models.Session
+	User    *models.User
+}
+
+// Session загружает cookie-сессию в контекст запроса. Недействительная cookie удаляется.
+func Session(cfg *config.Config, sessions SessionResolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(cfg.Session.CookieName)
+			if err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session, user, err := sessions.Resolve(r.Context(), cookie.Value)
+			switch {
+			case errors.Is(err, common.ErrSessionNotFound) || errors.Is(err, common.ErrSessionExpired):
+				logger.Debug("Недействительная сессия", zap.Error(err))
+				ClearSessionCookie(w, cfg)
+				next.ServeHTTP(w, r)
+				return
+			case err != nil:
+				// Сбой хранилища не повод разлогинивать: cookie остаётся,
+				// запрос обрабатывается как анонимный.
+				logger.Error("Ошибка загрузки сессии", zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ContextSessionKey, &SessionData{Session: session, User: user})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CSRF проверяет CSRF-токен в небезопасных запросах, аутентифицированных cookie-сессией.
+// Запросы с заголовком Authorization не проверяются: токен в нём браузер сам не подставляет.
+func CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		data, ok := SessionFromContext(r.Context())
+		if !ok || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get(CSRFHeader)
+		if token == "" {
+			token = r.PostFormValue(CSRFFormField)
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(data.Session.CSRFToken)) != 1 {
+			logger.Warn("Неверный CSRF-токен", zap.String("path", r.URL.Path), zap.Uint("userID", data.User.ID))
+			res.ERROR(w, r, common.ErrInvalidCSRFToken)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionFromContext возвращает сессию веб-интерфейса из контекста запроса.
+func SessionFromContext(ctx context.Context) (*SessionData, bool) {
+	data, ok := ctx.Value(ContextSessionKey).(*SessionData)
+	return data, ok
+}
+
+// SetSessionCookie выставляет cookie сессии.
+func SetSessionCookie(w http.ResponseWriter, cfg *config.Config, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cfg.Session.CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(cfg.Session.AbsoluteTimeout.Seconds()),
+		HttpOnly: true,
+		Secure:   cfg.Session.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearSessionCookie удаляет cookie сессии.
+func ClearSessionCookie(w http.ResponseWriter, cfg *config.Config) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     cfg.Session.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   cfg.Session.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -33,6 +33,20 @@ func RunDown(m any) {
 	log.Println("✅ Migration DOWN completed")
 }
 
+// RunSQL executes the statements in order and stops at the first failure.
+// Statements should be idempotent (IF NOT EXISTS), so that running the
+// migration again on an up-to-date database changes nothing.
+func RunSQL(statements ...string) {
+	loadEnv()
+	db := openDB()
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			log.Fatalf("❌ Migration failed: %s: %v", stmt, err)
+		}
+	}
+	log.Println("✅ Migration SQL completed")
+}
+
 // openDB opens a connection to the PostgreSQL database using the DSN
 // from environment variables.
 func openDB() *gorm.DB {
@@ -1,10 +1,15 @@
 package parse
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// DateLayout - формат дат в параметрах запроса.
+const DateLayout = "2006-01-02"
+
 // parseID парсит идентификатор из строки в uint.
 func ParseID(r *http.Request) (uint, error) {
 	rid := r.PathValue("id")
@@ -14,3 +19,20 @@ func ParseID(r *http.Request) (uint, error) {
 	}
 	return uint(id), nil
 }
+
+// DateRange парсит обязательные параметры запроса from и to в формате DateLayout.
+// from не может быть позже to.
+func DateRange(r *http.Request) (from, to time.Time, err error) {
+	from, err = time.Parse(DateLayout, r.URL.Query().Get("from"))
+	if err != nil {
+		return from, to, err
+	}
+	to, err = time.Parse(DateLayout, r.URL.Query().Get("to"))
+	if err != nil {
+		return from, to, err
+	}
+	if from.After(to) {
+		return from, to, errors.New("'from' date must be before or equal to 'to' date")
+	}
+	return from, to, nil
+}
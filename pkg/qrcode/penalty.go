@@ -0,0 +1,84 @@
+package qrcode
+
+// Penalty weights of the mask evaluation rules.
+const (
+	penaltyRun     = 3  // run of five same-coloured modules, plus one per extra module
+	penaltyBlock   = 3  // 2x2 block of one colour
+	penaltyFinder  = 40 // pattern that looks like a finder
+	penaltyBalance = 10 // every 5% the dark share is away from 50%
+)
+
+// finderLike is the 1:1:3:1:1 dark-light ratio of a finder pattern followed
+// by four light modules; it is also looked for reversed.
+var finderLike = []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+// penalty scores the modules by the four rules of the standard; the mask
+// with the lowest score is used.
+func (c *Code) penalty() int {
+	n := c.Size
+	score := 0
+
+	// Runs in rows and columns, and finder-like patterns.
+	for i := 0; i < n; i++ {
+		row := func(j int) bool { return c.modules[i*n+j] }
+		col := func(j int) bool { return c.modules[j*n+i] }
+		score += runPenalty(n, row) + runPenalty(n, col)
+		score += finderPenalty(n, row) + finderPenalty(n, col)
+	}
+
+	// 2x2 blocks.
+	for y := 0; y < n-1; y++ {
+		for x := 0; x < n-1; x++ {
+			v := c.modules[y*n+x]
+			if v == c.modules[y*n+x+1] && v == c.modules[(y+1)*n+x] && v == c.modules[(y+1)*n+x+1] {
+				score += penaltyBlock
+			}
+		}
+	}
+
+	// Balance of dark and light modules.
+	dark := 0
+	for _, v := range c.modules {
+		if v {
+			dark++
+		}
+	}
+	percent := dark * 100 / len(c.modules)
+	score += abs(percent-50) / 5 * penaltyBalance
+	return score
+}
+
+// runPenalty scores runs of five or more same-coloured modules in a line.
+func runPenalty(n int, at func(int) bool) int {
+	score, run := 0, 1
+	for j := 1; j <= n; j++ {
+		if j < n && at(j) == at(j-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += penaltyRun + run - 5
+		}
+		run = 1
+	}
+	return score
+}
+
+// finderPenalty scores finder-like patterns in a line in both directions.
+func finderPenalty(n int, at func(int) bool) int {
+	score := 0
+	for j := 0; j+len(finderLike) <= n; j++ {
+		forward, backward := true, true
+		for k, v := range finderLike {
+			forward = forward && at(j+k) == v
+			backward = backward && at(j+len(finderLike)-1-k) == v
+		}
+		if forward {
+			score += penaltyFinder
+		}
+		if backward {
+			score += penaltyFinder
+		}
+	}
+	return score
+}
@@ -0,0 +1,287 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// Known answers from ISO/IEC 18004:2015, tables 1, 7, 9 and E.1.
+var versionTests = []struct {
+	version    int
+	codewords  int    // total codewords
+	data       [4]int // data codewords by level L, M, Q, H
+	bytes      [4]int // byte mode capacity by level
+	alignment  []int
+	versionHex int // version information, 0 below version 7
+}{
+	{1, 26, [4]int{19, 16, 13, 9}, [4]int{17, 14, 11, 7}, nil, 0},
+	{7, 196, [4]int{156, 124, 88, 66}, [4]int{154, 122, 86, 64}, []int{6, 22, 38}, 0x07C94},
+	{10, 346, [4]int{274, 216, 154, 122}, [4]int{271, 213, 151, 119}, []int{6, 28, 50}, 0x0A4D3},
+	{40, 3706, [4]int{2956, 2334, 1666, 1276}, [4]int{2953, 2331, 1663, 1273}, []int{6, 30, 58, 86, 114, 142, 170}, 0x28C69},
+}
+
+var levels = []Level{Low, Medium, Quartile, High}
+
+func TestCapacity(t *testing.T) {
+	for _, tt := range versionTests {
+		if got := rawDataModules(tt.version) / 8; got != tt.codewords {
+			t.Errorf("version %d: %d codewords, want %d", tt.version, got, tt.codewords)
+		}
+		for _, level := range levels {
+			if got := dataCodewords(tt.version, level); got != tt.data[level] {
+				t.Errorf("version %d-%s: %d data codewords, want %d", tt.version, level, got, tt.data[level])
+			}
+		}
+	}
+}
+
+func TestEncodeVersionAtCapacity(t *testing.T) {
+	for _, tt := range versionTests {
+		for _, level := range levels {
+			n := tt.bytes[level]
+			t.Run(fmt.Sprintf("%d-%s", tt.version, level), func(t *testing.T) {
+				code, err := Encode(bytes.Repeat([]byte{'a'}, n), level)
+				if err != nil {
+					t.Fatalf("Encode(%d bytes): %v", n, err)
+				}
+				if code.Version != tt.version || code.Size != 4*tt.version+17 || code.Level != level {
+					t.Errorf("Encode(%d bytes) = version %d size %d level %s, want version %d", n, code.Version, code.Size, code.Level, tt.version)
+				}
+
+				code, err = Encode(bytes.Repeat([]byte{'a'}, n+1), level)
+				if tt.version == 40 {
+					if !errors.Is(err, ErrTooLong) {
+						t.Errorf("Encode(%d bytes) error = %v, want ErrTooLong", n+1, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Encode(%d bytes): %v", n+1, err)
+				}
+				if code.Version != tt.version+1 {
+					t.Errorf("Encode(%d bytes) = version %d, want %d", n+1, code.Version, tt.version+1)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, 2954), Low); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(2954 bytes, L) error = %v, want ErrTooLong", err)
+	}
+	if _, err := Encode(make([]byte, 1274), High); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(1274 bytes, H) error = %v, want ErrTooLong", err)
+	}
+	if _, err := Encode(nil, Level(4)); err == nil || errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode with level 4 error = %v, want an invalid level error", err)
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	for _, tt := range versionTests {
+		if got := alignmentPositions(tt.version); !slices.Equal(got, tt.alignment) {
+			t.Errorf("version %d: alignment positions %v, want %v", tt.version, got, tt.alignment)
+		}
+	}
+	// Version 32 is the one exception to the spacing formula.
+	if got, want := alignmentPositions(32), []int{6, 34, 60, 86, 112, 138}; !slices.Equal(got, want) {
+		t.Errorf("version 32: alignment positions %v, want %v", got, want)
+	}
+}
+
+// formatTable holds the format information of every level and mask, from
+// table C.1, as bits 14 to 0.
+var formatTable = map[Level][8]string{
+	Low:      {"111011111000100", "111001011110011", "111110110101010", "111100010011101", "110011000101111", "110001100011000", "110110001000001", "110100101110110"},
+	Medium:   {"101010000010010", "101000100100101", "101111001111100", "101101101001011", "100010111111001", "100000011001110", "100111110010111", "100101010100000"},
+	Quartile: {"011010101011111", "011000001101000", "011111100110001", "011101000000110", "010010010110100", "010000110000011", "010111011011010", "010101111101101"},
+	High:     {"001011010001001", "001001110111110", "001110011100111", "001100111010000", "000011101100010", "000001001010101", "000110100001100", "000100000111011"},
+}
+
+func TestFormatInfo(t *testing.T) {
+	for _, level := range levels {
+		for mask, want := range formatTable[level] {
+			if got := fmt.Sprintf("%015b", formatInfo(level, mask)); got != want {
+				t.Errorf("formatInfo(%s, %d) = %s, want %s", level, mask, got, want)
+			}
+		}
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	for _, tt := range versionTests {
+		if tt.versionHex == 0 {
+			continue
+		}
+		if got := versionInfo(tt.version); got != tt.versionHex {
+			t.Errorf("versionInfo(%d) = %#05x, want %#05x", tt.version, got, tt.versionHex)
+		}
+	}
+}
+
+// readFormat reads both copies of the format information from a code.
+func readFormat(c *Code) (first, second int) {
+	set := func(v *int, i int, dark bool) {
+		if dark {
+			*v |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		set(&first, i, c.Black(8, i))
+	}
+	set(&first, 6, c.Black(8, 7))
+	set(&first, 7, c.Black(8, 8))
+	set(&first, 8, c.Black(7, 8))
+	for i := 9; i < 15; i++ {
+		set(&first, i, c.Black(14-i, 8))
+	}
+	for i := 0; i < 8; i++ {
+		set(&second, i, c.Black(c.Size-1-i, 8))
+	}
+	for i := 8; i < 15; i++ {
+		set(&second, i, c.Black(8, c.Size-15+i))
+	}
+	return first, second
+}
+
+func TestEncodePlacesFunctionPatterns(t *testing.T) {
+	for _, tt := range versionTests {
+		for _, level := range levels {
+			code, err := Encode(bytes.Repeat([]byte{'x'}, tt.bytes[level]), level)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			name := fmt.Sprintf("%d-%s", tt.version, level)
+
+			first, second := readFormat(code)
+			if first != second {
+				t.Errorf("%s: format copies differ: %015b and %015b", name, first, second)
+			}
+			if formats := formatTable[level]; !slices.Contains(formats[:], fmt.Sprintf("%015b", first)) {
+				t.Errorf("%s: format %015b is not a format of level %s", name, first, level)
+			}
+			if !code.Black(8, code.Size-8) {
+				t.Errorf("%s: dark module missing", name)
+			}
+
+			// Version information, both copies, from version 7 on.
+			for i := 0; i < 18 && tt.versionHex != 0; i++ {
+				want := tt.versionHex>>i&1 == 1
+				a, b := code.Size-11+i%3, i/3
+				if code.Black(a, b) != want || code.Black(b, a) != want {
+					t.Errorf("%s: version bit %d is not %v", name, i, want)
+				}
+			}
+
+			// Timing patterns between the finders.
+			for i := 8; i < code.Size-8; i++ {
+				if code.Black(i, 6) != (i%2 == 0) || code.Black(6, i) != (i%2 == 0) {
+					t.Errorf("%s: timing pattern broken at %d", name, i)
+					break
+				}
+			}
+
+			// Alignment patterns: dark centre, light ring, dark border.
+			last := len(tt.alignment) - 1
+			for i, x := range tt.alignment {
+				for j, y := range tt.alignment {
+					if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+						continue
+					}
+					if !code.Black(x, y) || code.Black(x+1, y) || code.Black(x, y-1) || !code.Black(x+2, y+2) || !code.Black(x-2, y) {
+						t.Errorf("%s: no alignment pattern at %d,%d", name, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	// The 1-M example of annex I: "01234567" in numeric mode.
+	data := []byte{0x10, 0x20, 0x0C, 0x56, 0x61, 0x80, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11}
+	want := []byte{0xA5, 0x24, 0xD4, 0xC1, 0xED, 0x36, 0xC7, 0x87, 0x2C, 0x55}
+	if got := rsRemainder(data, rsDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = % X, want % X", got, want)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	// Version 5-Q has two blocks of 15 and two of 16 data codewords with
+	// 18 error correction codewords each.
+	data := make([]byte, dataCodewords(5, Quartile))
+	for i := range data {
+		data[i] = byte(i)
+	}
+	got := interleave(data, 5, Quartile)
+	if len(got) != rawDataModules(5)/8 {
+		t.Fatalf("interleave returned %d codewords, want %d", len(got), rawDataModules(5)/8)
+	}
+	// Data codewords go column by column through the blocks, the extra
+	// codeword of the long blocks last.
+	wantData := []byte{0, 15, 30, 46, 1, 16, 31, 47}
+	if !bytes.Equal(got[:8], wantData) {
+		t.Errorf("first data codewords = %v, want %v", got[:8], wantData)
+	}
+	if want := []byte{45, 61}; !bytes.Equal(got[60:62], want) {
+		t.Errorf("last data codewords = %v, want %v", got[60:62], want)
+	}
+	ecc := rsRemainder(data[:15], rsDivisor(18))
+	if got[62] != ecc[0] || got[66] != ecc[1] {
+		t.Errorf("error correction codewords are not interleaved by block")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]Level{"": Medium, "l": Low, "M": Medium, "q": Quartile, "H": High} {
+		if got, err := ParseLevel(name); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %s, %v, want %s", name, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("X"); err == nil {
+		t.Error("ParseLevel(\"X\") succeeded")
+	}
+}
+
+func TestRender(t *testing.T) {
+	code, err := Encode([]byte("https://sho.rt/abc"), Medium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Version 2 is 25 modules, 33 with the margin: 3 pixels per module.
+	img := code.Image(Options{Size: 100, Margin: 4})
+	if b := img.Bounds(); b.Dx() != 99 || b.Dy() != 99 {
+		t.Errorf("image bounds %v, want 99x99", b)
+	}
+	var svg bytes.Buffer
+	if err := code.SVG(&svg, Options{Size: 100, Margin: 4}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(svg.Bytes(), []byte(`width="99" height="99" viewBox="0 0 33 33"`)) {
+		t.Errorf("SVG size does not match the PNG: %.120s", svg.Bytes())
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for _, s := range []string{"ff0080", "#FF0080", "f08"} {
+		c, err := ParseColor(s)
+		if s == "f08" {
+			if err != nil || c.R != 0xff || c.G != 0 || c.B != 0x88 {
+				t.Errorf("ParseColor(%q) = %v, %v", s, c, err)
+			}
+			continue
+		}
+		if err != nil || c.R != 0xff || c.G != 0 || c.B != 0x80 || c.A != 0xff {
+			t.Errorf("ParseColor(%q) = %v, %v", s, c, err)
+		}
+	}
+	for _, s := range []string{"", "1234567", "12345", "gg0000", "-12345"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("ParseColor(%q) succeeded", s)
+		}
+	}
+}
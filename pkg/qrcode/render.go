@@ -0,0 +1,172 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// logoShare is the largest share of the image width the logo may take.
+// Covering more modules than that is too much even for the High level.
+const logoShare = 0.2
+
+// Options configures how a code is rendered.
+type Options struct {
+	Size       int         // largest image width and height in pixels
+	Margin     int         // quiet zone around the code in modules
+	Foreground color.Color // dark modules, black if nil
+	Background color.Color // light modules and the quiet zone, white if nil
+	Logo       image.Image // optional logo drawn over the centre
+}
+
+// colors returns the foreground and background with defaults applied.
+func (o Options) colors() (fg, bg color.Color) {
+	fg, bg = o.Foreground, o.Background
+	if fg == nil {
+		fg = color.Black
+	}
+	if bg == nil {
+		bg = color.White
+	}
+	return fg, bg
+}
+
+// scale returns the size of a module in pixels: the largest whole number
+// that keeps the image within opts.Size, at least 1.
+func (c *Code) scale(opts Options) int {
+	return max(1, opts.Size/(c.Size+2*opts.Margin))
+}
+
+// Image renders the code. Modules are whole pixels, so the image is the
+// largest multiple of the module count not wider than opts.Size.
+func (c *Code) Image(opts Options) image.Image {
+	fg, bg := opts.colors()
+	scale := c.scale(opts)
+	side := scale * (c.Size + 2*opts.Margin)
+
+	img := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	dark := image.NewUniform(fg)
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				px, py := (x+opts.Margin)*scale, (y+opts.Margin)*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), dark, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if opts.Logo != nil {
+		box := logoBox(side, opts.Logo.Bounds())
+		draw.Draw(img, box.Inset(-scale), image.NewUniform(bg), image.Point{}, draw.Src)
+		draw.Draw(img, box, resize(opts.Logo, box.Dx(), box.Dy()), image.Point{}, draw.Over)
+	}
+	return img
+}
+
+// PNG writes the code as a PNG image.
+func (c *Code) PNG(w io.Writer, opts Options) error {
+	return png.Encode(w, c.Image(opts))
+}
+
+// SVG writes the code as an SVG image, one path for all dark modules.
+// Its width and height are those the PNG image would have.
+func (c *Code) SVG(w io.Writer, opts Options) error {
+	fg, bg := opts.colors()
+	scale := c.scale(opts)
+	units := c.Size + 2*opts.Margin
+	side := scale * units
+
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Black(x, y) {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+opts.Margin, y+opts.Margin)
+			}
+		}
+	}
+
+	var logo string
+	if opts.Logo != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, opts.Logo); err != nil {
+			return fmt.Errorf("qrcode: encoding logo: %w", err)
+		}
+		// The logo box in module units, so it sits on the viewBox grid.
+		box := logoBox(units*100, opts.Logo.Bounds())
+		logo = fmt.Sprintf(
+			`<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/><image x="%s" y="%s" width="%s" height="%s" href="data:image/png;base64,%s"/>`,
+			units100(box.Min.X-100), units100(box.Min.Y-100), units100(box.Dx()+200), units100(box.Dy()+200), hexColor(bg),
+			units100(box.Min.X), units100(box.Min.Y), units100(box.Dx()), units100(box.Dy()),
+			base64.StdEncoding.EncodeToString(buf.Bytes()),
+		)
+	}
+
+	_, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="%s"/><path d="%s" fill="%s"/>%s</svg>`,
+		side, side, units, units, hexColor(bg), path.String(), hexColor(fg), logo,
+	)
+	return err
+}
+
+// logoBox returns the centred box for a logo of the given bounds in a
+// square image of the given side, keeping the logo's aspect ratio.
+func logoBox(side int, logo image.Rectangle) image.Rectangle {
+	limit := int(float64(side) * logoShare)
+	w, h := logo.Dx(), logo.Dy()
+	if w <= 0 || h <= 0 {
+		return image.Rectangle{}
+	}
+	if w >= h {
+		w, h = limit, max(1, h*limit/w)
+	} else {
+		w, h = max(1, w*limit/h), limit
+	}
+	x, y := (side-w)/2, (side-h)/2
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// resize scales an image to w x h with nearest-neighbour sampling.
+func resize(src image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	b := src.Bounds()
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return dst
+}
+
+// units100 formats hundredths of a module as a decimal number.
+func units100(v int) string {
+	return strconv.FormatFloat(float64(v)/100, 'f', -1, 64)
+}
+
+// ParseColor parses a colour written as "rrggbb" or "rgb" hex digits, with
+// or without a leading "#".
+func ParseColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("qrcode: invalid colour %q", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// hexColor formats a colour as "#rrggbb", ignoring transparency.
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -2,15 +2,47 @@ package req
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"net/http"
+	"strings"
+
+	"shorty/internal/common"
 )
 
-// Decode декодирует тело запроса в структуру T.
-func Decode[T any](body io.ReadCloser) (T, error) {
+// errTrailingData - после JSON-объекта в теле есть ещё данные.
+var errTrailingData = errors.New("request body must contain a single JSON value")
+
+// Decode декодирует тело запроса в структуру T. Неизвестные поля и данные
+// после JSON-значения считаются ошибкой.
+func Decode[T any](body io.Reader) (T, error) {
 	var payload T
-	err := json.NewDecoder(body).Decode(&payload)
-	if err != nil {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
 		return payload, err
 	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return payload, errTrailingData
+	}
 	return payload, nil
 }
+
+// DecodeError переводит ошибку разбора тела в ошибку приложения: слишком
+// большое тело - ErrBodyTooLarge, неизвестное поле или неверный тип - ошибка
+// поля, остальное - ErrRequestBodyParse.
+func DecodeError(err error) *common.AppError {
+	var tooLarge *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return common.ErrBodyTooLarge.Wrap(err)
+	case errors.As(err, &typeErr):
+		return common.ErrRequestBodyParse.WithField(typeErr.Field, "invalid_type", typeErr.Type.String()).Wrap(err)
+	}
+	// encoding/json не экспортирует тип ошибки неизвестного поля.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return common.ErrRequestBodyParse.WithField(strings.Trim(field, `"`), "unknown_field", "").Wrap(err)
+	}
+	return common.ErrRequestBodyParse.Wrap(err)
+}
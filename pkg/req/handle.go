@@ -6,16 +6,22 @@ import (
 	"shorty/pkg/res"
 )
 
+// MaxBodyBytes - предельный размер тела запроса для HandleBody.
+var MaxBodyBytes int64 = 1 << 20
+
 // HandleBody декодирует тело запроса в структуру T и проверяет её валидность.
+// При ошибке ответ уже записан: 400 или 413 для неразобранного тела,
+// 422 со всеми нарушенными правилами по полям для невалидного.
 func HandleBody[T any](w *http.ResponseWriter, r *http.Request) (*T, error) {
+	r.Body = http.MaxBytesReader(*w, r.Body, MaxBodyBytes)
 	body, err := Decode[T](r.Body)
 	if err != nil {
-		res.JSON(*w, err.Error(), http.StatusBadRequest)
+		res.ERROR(*w, r, DecodeError(err))
 		return nil, err
 	}
 	err = IsValidate(body)
 	if err != nil {
-		res.JSON(*w, err.Error(), http.StatusBadRequest)
+		res.ERROR(*w, r, ValidationError(err))
 		return nil, err
 	}
 	return &body, nil
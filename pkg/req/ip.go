@@ -0,0 +1,28 @@
+package req
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP возвращает IP-адрес клиента. Заголовки X-Forwarded-For и X-Real-IP
+// учитываются только если trustProxy включён, иначе их легко подделать.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -1,12 +1,128 @@
 package req
 
 import (
+	"errors"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
 	"github.com/go-playground/validator/v10"
+
+	"shorty/internal/common"
 )
 
+// validate - общий экземпляр валидатора: он кеширует разбор структур,
+// поэтому создаётся один раз. Собственные правила добавляются через
+// RegisterValidation при старте приложения.
+var validate = newValidator()
+
+// aliasPattern - допустимый пользовательский код короткой ссылки.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
+// MinPasswordLength - минимальная длина пароля для правила password.
+const MinPasswordLength = 8
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// В ошибках полей используются имена из JSON, как их видит клиент.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+	v.RegisterValidation("alias", func(fl validator.FieldLevel) bool {
+		return isAlias(fl.Field().String(), nil)
+	})
+	v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return isStrongPassword(fl.Field().String())
+	})
+	// По умолчанию urlpolicy проверяет только синтаксис и схему http(s);
+	// приложение заменяет правило проверкой настроенной политикой URL.
+	v.RegisterValidation("urlpolicy", func(fl validator.FieldLevel) bool {
+		u, err := url.Parse(fl.Field().String())
+		return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+	})
+	return v
+}
+
+// RegisterValidation добавляет или заменяет правило проверки tag.
+func RegisterValidation(tag string, fn func(value string) bool) error {
+	return validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		return fn(fl.Field().String())
+	})
+}
+
+// ReserveAliases запрещает коды коротких ссылок, совпадающие с words без
+// учёта регистра. Приложение передаёт сюда первые сегменты своих маршрутов:
+// ссылка /signin никогда не дошла бы до редиректа. Повторный вызов заменяет
+// прежний список.
+func ReserveAliases(words ...string) error {
+	reserved := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		reserved[strings.ToLower(w)] = struct{}{}
+	}
+	return RegisterValidation("alias", func(value string) bool {
+		return isAlias(value, reserved)
+	})
+}
+
+// isAlias проверяет формат кода и что он не занят маршрутом приложения.
+func isAlias(value string, reserved map[string]struct{}) bool {
+	if !aliasPattern.MatchString(value) {
+		return false
+	}
+	_, taken := reserved[strings.ToLower(value)]
+	return !taken
+}
+
+// isStrongPassword требует минимальную длину, букву и цифру.
+func isStrongPassword(password string) bool {
+	if len([]rune(password)) < MinPasswordLength {
+		return false
+	}
+	var letter, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return letter && digit
+}
+
 // IsValidate проверяет валидность структуры payload.
 func IsValidate[T any](payload T) error {
-	validate := validator.New()
-	err := validate.Struct(payload)
-	return err
+	return validate.Struct(payload)
+}
+
+// ValidationError переводит ошибку валидатора в common.ErrInvalidRequest
+// со списком всех нарушенных правил по полям.
+func ValidationError(err error) *common.AppError {
+	appErr := common.ErrInvalidRequest.Wrap(err)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return appErr
+	}
+	for _, fe := range fieldErrs {
+		appErr = appErr.WithField(fieldPath(fe), fe.Tag(), fe.Param())
+	}
+	return appErr
+}
+
+// fieldPath возвращает путь поля без имени корневой структуры: "items[0].url".
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return fe.Field()
 }
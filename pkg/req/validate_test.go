@@ -0,0 +1,38 @@
+package req
+
+import (
+	"errors"
+	"testing"
+
+	"shorty/internal/common"
+)
+
+type aliasBody struct {
+	Hash string `json:"hash" validate:"omitempty,alias"`
+}
+
+func TestReserveAliases(t *testing.T) {
+	if err := ReserveAliases("signin", "static", "links"); err != nil {
+		t.Fatalf("ReserveAliases: %v", err)
+	}
+	t.Cleanup(func() { ReserveAliases() })
+
+	for _, hash := range []string{"signin", "Static", "LINKS"} {
+		err := IsValidate(aliasBody{Hash: hash})
+		if err == nil {
+			t.Errorf("alias %q passed validation", hash)
+			continue
+		}
+		if appErr := ValidationError(err); !errors.Is(appErr, common.ErrInvalidRequest) {
+			t.Errorf("alias %q: %v, want ErrInvalidRequest", hash, appErr)
+		}
+	}
+	for _, hash := range []string{"", "signin2", "my-links", "abc"} {
+		if err := IsValidate(aliasBody{Hash: hash}); err != nil {
+			t.Errorf("alias %q: %v, want nil", hash, err)
+		}
+	}
+	if err := IsValidate(aliasBody{Hash: "a b"}); err == nil {
+		t.Error("alias with a space passed validation")
+	}
+}
@@ -0,0 +1,57 @@
+package res
+
+import (
+	"net/http"
+
+	"shorty/internal/common"
+	"shorty/pkg/i18n"
+)
+
+// Problem - тело ответа об ошибке по RFC 7807. Code и Errors - расширения:
+// стабильный код ошибки и ошибки отдельных полей запроса.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`
+	Errors   []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem - ошибка поля запроса.
+type FieldProblem struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewProblem собирает тело ответа для ошибки на языке запроса.
+// Причина ошибки (AppError.Err) клиенту не передаётся.
+func NewProblem(r *http.Request, err *common.AppError) Problem {
+	locale := i18n.FromContext(r.Context())
+	status := err.Kind.Status()
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   i18n.T(locale, err.Code),
+		Instance: r.URL.Path,
+		Code:     err.Code,
+	}
+	for _, f := range err.Fields {
+		key, args := "field."+f.Code, []any{}
+		if f.Param != "" {
+			args = append(args, f.Param)
+		}
+		if !i18n.Has(key) {
+			key, args = "field.invalid", nil
+		}
+		problem.Errors = append(problem.Errors, FieldProblem{
+			Field:   f.Field,
+			Code:    f.Code,
+			Message: i18n.T(locale, key, args...),
+		})
+	}
+	return problem
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"shorty/internal/common"
+	"shorty/pkg/i18n"
 )
 
 // JSON отправляет JSON-ответ с указанным статусом.
@@ -21,14 +25,32 @@ func JSON(w http.ResponseWriter, data interface{}, status int) {
 	}
 }
 
-// ERROR отправляет JSON с сообщением об ошибке и статусом.
-func ERROR(w http.ResponseWriter, err error, statusCode int) {
-	if err != nil {
-		err = fmt.Errorf("неизвестная ошибка")
-		statusCode = http.StatusInternalServerError
+// ERROR отправляет ошибку в формате RFC 7807 (application/problem+json).
+// Статус определяется классом ошибки common.AppError, текст переводится
+// на язык запроса. Ошибки без класса отдаются как internal_error.
+func ERROR(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := common.AsAppError(err)
+	problem := NewProblem(r, appErr)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		http.Error(w, fmt.Sprintf("Ошибка кодирования JSON: %v", err), http.StatusInternalServerError)
 	}
-	if statusCode < 100 || statusCode > 599 {
-		statusCode = http.StatusInternalServerError
+}
+
+// MESSAGE отправляет переведённое сообщение об успешном действии.
+func MESSAGE(w http.ResponseWriter, r *http.Request, key string, status int) {
+	JSON(w, map[string]string{"message": i18n.T(i18n.FromContext(r.Context()), key)}, status)
+}
+
+// WantsHTML сообщает, что клиент ожидает HTML-страницу, а не JSON:
+// браузеры ставят text/html первым в заголовке Accept.
+func WantsHTML(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	htmlAt := strings.Index(accept, "text/html")
+	if htmlAt < 0 {
+		return false
 	}
-	JSON(w, map[string]string{"error": err.Error()}, statusCode)
+	jsonAt := strings.Index(accept, "application/json")
+	return jsonAt < 0 || htmlAt < jsonAt
 }
@@ -0,0 +1,128 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Counter reports how many codes of the given length are already taken.
+type Counter interface {
+	CountCodes(ctx context.Context, length int) (int64, error)
+}
+
+// PoolConfig configures a Pool.
+type PoolConfig struct {
+	Generator Generator
+	Counter   Counter
+	Length    int     // length of new codes to start with
+	MaxLength int     // length never grows past this
+	Threshold float64 // share of the keyspace in use at which the length grows
+}
+
+// Pool hands out candidate codes and keeps their length in step with how
+// full the keyspace is: once the share of taken codes of the current length
+// crosses the threshold, or the generator runs out, codes get one character
+// longer. This keeps random collisions rare without making every code long.
+type Pool struct {
+	cfg PoolConfig
+
+	mu     sync.Mutex
+	length int
+	used   int64 // taken codes of the current length
+	loaded bool  // used has been read from the Counter
+}
+
+// NewPool creates a pool. Zero config values get sensible defaults.
+func NewPool(cfg PoolConfig) *Pool {
+	if cfg.Length <= 0 {
+		cfg.Length = 7
+	}
+	if cfg.MaxLength < cfg.Length {
+		cfg.MaxLength = cfg.Length
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		cfg.Threshold = 0.1
+	}
+	return &Pool{cfg: cfg, length: cfg.Length}
+}
+
+// Next returns a candidate code of the current length.
+func (p *Pool) Next(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.fit(ctx); err != nil {
+		return "", err
+	}
+	for {
+		code, err := p.cfg.Generator.Generate(ctx, p.length)
+		if !errors.Is(err, ErrExhausted) {
+			return code, err
+		}
+		if err := p.grow(ctx); err != nil {
+			return "", err
+		}
+	}
+}
+
+// Taken records that a code from Next has been stored.
+func (p *Pool) Taken(code string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(code) == p.length {
+		p.used++
+	}
+}
+
+// Collided records that a code from Next was already taken. The count of
+// taken codes is stale then and is read again before the next code.
+func (p *Pool) Collided() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loaded = false
+}
+
+// Length returns the current code length.
+func (p *Pool) Length() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.length
+}
+
+// fit loads the count of taken codes if needed and grows the length while
+// utilisation is above the threshold.
+func (p *Pool) fit(ctx context.Context) error {
+	if !p.loaded {
+		if err := p.load(ctx); err != nil {
+			return err
+		}
+	}
+	alphabet := p.cfg.Generator.Alphabet()
+	for p.length < p.cfg.MaxLength && utilisation(alphabet, p.length, p.used) > p.cfg.Threshold {
+		if err := p.grow(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// grow makes codes one character longer.
+func (p *Pool) grow(ctx context.Context) error {
+	if p.length >= p.cfg.MaxLength {
+		return ErrExhausted
+	}
+	p.length++
+	p.loaded = false
+	return p.load(ctx)
+}
+
+// load reads how many codes of the current length are taken.
+func (p *Pool) load(ctx context.Context) error {
+	used, err := p.cfg.Counter.CountCodes(ctx, p.length)
+	if err != nil {
+		return fmt.Errorf("shortcode: counting codes of length %d: %w", p.length, err)
+	}
+	p.used, p.loaded = used, true
+	return nil
+}
@@ -0,0 +1,198 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeCounter reports fixed counts of taken codes per length and records
+// how often it was asked.
+type fakeCounter struct {
+	used  map[int]int64
+	calls map[int]int
+	err   error
+}
+
+func newFakeCounter(used map[int]int64) *fakeCounter {
+	return &fakeCounter{used: used, calls: make(map[int]int)}
+}
+
+func (c *fakeCounter) CountCodes(_ context.Context, length int) (int64, error) {
+	c.calls[length]++
+	return c.used[length], c.err
+}
+
+// fakeGenerator returns codes of "a" over a two-letter alphabet, or
+// ErrExhausted for the lengths listed in exhausted.
+type fakeGenerator struct {
+	exhausted map[int]bool
+}
+
+func (g *fakeGenerator) Generate(_ context.Context, length int) (string, error) {
+	if g.exhausted[length] {
+		return "", ErrExhausted
+	}
+	return strings.Repeat("a", length), nil
+}
+
+func (g *fakeGenerator) Alphabet() string {
+	return "ab"
+}
+
+func TestPoolDefaults(t *testing.T) {
+	p := NewPool(PoolConfig{Generator: &fakeGenerator{}, Counter: newFakeCounter(nil), MaxLength: 3})
+	if p.cfg.Length != 7 || p.cfg.MaxLength != 7 || p.cfg.Threshold != 0.1 {
+		t.Errorf("defaults = %+v", p.cfg)
+	}
+}
+
+func TestPoolGrowsAtThreshold(t *testing.T) {
+	// Length 4 over "ab" has 16 codes; the threshold is 0.5, so 8 taken
+	// codes are fine and 9 are too many.
+	tests := []struct {
+		name string
+		used map[int]int64
+		want int
+	}{
+		{"below", map[int]int64{4: 7}, 4},
+		{"at", map[int]int64{4: 8}, 4},
+		{"above", map[int]int64{4: 9}, 5},
+		{"two steps", map[int]int64{4: 16, 5: 20}, 6},
+		{"capped", map[int]int64{4: 16, 5: 32, 6: 64}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPool(PoolConfig{
+				Generator: &fakeGenerator{},
+				Counter:   newFakeCounter(tt.used),
+				Length:    4,
+				MaxLength: 6,
+				Threshold: 0.5,
+			})
+			code, err := p.Next(context.Background())
+			if err != nil {
+				t.Fatalf("Next: %v", err)
+			}
+			if len(code) != tt.want || p.Length() != tt.want {
+				t.Errorf("Next = %q, Length = %d, want length %d", code, p.Length(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolTakenGrowsWithoutReload(t *testing.T) {
+	counter := newFakeCounter(map[int]int64{4: 7})
+	p := NewPool(PoolConfig{Generator: &fakeGenerator{}, Counter: counter, Length: 4, MaxLength: 6, Threshold: 0.5})
+	for i := 0; i < 2; i++ {
+		code, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != 4 {
+			t.Fatalf("code %d = %q, want length 4", i, code)
+		}
+		p.Taken(code)
+	}
+	// 9 of 16 taken now, counted locally.
+	code, err := p.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 5 {
+		t.Errorf("Next after crossing the threshold = %q, want length 5", code)
+	}
+	if counter.calls[4] != 1 {
+		t.Errorf("codes of length 4 were counted %d times, want 1", counter.calls[4])
+	}
+	// A code of another length does not count towards the current one.
+	p.Taken("aaaa")
+	if p.used != 0 {
+		t.Errorf("used = %d after a code of the old length, want 0", p.used)
+	}
+}
+
+func TestPoolCollidedReloads(t *testing.T) {
+	counter := newFakeCounter(map[int]int64{4: 2})
+	p := NewPool(PoolConfig{Generator: &fakeGenerator{}, Counter: counter, Length: 4, MaxLength: 6, Threshold: 0.5})
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Next(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if counter.calls[4] != 1 {
+		t.Fatalf("count read %d times before a collision, want 1", counter.calls[4])
+	}
+
+	// Other instances filled the keyspace meanwhile.
+	counter.used[4] = 12
+	p.Collided()
+	code, err := p.Next(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter.calls[4] != 2 {
+		t.Errorf("count read %d times after a collision, want 2", counter.calls[4])
+	}
+	if len(code) != 5 {
+		t.Errorf("Next after the reload = %q, want length 5", code)
+	}
+}
+
+func TestPoolGrowsWhenGeneratorIsExhausted(t *testing.T) {
+	gen := &fakeGenerator{exhausted: map[int]bool{4: true}}
+	p := NewPool(PoolConfig{Generator: gen, Counter: newFakeCounter(nil), Length: 4, MaxLength: 5, Threshold: 0.5})
+	code, err := p.Next(context.Background())
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if len(code) != 5 {
+		t.Errorf("Next = %q, want length 5", code)
+	}
+
+	// Nothing left at MaxLength either.
+	gen.exhausted[5] = true
+	if _, err := p.Next(context.Background()); !errors.Is(err, ErrExhausted) {
+		t.Errorf("Next at MaxLength error = %v, want ErrExhausted", err)
+	}
+	if p.Length() != 5 {
+		t.Errorf("Length = %d, grew past MaxLength", p.Length())
+	}
+}
+
+func TestPoolCounterError(t *testing.T) {
+	boom := errors.New("boom")
+	counter := newFakeCounter(nil)
+	counter.err = boom
+	p := NewPool(PoolConfig{Generator: &fakeGenerator{}, Counter: counter, Length: 4})
+	if _, err := p.Next(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("Next error = %v, want the counter error", err)
+	}
+}
+
+func TestPoolWithHashids(t *testing.T) {
+	// The sequence runs past the 4-character keyspace of "ab", so the pool
+	// moves on to longer codes without handing out a duplicate.
+	g, err := NewHashids(&counterSequence{}, "ab", "salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPool(PoolConfig{Generator: g, Counter: newFakeCounter(nil), Length: 4, MaxLength: 8, Threshold: 1})
+	seen := make(map[string]bool)
+	for i := 0; i < 40; i++ {
+		code, err := p.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next %d: %v", i, err)
+		}
+		if seen[code] {
+			t.Fatalf("code %q handed out twice", code)
+		}
+		seen[code] = true
+		p.Taken(code)
+	}
+	if p.Length() <= 4 {
+		t.Errorf("Length = %d after 40 codes, want longer than 4", p.Length())
+	}
+}
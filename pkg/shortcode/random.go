@@ -0,0 +1,42 @@
+package shortcode
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Random draws every character of a code uniformly from the alphabet using
+// crypto/rand. Codes are unpredictable, so collisions grow with utilisation.
+type Random struct {
+	alphabet string
+}
+
+// NewRandom creates a random generator over the alphabet.
+func NewRandom(alphabet string) (*Random, error) {
+	if err := validAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+	return &Random{alphabet: alphabet}, nil
+}
+
+// Generate returns a random code of the given length. It fails only when the
+// system entropy source does.
+func (g *Random) Generate(_ context.Context, length int) (string, error) {
+	max := big.NewInt(int64(len(g.alphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("shortcode: reading random data: %w", err)
+		}
+		code[i] = g.alphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Alphabet returns the characters codes are drawn from.
+func (g *Random) Alphabet() string {
+	return g.alphabet
+}
@@ -0,0 +1,108 @@
+package shortcode
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+)
+
+// Sequence hands out increasing numbers, one per generated code.
+// A database sequence is the usual implementation.
+type Sequence interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// SequenceFunc adapts a function to the Sequence interface.
+type SequenceFunc func(ctx context.Context) (uint64, error)
+
+// Next calls f(ctx).
+func (f SequenceFunc) Next(ctx context.Context) (uint64, error) {
+	return f(ctx)
+}
+
+// Hashids turns numbers from a Sequence into codes in the spirit of Hashids:
+// the alphabet is shuffled with a salt and the number is scrambled by an
+// affine bijection over the keyspace, so consecutive links get unrelated
+// codes. Two numbers never map to the same code of one length, so collisions
+// are only possible with custom aliases.
+type Hashids struct {
+	seq      Sequence
+	alphabet string // shuffled with the salt
+	salt     string
+}
+
+// NewHashids creates a sequence-based generator over the alphabet.
+func NewHashids(seq Sequence, alphabet, salt string) (*Hashids, error) {
+	if err := validAlphabet(alphabet); err != nil {
+		return nil, err
+	}
+	return &Hashids{seq: seq, alphabet: shuffle(alphabet, salt), salt: salt}, nil
+}
+
+// Generate takes the next number from the sequence and encodes it as a code
+// of the given length. ErrExhausted means the number does not fit.
+func (g *Hashids) Generate(ctx context.Context, length int) (string, error) {
+	n, err := g.seq.Next(ctx)
+	if err != nil {
+		return "", fmt.Errorf("shortcode: reading sequence: %w", err)
+	}
+	space := Keyspace(g.alphabet, length)
+	x := new(big.Int).SetUint64(n)
+	if x.Cmp(space) >= 0 {
+		return "", ErrExhausted
+	}
+	mul, add := g.affine(space, length)
+	x.Mul(x, mul).Add(x, add).Mod(x, space)
+
+	base := big.NewInt(int64(len(g.alphabet)))
+	digit := new(big.Int)
+	code := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		x.DivMod(x, base, digit)
+		code[i] = g.alphabet[digit.Int64()]
+	}
+	return string(code), nil
+}
+
+// Alphabet returns the characters codes are drawn from.
+func (g *Hashids) Alphabet() string {
+	return g.alphabet
+}
+
+// affine derives from the salt a multiplier coprime with the keyspace and an
+// offset, which makes x -> x*mul + add a bijection modulo the keyspace.
+func (g *Hashids) affine(space *big.Int, length int) (mul, add *big.Int) {
+	mul = new(big.Int).Mod(saltHash(g.salt, "mul", length), space)
+	one := big.NewInt(1)
+	for mul.Sign() == 0 || new(big.Int).GCD(nil, nil, mul, space).Cmp(one) != 0 {
+		mul.Add(mul, one).Mod(mul, space)
+	}
+	add = new(big.Int).Mod(saltHash(g.salt, "add", length), space)
+	return mul, add
+}
+
+// saltHash hashes the salt together with a purpose and the code length.
+func saltHash(salt, purpose string, length int) *big.Int {
+	h := fnv.New128a()
+	fmt.Fprintf(h, "%s|%s|%d", salt, purpose, length)
+	return new(big.Int).SetBytes(h.Sum(nil))
+}
+
+// shuffle permutes the alphabet deterministically with the salt, the same
+// way Hashids does.
+func shuffle(alphabet, salt string) string {
+	if salt == "" {
+		return alphabet
+	}
+	a := []byte(alphabet)
+	for i, v, p := len(a)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		c := int(salt[v])
+		p += c
+		j := (c + v + p) % i
+		a[i], a[j] = a[j], a[i]
+		v++
+	}
+	return string(a)
+}
@@ -0,0 +1,110 @@
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+// counterSequence returns 0, 1, 2... from Next.
+type counterSequence struct{ n uint64 }
+
+func (s *counterSequence) Next(context.Context) (uint64, error) {
+	n := s.n
+	s.n++
+	return n, nil
+}
+
+func TestHashidsIsBijection(t *testing.T) {
+	for _, salt := range []string{"", "pepper", "another salt"} {
+		g, err := NewHashids(&counterSequence{}, "abcdef", salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 6^3 = 216 codes of length 3: the first 216 numbers use each once.
+		seen := make(map[string]uint64)
+		for n := uint64(0); n < 216; n++ {
+			code, err := g.Generate(context.Background(), 3)
+			if err != nil {
+				t.Fatalf("salt %q: Generate(%d): %v", salt, n, err)
+			}
+			if len(code) != 3 {
+				t.Fatalf("salt %q: Generate(%d) = %q", salt, n, code)
+			}
+			if prev, ok := seen[code]; ok {
+				t.Fatalf("salt %q: numbers %d and %d both map to %q", salt, prev, n, code)
+			}
+			seen[code] = n
+		}
+		// The next number does not fit.
+		if _, err := g.Generate(context.Background(), 3); !errors.Is(err, ErrExhausted) {
+			t.Errorf("salt %q: Generate past the keyspace error = %v, want ErrExhausted", salt, err)
+		}
+	}
+}
+
+func TestHashidsScramblesAndDependsOnSalt(t *testing.T) {
+	codes := func(salt string) []string {
+		g, err := NewHashids(&counterSequence{}, Base62, salt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for i := 0; i < 5; i++ {
+			code, err := g.Generate(context.Background(), 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, code)
+		}
+		return out
+	}
+	a, b := codes("one"), codes("two")
+	prefixes := make(map[string]bool)
+	for _, code := range a {
+		prefixes[code[:6]] = true
+	}
+	if len(prefixes) == 1 {
+		t.Errorf("consecutive numbers give codes that differ only in the last character: %v", a)
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	if same == len(a) {
+		t.Errorf("different salts give the same codes: %v", a)
+	}
+	// Same salt, same codes.
+	if c := codes("one"); c[0] != a[0] || c[4] != a[4] {
+		t.Errorf("the same salt gives other codes: %v and %v", a, c)
+	}
+}
+
+func TestHashidsShuffle(t *testing.T) {
+	if got := shuffle(Base62, ""); got != Base62 {
+		t.Errorf("shuffle without a salt = %q", got)
+	}
+	got := shuffle(Base62, "salt")
+	if got == Base62 {
+		t.Error("shuffle with a salt kept the order")
+	}
+	sorted := []byte(got)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	if string(sorted) != Base62 {
+		t.Errorf("shuffle is not a permutation: %q", got)
+	}
+}
+
+func TestHashidsSequenceError(t *testing.T) {
+	boom := errors.New("boom")
+	g, err := NewHashids(SequenceFunc(func(context.Context) (uint64, error) { return 0, boom }), Base62, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Generate(context.Background(), 7); !errors.Is(err, boom) {
+		t.Errorf("Generate error = %v, want the sequence error", err)
+	}
+}
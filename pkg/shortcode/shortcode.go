@@ -0,0 +1,71 @@
+// Package shortcode generates the short codes that identify links.
+package shortcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Alphabets for generated codes.
+const (
+	// Base62 is the default alphabet: digits and both letter cases.
+	Base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// Readable is a lowercase alphabet without characters that are easily
+	// confused when printed or read aloud (0/o, 1/l/i).
+	Readable = "23456789abcdefghjkmnpqrstuvwxyz"
+)
+
+// ErrExhausted is returned by a generator that has no codes of the requested
+// length left.
+var ErrExhausted = errors.New("shortcode: keyspace exhausted")
+
+// Generator produces candidate codes of a given length. Codes are not
+// guaranteed to be free: the caller stores them and retries on collision.
+type Generator interface {
+	Generate(ctx context.Context, length int) (string, error)
+	// Alphabet returns the characters the generator draws codes from.
+	Alphabet() string
+}
+
+// Alphabet returns the alphabet with the given name: "base62" or "readable".
+func Alphabet(name string) (string, error) {
+	switch name {
+	case "", "base62":
+		return Base62, nil
+	case "readable":
+		return Readable, nil
+	}
+	return "", fmt.Errorf("shortcode: unknown alphabet %q", name)
+}
+
+// Keyspace returns how many codes of the given length the alphabet allows.
+func Keyspace(alphabet string, length int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(int64(len(alphabet))), big.NewInt(int64(length)), nil)
+}
+
+// utilisation returns the share of the keyspace taken by used codes.
+func utilisation(alphabet string, length int, used int64) float64 {
+	space, _ := new(big.Float).SetInt(Keyspace(alphabet, length)).Float64()
+	if math.IsInf(space, 1) {
+		return 0
+	}
+	return float64(used) / space
+}
+
+// validAlphabet checks that an alphabet has at least two distinct characters.
+func validAlphabet(alphabet string) error {
+	if len(alphabet) < 2 {
+		return fmt.Errorf("shortcode: alphabet %q is too short", alphabet)
+	}
+	seen := make(map[byte]bool, len(alphabet))
+	for i := 0; i < len(alphabet); i++ {
+		if seen[alphabet[i]] {
+			return fmt.Errorf("shortcode: alphabet has a repeated character %q", alphabet[i])
+		}
+		seen[alphabet[i]] = true
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package shortcode
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAlphabet(t *testing.T) {
+	for name, want := range map[string]string{"": Base62, "base62": Base62, "readable": Readable} {
+		if got, err := Alphabet(name); err != nil || got != want {
+			t.Errorf("Alphabet(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := Alphabet("hex"); err == nil {
+		t.Error("Alphabet(\"hex\") succeeded")
+	}
+	for _, c := range "01lio" {
+		if strings.ContainsRune(Readable, c) {
+			t.Errorf("Readable contains the ambiguous %q", c)
+		}
+	}
+}
+
+func TestKeyspaceAndUtilisation(t *testing.T) {
+	if got := Keyspace("ab", 10).Int64(); got != 1024 {
+		t.Errorf("Keyspace(ab, 10) = %d, want 1024", got)
+	}
+	if got := Keyspace(Base62, 7).String(); got != "3521614606208" {
+		t.Errorf("Keyspace(base62, 7) = %s, want 3521614606208", got)
+	}
+	if got := utilisation("ab", 3, 2); got != 0.25 {
+		t.Errorf("utilisation = %v, want 0.25", got)
+	}
+	// A keyspace too large for a float64 counts as empty.
+	if got := utilisation(Base62, 200, 1<<62); got != 0 {
+		t.Errorf("utilisation of a huge keyspace = %v, want 0", got)
+	}
+}
+
+func TestValidAlphabet(t *testing.T) {
+	for _, alphabet := range []string{"", "a", "abca"} {
+		if _, err := NewRandom(alphabet); err == nil {
+			t.Errorf("NewRandom(%q) succeeded", alphabet)
+		}
+		if _, err := NewHashids(nil, alphabet, ""); err == nil {
+			t.Errorf("NewHashids(%q) succeeded", alphabet)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	g, err := NewRandom(Readable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Alphabet() != Readable {
+		t.Errorf("Alphabet() = %q", g.Alphabet())
+	}
+	seen := make(map[string]bool)
+	counts := make(map[rune]int)
+	for i := 0; i < 1000; i++ {
+		code, err := g.Generate(context.Background(), 8)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if len(code) != 8 {
+			t.Fatalf("Generate(8) = %q", code)
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(Readable, c) {
+				t.Fatalf("Generate = %q, %q is not in the alphabet", code, c)
+			}
+			counts[c]++
+		}
+		if seen[code] {
+			t.Fatalf("Generate repeated %q within 1000 codes of 31^8", code)
+		}
+		seen[code] = true
+	}
+	// 8000 characters over 31 symbols: every symbol shows up.
+	if len(counts) != len(Readable) {
+		t.Errorf("only %d of %d characters were drawn", len(counts), len(Readable))
+	}
+}
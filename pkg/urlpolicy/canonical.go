@@ -0,0 +1,75 @@
+package urlpolicy
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that only carry campaign or click
+// tracking data and do not change what a URL points to.
+var trackingParams = map[string]bool{
+	"fbclid":    true,
+	"gclid":     true,
+	"dclid":     true,
+	"msclkid":   true,
+	"yclid":     true,
+	"mc_cid":    true,
+	"mc_eid":    true,
+	"igshid":    true,
+	"_openstat": true,
+}
+
+// IsTrackingParam reports whether a query parameter is a tracking one:
+// any utm_* parameter or a known click identifier.
+func IsTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// Canonical returns the canonical form of rawURL, used to tell whether two
+// URLs point to the same resource: the scheme and host are lowercased, the
+// default port and a trailing dot on the host are dropped, an empty path
+// becomes "/" and query parameters are sorted by name. With stripTracking
+// the tracking parameters are removed as well. The fragment is kept, since
+// client-side applications route on it.
+func Canonical(rawURL string, stripTracking bool) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host, port := strings.TrimSuffix(strings.ToLower(u.Hostname()), "."), u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	u.Host = host
+
+	if u.Path == "" && u.Opaque == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	if stripTracking {
+		for name := range query {
+			if IsTrackingParam(name) {
+				delete(query, name)
+			}
+		}
+	}
+	u.RawQuery = query.Encode() // Encode sorts by name
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// defaultPorts are the ports implied by a scheme.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
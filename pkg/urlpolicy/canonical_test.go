@@ -0,0 +1,67 @@
+package urlpolicy
+
+import "testing"
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		in            string
+		stripTracking bool
+		want          string
+	}{
+		{"HTTPS://Example.COM", false, "https://example.com/"},
+		{"  https://example.com/a  ", false, "https://example.com/a"},
+		{"https://example.com.:443/a", false, "https://example.com/a"},
+		{"http://example.com:80/", false, "http://example.com/"},
+		{"http://example.com:443/", false, "http://example.com:443/"},
+		{"https://example.com:8443/", false, "https://example.com:8443/"},
+		{"http://[::1]:80/x", false, "http://[::1]/x"},
+		{"http://[::1]:8080/x", false, "http://[::1]:8080/x"},
+		{"https://example.com/?b=2&a=1&a=0", false, "https://example.com/?a=1&a=0&b=2"},
+		{"https://example.com/?", false, "https://example.com/"},
+		{"https://example.com/Path/", false, "https://example.com/Path/"},
+		{"https://example.com/#/route", false, "https://example.com/#/route"},
+		{"https://example.com/?utm_source=x&id=1&FBCLID=y", false, "https://example.com/?FBCLID=y&id=1&utm_source=x"},
+		{"https://example.com/?utm_source=x&id=1&FBCLID=y&gclid=z", true, "https://example.com/?id=1"},
+		{"https://example.com/?UTM_Campaign=x", true, "https://example.com/"},
+		{"mailto:User@Example.com", false, "mailto:User@Example.com"},
+	}
+	for _, tt := range tests {
+		got, err := Canonical(tt.in, tt.stripTracking)
+		if err != nil {
+			t.Errorf("Canonical(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%q, %v) = %q, want %q", tt.in, tt.stripTracking, got, tt.want)
+		}
+	}
+	if _, err := Canonical("http://[::1", false); err == nil {
+		t.Error("Canonical accepted a malformed URL")
+	}
+}
+
+func TestCanonicalSameResource(t *testing.T) {
+	a, _ := Canonical("https://Example.com:443?b=1&a=2&utm_medium=email", true)
+	b, _ := Canonical("https://example.com./?a=2&b=1&fbclid=abc", true)
+	if a != b {
+		t.Errorf("equivalent URLs differ: %q and %q", a, b)
+	}
+}
+
+func TestIsTrackingParam(t *testing.T) {
+	for name, want := range map[string]bool{
+		"utm_source": true,
+		"UTM_TERM":   true,
+		"utm_":       true,
+		"gclid":      true,
+		"MsClkId":    true,
+		"_openstat":  true,
+		"utm":        false,
+		"id":         false,
+		"ref":        false,
+	} {
+		if got := IsTrackingParam(name); got != want {
+			t.Errorf("IsTrackingParam(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package urlpolicy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// domainList is a set of domains backed by a text file with one domain per
+// line. Empty lines and lines starting with "#" are ignored when matching but
+// kept, together with the order of the entries, when the file is rewritten.
+type domainList struct {
+	path    string
+	domains map[string]struct{}
+	lines   []string // file contents as read or last written
+}
+
+// loadDomainList reads the list from path. A missing file is an empty list.
+func loadDomainList(path string) (*domainList, error) {
+	l := &domainList{path: path, domains: make(map[string]struct{})}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain list %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		l.lines = append(l.lines, scanner.Text())
+		if domain, ok := lineDomain(scanner.Text()); ok {
+			l.domains[domain] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// lineDomain returns the domain on a list file line. Comments, empty lines
+// and invalid entries have none.
+func lineDomain(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	domain, err := NormalizeDomain(line)
+	return domain, err == nil
+}
+
+// matches reports whether host or one of its parent domains is on the list.
+func (l *domainList) matches(host string) bool {
+	for host != "" {
+		if _, ok := l.domains[host]; ok {
+			return true
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return false
+		}
+		host = parent
+	}
+	return false
+}
+
+// sorted returns the domains in alphabetical order.
+func (l *domainList) sorted() []string {
+	return sortedDomains(l.domains)
+}
+
+func sortedDomains(set map[string]struct{}) []string {
+	domains := make([]string, 0, len(set))
+	for d := range set {
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// add adds a domain to the list. The file is written first, so a failed
+// write leaves the list as it was.
+func (l *domainList) add(domain string) error {
+	if _, ok := l.domains[domain]; ok {
+		return nil
+	}
+	domains := maps.Clone(l.domains)
+	domains[domain] = struct{}{}
+	return l.replace(domains)
+}
+
+// remove removes a domain from the list, saving the file first like add.
+func (l *domainList) remove(domain string) error {
+	if _, ok := l.domains[domain]; !ok {
+		return nil
+	}
+	domains := maps.Clone(l.domains)
+	delete(domains, domain)
+	return l.replace(domains)
+}
+
+// replace saves domains to the file and then makes them the list.
+func (l *domainList) replace(domains map[string]struct{}) error {
+	lines := l.render(domains)
+	if err := l.save(lines); err != nil {
+		return err
+	}
+	l.domains = domains
+	l.lines = lines
+	return nil
+}
+
+// render returns the file lines for domains: the current lines without the
+// removed domains, followed by the new domains in alphabetical order.
+// Comments, empty lines and lines that are not valid domains stay in place.
+func (l *domainList) render(domains map[string]struct{}) []string {
+	lines := make([]string, 0, len(l.lines)+len(domains))
+	written := make(map[string]struct{}, len(domains))
+	for _, line := range l.lines {
+		domain, ok := lineDomain(line)
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		if _, keep := domains[domain]; !keep {
+			continue
+		}
+		if _, dup := written[domain]; dup {
+			continue
+		}
+		written[domain] = struct{}{}
+		lines = append(lines, line)
+	}
+	for _, d := range sortedDomains(domains) {
+		if _, ok := written[d]; !ok {
+			lines = append(lines, d)
+		}
+	}
+	return lines
+}
+
+// save writes lines to the list file atomically.
+func (l *domainList) save(lines []string) error {
+	if l.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", l.path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save domain list %s: %w", l.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save domain list %s: %w", l.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save domain list %s: %w", l.path, err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("failed to save domain list %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// NormalizeDomain lowercases a domain and strips a trailing dot and a
+// leading "*." so that "*.Example.com." and "example.com" are the same entry.
+func NormalizeDomain(domain string) (string, error) {
+	d := strings.ToLower(strings.TrimSpace(domain))
+	d = strings.TrimPrefix(d, "*.")
+	d = strings.TrimSuffix(d, ".")
+	if d == "" || strings.ContainsAny(d, "/:@ \t") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDomain, domain)
+	}
+	return d, nil
+}
@@ -0,0 +1,355 @@
+// Package urlpolicy decides whether a destination URL may be shortened.
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rejection reasons.
+const (
+	ReasonInvalidURL     = "invalid_url"
+	ReasonScheme         = "scheme_not_allowed"
+	ReasonPrivateAddress = "private_address"
+	ReasonBlockedDomain  = "blocked_domain"
+	ReasonReputation     = "reputation"
+	ReasonUnresolved     = "unresolved_host"
+	ReasonSelfLink       = "self_link"
+)
+
+// List names a domain list managed by the policy.
+type List string
+
+const (
+	Blocklist List = "blocklist"
+	Allowlist List = "allowlist"
+)
+
+var (
+	// ErrUnknownList is returned for a list name other than Blocklist or Allowlist.
+	ErrUnknownList = errors.New("unknown domain list")
+	// ErrInvalidDomain is returned for a value that is not a domain name.
+	ErrInvalidDomain = errors.New("invalid domain")
+	// ErrInvalidIPv4 is returned for a host that looks like an IPv4 address but is not a valid one.
+	ErrInvalidIPv4 = errors.New("invalid IPv4 address")
+)
+
+// Violation is returned when a URL is rejected by the policy.
+type Violation struct {
+	Reason string
+	Detail string
+}
+
+func (v *Violation) Error() string {
+	if v.Detail == "" {
+		return "url rejected: " + v.Reason
+	}
+	return "url rejected: " + v.Reason + ": " + v.Detail
+}
+
+// Checker is a hook for external reputation checks (Safe Browsing, internal
+// threat feeds and so on). It returns a *Violation to reject the URL; any
+// other error is treated as the checker being unavailable and is ignored.
+type Checker interface {
+	Check(ctx context.Context, u *url.URL) error
+}
+
+// CheckerFunc adapts a function to the Checker interface.
+type CheckerFunc func(ctx context.Context, u *url.URL) error
+
+// Check calls f(ctx, u).
+func (f CheckerFunc) Check(ctx context.Context, u *url.URL) error {
+	return f(ctx, u)
+}
+
+// Config configures a Policy.
+type Config struct {
+	AllowedSchemes []string
+	BlockPrivate   bool          // reject loopback, private, link-local and similar targets
+	ResolveHosts   bool          // also resolve host names and check the addresses
+	ResolveTimeout time.Duration // timeout for resolving a host name
+	BlocklistPath  string
+	AllowlistPath  string
+	SelfHosts      []string // hosts served by this instance; a link to them would loop
+}
+
+// Policy checks destination URLs. Domains on the allowlist skip the
+// blocklist and reputation checks but not the scheme, self-link and address
+// checks.
+type Policy struct {
+	cfg      Config
+	resolver *net.Resolver
+
+	mu       sync.RWMutex
+	lists    map[List]*domainList
+	checkers []Checker
+}
+
+// New creates a policy and loads its domain lists from the configured files.
+func New(cfg Config, checkers ...Checker) (*Policy, error) {
+	blocklist, err := loadDomainList(cfg.BlocklistPath)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := loadDomainList(cfg.AllowlistPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{
+		cfg:      cfg,
+		resolver: net.DefaultResolver,
+		lists:    map[List]*domainList{Blocklist: blocklist, Allowlist: allowlist},
+		checkers: checkers,
+	}, nil
+}
+
+// AddChecker registers an external reputation check.
+func (p *Policy) AddChecker(c Checker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checkers = append(p.checkers, c)
+}
+
+// Check returns a *Violation if rawURL must not be shortened.
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	u, err := p.parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if slices.Contains(p.cfg.SelfHosts, host) {
+		return &Violation{Reason: ReasonSelfLink, Detail: host}
+	}
+	if p.cfg.BlockPrivate {
+		if err := p.checkAddress(ctx, host); err != nil {
+			return err
+		}
+	}
+
+	p.mu.RLock()
+	allowed := p.lists[Allowlist].matches(host)
+	blocked := p.lists[Blocklist].matches(host)
+	checkers := slices.Clone(p.checkers)
+	p.mu.RUnlock()
+
+	if allowed {
+		return nil
+	}
+	if blocked {
+		return &Violation{Reason: ReasonBlockedDomain, Detail: host}
+	}
+	for _, c := range checkers {
+		var v *Violation
+		if err := c.Check(ctx, u); errors.As(err, &v) {
+			if v.Reason == "" {
+				v.Reason = ReasonReputation
+			}
+			return v
+		}
+	}
+	return nil
+}
+
+// CheckSyntax performs only the checks that need no lookups: the URL must
+// parse, use an allowed scheme and have a host. It suits request validation;
+// Check must still run before the URL is stored.
+func (p *Policy) CheckSyntax(rawURL string) error {
+	_, err := p.parse(rawURL)
+	return err
+}
+
+func (p *Policy) parse(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, &Violation{Reason: ReasonInvalidURL}
+	}
+	if !slices.Contains(p.cfg.AllowedSchemes, strings.ToLower(u.Scheme)) {
+		return nil, &Violation{Reason: ReasonScheme, Detail: u.Scheme}
+	}
+	if u.Host == "" {
+		return nil, &Violation{Reason: ReasonInvalidURL}
+	}
+	return u, nil
+}
+
+// Domains returns the domains on a list.
+func (p *Policy) Domains(list List) ([]string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	l, ok := p.lists[list]
+	if !ok {
+		return nil, ErrUnknownList
+	}
+	return l.sorted(), nil
+}
+
+// AddDomain adds a domain to a list and saves the list file.
+func (p *Policy) AddDomain(list List, domain string) (string, error) {
+	d, err := NormalizeDomain(domain)
+	if err != nil {
+		return "", err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.lists[list]
+	if !ok {
+		return "", ErrUnknownList
+	}
+	if err := l.add(d); err != nil {
+		return "", err
+	}
+	return d, nil
+}
+
+// RemoveDomain removes a domain from a list and saves the list file.
+func (p *Policy) RemoveDomain(list List, domain string) error {
+	d, err := NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l, ok := p.lists[list]
+	if !ok {
+		return ErrUnknownList
+	}
+	return l.remove(d)
+}
+
+// checkAddress rejects hosts that are or resolve to non-public addresses.
+func (p *Policy) checkAddress(ctx context.Context, host string) error {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &Violation{Reason: ReasonPrivateAddress, Detail: host}
+	}
+	if addr, ok, err := parseHostAddr(host); ok {
+		if err != nil || !IsPublicAddr(addr) {
+			return &Violation{Reason: ReasonPrivateAddress, Detail: host}
+		}
+		return nil
+	}
+	if !p.cfg.ResolveHosts {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.ResolveTimeout)
+	defer cancel()
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		// Without the addresses there is no telling where the name leads,
+		// and it may well resolve to a private one by the time of the
+		// redirect.
+		return &Violation{Reason: ReasonUnresolved, Detail: host}
+	}
+	for _, addr := range addrs {
+		if !IsPublicAddr(addr) {
+			return &Violation{Reason: ReasonPrivateAddress, Detail: fmt.Sprintf("%s resolves to %s", host, addr)}
+		}
+	}
+	return nil
+}
+
+// parseHostAddr reports whether host is an IP address literal and parses
+// it. Besides the canonical forms it accepts the IPv4 shorthands that
+// browsers and inet_aton understand: fewer than four parts, the last one
+// filling the remaining bytes ("127.1", "2130706433"), and hexadecimal or
+// octal parts ("0x7f.0.0.1", "0177.0.0.1"). A host that looks like an IPv4
+// address but is out of range is reported with an error; browsers refuse
+// such hosts, and so must the policy.
+func parseHostAddr(host string) (netip.Addr, bool, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr, true, nil
+	}
+	parts := strings.Split(host, ".")
+	// As in the URL standard, the host is an IPv4 address if its last
+	// part is a number.
+	if _, err := parseIPv4Part(parts[len(parts)-1]); err != nil {
+		return netip.Addr{}, false, nil
+	}
+	if len(parts) > 4 {
+		return netip.Addr{}, true, ErrInvalidIPv4
+	}
+	var n uint64
+	for i, part := range parts {
+		v, err := parseIPv4Part(part)
+		if err != nil {
+			return netip.Addr{}, true, err
+		}
+		if i < len(parts)-1 {
+			if v > 0xff {
+				return netip.Addr{}, true, ErrInvalidIPv4
+			}
+			n |= v << (8 * (3 - i))
+			continue
+		}
+		if v >= 1<<(8*(4-i)) {
+			return netip.Addr{}, true, ErrInvalidIPv4
+		}
+		n |= v
+	}
+	return netip.AddrFrom4([4]byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}), true, nil
+}
+
+// parseIPv4Part parses one part of an IPv4 address: decimal, hexadecimal
+// with a 0x prefix or octal with a leading zero.
+func parseIPv4Part(part string) (uint64, error) {
+	base := 10
+	switch {
+	case len(part) >= 2 && (part[:2] == "0x" || part[:2] == "0X"):
+		part, base = part[2:], 16
+		if part == "" {
+			return 0, nil
+		}
+	case len(part) > 1 && part[0] == '0':
+		part, base = part[1:], 8
+	}
+	if part == "" || part[0] == '+' || part[0] == '-' {
+		return 0, ErrInvalidIPv4
+	}
+	v, err := strconv.ParseUint(part, base, 64)
+	if err != nil {
+		return 0, ErrInvalidIPv4
+	}
+	return v, nil
+}
+
+// nonPublicPrefixes are ranges that are not covered by the netip helpers
+// but must not be reachable through a short link.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, may embed private IPv4
+	netip.MustParsePrefix("2001:db8::/32"), // documentation
+}
+
+// IsPublicAddr reports whether addr is a globally routable unicast address.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() ||
+		addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() ||
+		addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,261 @@
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestPolicy(t *testing.T, cfg Config) *Policy {
+	t.Helper()
+	if cfg.AllowedSchemes == nil {
+		cfg.AllowedSchemes = []string{"http", "https"}
+	}
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return p
+}
+
+func reason(err error) string {
+	var v *Violation
+	if errors.As(err, &v) {
+		return v.Reason
+	}
+	return ""
+}
+
+func TestParseHostAddr(t *testing.T) {
+	tests := []struct {
+		host   string
+		want   string
+		isAddr bool
+		err    bool
+	}{
+		{host: "127.0.0.1", want: "127.0.0.1", isAddr: true},
+		{host: "2130706433", want: "127.0.0.1", isAddr: true},
+		{host: "127.1", want: "127.0.0.1", isAddr: true},
+		{host: "10.1.2", want: "10.1.0.2", isAddr: true},
+		{host: "0x7f000001", want: "127.0.0.1", isAddr: true},
+		{host: "0X7F.0.0.1", want: "127.0.0.1", isAddr: true},
+		{host: "017700000001", want: "127.0.0.1", isAddr: true},
+		{host: "0177.0.0.01", want: "127.0.0.1", isAddr: true},
+		{host: "0", want: "0.0.0.0", isAddr: true},
+		{host: "0x", want: "0.0.0.0", isAddr: true},
+		{host: "8.8.8.8", want: "8.8.8.8", isAddr: true},
+		{host: "::1", want: "::1", isAddr: true},
+		{host: "4294967296", isAddr: true, err: true},
+		{host: "256.0.0.1", isAddr: true, err: true},
+		{host: "1.2.65536", isAddr: true, err: true},
+		{host: "1.2.3.4.5", isAddr: true, err: true},
+		{host: "08.1.1.1", isAddr: true, err: true},
+		{host: "foo.1", isAddr: true, err: true},
+		{host: "example.com", isAddr: false},
+		{host: "1.2.3.com", isAddr: false},
+		{host: "0x7g", isAddr: false},
+		{host: "", isAddr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			addr, isAddr, err := parseHostAddr(tt.host)
+			if isAddr != tt.isAddr {
+				t.Fatalf("isAddr = %v, want %v", isAddr, tt.isAddr)
+			}
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if tt.want != "" && addr != netip.MustParseAddr(tt.want) {
+				t.Errorf("addr = %s, want %s", addr, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRejectsPrivateAddresses(t *testing.T) {
+	p := newTestPolicy(t, Config{BlockPrivate: true})
+	for _, rawURL := range []string{
+		"http://localhost/",
+		"http://app.localhost/",
+		"http://127.0.0.1/",
+		"http://2130706433/",
+		"http://127.1/",
+		"http://0x7f000001/",
+		"http://017700000001/",
+		"http://0/",
+		"http://10.0.0.1:8080/",
+		"http://192.168.1.1./",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1/",
+		"http://[::1]/",
+		"http://[::ffff:127.0.0.1]/",
+		"http://[fe80::1]/",
+		"http://999.0.0.1/",
+	} {
+		if got := reason(p.Check(context.Background(), rawURL)); got != ReasonPrivateAddress {
+			t.Errorf("Check(%q) reason = %q, want %q", rawURL, got, ReasonPrivateAddress)
+		}
+	}
+}
+
+func TestCheckAllowsPublicAddresses(t *testing.T) {
+	p := newTestPolicy(t, Config{BlockPrivate: true})
+	for _, rawURL := range []string{
+		"https://8.8.8.8/",
+		"https://134744072/",
+		"https://[2606:4700:4700::1111]/",
+		"https://example.com/",
+	} {
+		if err := p.Check(context.Background(), rawURL); err != nil {
+			t.Errorf("Check(%q) = %v, want nil", rawURL, err)
+		}
+	}
+}
+
+func TestCheckRejectsUnresolvedHost(t *testing.T) {
+	p := newTestPolicy(t, Config{BlockPrivate: true, ResolveHosts: true, ResolveTimeout: time.Second})
+	p.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("no network in tests")
+		},
+	}
+	err := p.Check(context.Background(), "https://does-not-resolve.example/")
+	if got := reason(err); got != ReasonUnresolved {
+		t.Errorf("reason = %q, want %q", got, ReasonUnresolved)
+	}
+
+	p = newTestPolicy(t, Config{BlockPrivate: false, ResolveHosts: true, ResolveTimeout: time.Second})
+	p.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("no network in tests")
+		},
+	}
+	if err := p.Check(context.Background(), "https://does-not-resolve.example/"); err != nil {
+		t.Errorf("Check without BlockPrivate = %v, want nil", err)
+	}
+}
+
+func TestCheckSchemeAndLists(t *testing.T) {
+	p := newTestPolicy(t, Config{BlockPrivate: true})
+	if got := reason(p.Check(context.Background(), "javascript:alert(1)")); got != ReasonScheme {
+		t.Errorf("javascript: reason = %q, want %q", got, ReasonScheme)
+	}
+	if got := reason(p.Check(context.Background(), "http:///path")); got != ReasonInvalidURL {
+		t.Errorf("empty host reason = %q, want %q", got, ReasonInvalidURL)
+	}
+
+	if _, err := p.AddDomain(Blocklist, "*.Evil.example."); err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if got := reason(p.Check(context.Background(), "https://cdn.evil.example/x")); got != ReasonBlockedDomain {
+		t.Errorf("subdomain of blocked domain reason = %q, want %q", got, ReasonBlockedDomain)
+	}
+	if _, err := p.AddDomain(Allowlist, "cdn.evil.example"); err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if err := p.Check(context.Background(), "https://cdn.evil.example/x"); err != nil {
+		t.Errorf("allowlisted domain = %v, want nil", err)
+	}
+}
+
+func TestDomainListSaveFailureKeepsList(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lists", "blocklist.txt")
+	p := newTestPolicy(t, Config{BlocklistPath: path})
+
+	if _, err := p.AddDomain(Blocklist, "evil.example"); err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "evil.example\n" {
+		t.Fatalf("list file = %q, %v", data, err)
+	}
+
+	// Make the directory unusable so that the next save fails.
+	if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Dir(path), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.AddDomain(Blocklist, "other.example"); err == nil {
+		t.Fatal("AddDomain succeeded with an unwritable list file")
+	}
+	if err := p.RemoveDomain(Blocklist, "evil.example"); err == nil {
+		t.Fatal("RemoveDomain succeeded with an unwritable list file")
+	}
+	domains, _ := p.Domains(Blocklist)
+	if len(domains) != 1 || domains[0] != "evil.example" {
+		t.Errorf("domains after failed saves = %v, want [evil.example]", domains)
+	}
+}
+
+func TestDomainListKeepsCommentsAndOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	original := "# Blocked domains, one per line.\n" +
+		"\n" +
+		"zeta.example\n" +
+		"# phishing\n" +
+		"Alpha.Example\n" +
+		"not a domain\n" +
+		"mid.example\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := newTestPolicy(t, Config{BlocklistPath: path})
+
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// Adding an existing domain leaves the file alone.
+	if _, err := p.AddDomain(Blocklist, "alpha.example"); err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if got := read(); got != original {
+		t.Errorf("file after adding a listed domain:\n%s\nwant:\n%s", got, original)
+	}
+
+	if _, err := p.AddDomain(Blocklist, "new.example"); err != nil {
+		t.Fatalf("AddDomain: %v", err)
+	}
+	if err := p.RemoveDomain(Blocklist, "zeta.example"); err != nil {
+		t.Fatalf("RemoveDomain: %v", err)
+	}
+	want := "# Blocked domains, one per line.\n" +
+		"\n" +
+		"# phishing\n" +
+		"Alpha.Example\n" +
+		"not a domain\n" +
+		"mid.example\n" +
+		"new.example\n"
+	if got := read(); got != want {
+		t.Errorf("file after add and remove:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Reloading the file gives the same list.
+	reloaded := newTestPolicy(t, Config{BlocklistPath: path})
+	got, _ := reloaded.Domains(Blocklist)
+	before, _ := p.Domains(Blocklist)
+	if len(got) != 3 || len(got) != len(before) {
+		t.Fatalf("reloaded domains = %v, want %v", got, before)
+	}
+	for i := range got {
+		if got[i] != before[i] {
+			t.Errorf("reloaded domains = %v, want %v", got, before)
+			break
+		}
+	}
+}
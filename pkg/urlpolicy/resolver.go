@@ -0,0 +1,169 @@
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Rejection reasons of the redirect resolver.
+const (
+	ReasonRedirectLoop     = "redirect_loop"
+	ReasonTooManyRedirects = "too_many_redirects"
+)
+
+// errPrivateDial is returned by the dialer for non-public addresses.
+var errPrivateDial = errors.New("refusing to connect to a non-public address")
+
+// ResolverConfig configures a Resolver.
+type ResolverConfig struct {
+	MaxHops      int           // redirects to follow before giving up
+	Timeout      time.Duration // timeout for the whole chain
+	AllowPrivate bool          // allow connecting to private addresses, for local testing only
+	SelfHosts    []string      // hosts served by this instance; a hop to them is a loop
+	UserAgent    string
+}
+
+// Resolver follows the redirects of a destination URL to find where it
+// really leads. It connects only to public addresses unless AllowPrivate is
+// set, so a shortened URL cannot be used to probe the internal network.
+type Resolver struct {
+	cfg    ResolverConfig
+	client *http.Client
+	// canDial reports whether an address may be connected to; tests
+	// replace it to treat their local servers as public.
+	canDial func(netip.AddrPort) bool
+}
+
+// NewResolver creates a redirect resolver.
+func NewResolver(cfg ResolverConfig) *Resolver {
+	r := &Resolver{
+		cfg: cfg,
+		canDial: func(addrPort netip.AddrPort) bool {
+			return IsPublicAddr(addrPort.Addr())
+		},
+	}
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivate {
+		// Control runs after name resolution, so it sees the address that is
+		// actually dialled and DNS rebinding does not get around it.
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !r.canDial(addrPort) {
+				return fmt.Errorf("%w: %s", errPrivateDial, address)
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   cfg.Timeout,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+	r.client = &http.Client{
+		Transport: transport,
+		// Redirects are followed by Resolve itself so that every hop is checked.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return r
+}
+
+// Resolve follows the redirects of rawURL and returns the last URL of the
+// chain. Loops, chains through this instance or a non-public address and
+// chains longer than MaxHops are reported as a *Violation. If a hop cannot
+// be fetched for another reason, the last URL reached is returned together
+// with the error.
+func (r *Resolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	current, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, &Violation{Reason: ReasonInvalidURL}
+	}
+
+	seen := make(map[string]bool)
+	for hop := 0; ; hop++ {
+		if r.isSelf(current) {
+			return current.String(), &Violation{Reason: ReasonRedirectLoop, Detail: current.String()}
+		}
+		if seen[current.String()] {
+			return current.String(), &Violation{Reason: ReasonRedirectLoop, Detail: current.String()}
+		}
+		seen[current.String()] = true
+
+		// Only web destinations redirect; anything else is the final hop.
+		if current.Scheme != "http" && current.Scheme != "https" {
+			return current.String(), nil
+		}
+
+		next, err := r.next(ctx, current)
+		if errors.Is(err, errPrivateDial) {
+			return current.String(), &Violation{Reason: ReasonPrivateAddress, Detail: current.String()}
+		}
+		if err != nil {
+			return current.String(), err
+		}
+		if next == nil {
+			return current.String(), nil
+		}
+		if hop >= r.cfg.MaxHops {
+			return current.String(), &Violation{Reason: ReasonTooManyRedirects, Detail: fmt.Sprintf("more than %d", r.cfg.MaxHops)}
+		}
+		current = next
+	}
+}
+
+// next fetches u and returns the redirect target, or nil if u does not redirect.
+func (r *Resolver) next(ctx context.Context, u *url.URL) (*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", r.cfg.UserAgent)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", u.Redacted(), err)
+	}
+	// The body is never needed, only the status and the Location header.
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return nil, nil
+	}
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, nil
+	}
+	next, err := u.Parse(location)
+	if err != nil {
+		return nil, &Violation{Reason: ReasonInvalidURL, Detail: location}
+	}
+	return next, nil
+}
+
+// isSelf reports whether u points at this instance.
+func (r *Resolver) isSelf(u *url.URL) bool {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return host != "" && slices.Contains(r.cfg.SelfHosts, host)
+}
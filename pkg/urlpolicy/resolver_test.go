@@ -0,0 +1,178 @@
+package urlpolicy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestResolver returns a resolver that treats the given servers as
+// public addresses and every other address as private.
+func newTestResolver(cfg ResolverConfig, public ...*httptest.Server) *Resolver {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	r := NewResolver(cfg)
+	allowed := make(map[netip.AddrPort]bool)
+	for _, srv := range public {
+		allowed[netip.MustParseAddrPort(srv.Listener.Addr().String())] = true
+	}
+	r.canDial = func(addrPort netip.AddrPort) bool {
+		return allowed[addrPort]
+	}
+	return r
+}
+
+// redirectTo answers every request with a redirect to target.
+func redirectTo(target string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
+
+func TestResolveFollowsRedirects(t *testing.T) {
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer final.Close()
+	first := httptest.NewServer(redirectTo(final.URL + "/landing"))
+	defer first.Close()
+
+	r := newTestResolver(ResolverConfig{MaxHops: 5}, first, final)
+	got, err := r.Resolve(context.Background(), first.URL+"/start")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if want := final.URL + "/landing"; got != want {
+		t.Errorf("Resolve = %q, want %q", got, want)
+	}
+}
+
+func TestResolveHopLimit(t *testing.T) {
+	// /n redirects to /n+1 forever.
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Path[1:])
+		http.Redirect(w, r, srv.URL+"/"+strconv.Itoa(n+1), http.StatusMovedPermanently)
+	}))
+	defer srv.Close()
+
+	r := newTestResolver(ResolverConfig{MaxHops: 3}, srv)
+	_, err := r.Resolve(context.Background(), srv.URL+"/0")
+	if got := reason(err); got != ReasonTooManyRedirects {
+		t.Errorf("reason = %q, want %q (err %v)", got, ReasonTooManyRedirects, err)
+	}
+
+	// A chain of exactly MaxHops redirects is fine.
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n, _ := strconv.Atoi(r.URL.Path[1:]); n < 3 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+		}
+	}))
+	defer final.Close()
+	r = newTestResolver(ResolverConfig{MaxHops: 3}, final)
+	got, err := r.Resolve(context.Background(), final.URL+"/0")
+	if err != nil || got != final.URL+"/3" {
+		t.Errorf("Resolve = %q, %v, want %q", got, err, final.URL+"/3")
+	}
+}
+
+func TestResolveLoop(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/a", redirectTo("/b"))
+	mux.Handle("/b", redirectTo("/a"))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := newTestResolver(ResolverConfig{MaxHops: 10}, srv)
+	_, err := r.Resolve(context.Background(), srv.URL+"/a")
+	if got := reason(err); got != ReasonRedirectLoop {
+		t.Errorf("reason = %q, want %q (err %v)", got, ReasonRedirectLoop, err)
+	}
+}
+
+func TestResolveRedirectToPrivateAddress(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the resolver connected to a private address")
+	}))
+	defer internal.Close()
+	srv := httptest.NewServer(redirectTo(internal.URL + "/admin"))
+	defer srv.Close()
+
+	r := newTestResolver(ResolverConfig{MaxHops: 5}, srv)
+	got, err := r.Resolve(context.Background(), srv.URL)
+	if reason(err) != ReasonPrivateAddress {
+		t.Fatalf("Resolve error = %v, want %s", err, ReasonPrivateAddress)
+	}
+	if want := internal.URL + "/admin"; got != want {
+		t.Errorf("Resolve = %q, want %q", got, want)
+	}
+}
+
+func TestResolveSelfHost(t *testing.T) {
+	srv := httptest.NewServer(redirectTo("https://sho.rt/abc123"))
+	defer srv.Close()
+
+	r := newTestResolver(ResolverConfig{MaxHops: 5, SelfHosts: []string{"sho.rt"}}, srv)
+	_, err := r.Resolve(context.Background(), srv.URL)
+	if got := reason(err); got != ReasonRedirectLoop {
+		t.Errorf("reason = %q, want %q (err %v)", got, ReasonRedirectLoop, err)
+	}
+
+	// The start URL itself is checked too.
+	_, err = r.Resolve(context.Background(), "http://SHO.RT./x")
+	if got := reason(err); got != ReasonRedirectLoop {
+		t.Errorf("start URL reason = %q, want %q (err %v)", got, ReasonRedirectLoop, err)
+	}
+}
+
+func TestResolveTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	r := newTestResolver(ResolverConfig{MaxHops: 5, Timeout: 100 * time.Millisecond}, srv)
+	start := time.Now()
+	got, err := r.Resolve(context.Background(), srv.URL+"/slow")
+	if err == nil {
+		t.Fatal("Resolve succeeded against a server that never answers")
+	}
+	var v *Violation
+	if errors.As(err, &v) {
+		t.Errorf("a timeout is not a policy violation, got %v", v)
+	}
+	var uerr *url.Error
+	if !errors.As(err, &uerr) || !uerr.Timeout() {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+	if got != srv.URL+"/slow" {
+		t.Errorf("Resolve = %q, want the last URL reached", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Resolve took %s with a 100ms timeout", elapsed)
+	}
+}
+
+func TestCheckSelfHost(t *testing.T) {
+	p := newTestPolicy(t, Config{SelfHosts: []string{"sho.rt"}})
+	for _, rawURL := range []string{"https://sho.rt/abc", "http://SHO.RT.:8080/abc"} {
+		if got := reason(p.Check(context.Background(), rawURL)); got != ReasonSelfLink {
+			t.Errorf("Check(%q) reason = %q, want %q", rawURL, got, ReasonSelfLink)
+		}
+	}
+	if err := p.Check(context.Background(), "https://www.sho.rt/abc"); err != nil {
+		t.Errorf("Check of another host = %v, want nil", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package useragent extracts the operating system and device class from a
+// User-Agent header. It is a small heuristic parser meant for redirect
+// targeting, not a full browser detection library.
+package useragent
+
+import "strings"
+
+// Operating systems.
+const (
+	OSiOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSChromeOS = "chromeos"
+	OSLinux    = "linux"
+	OSOther    = "other"
+)
+
+// Device classes.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceBot     = "bot"
+)
+
+// Info is what Parse learns from a User-Agent.
+type Info struct {
+	OS     string
+	Device string
+}
+
+// botMarkers are substrings of crawler and preview fetcher User-Agents.
+var botMarkers = []string{"bot", "crawler", "spider", "slurp", "facebookexternalhit", "preview", "curl", "wget"}
+
+// Parse returns the operating system and device class of a User-Agent.
+// Unknown values are reported as OSOther and DeviceDesktop.
+func Parse(ua string) Info {
+	s := strings.ToLower(ua)
+	info := Info{OS: parseOS(s), Device: DeviceDesktop}
+	switch {
+	case s == "" || containsAny(s, botMarkers...):
+		info.Device = DeviceBot
+	case containsAny(s, "ipad", "tablet") || (info.OS == OSAndroid && !strings.Contains(s, "mobile")):
+		info.Device = DeviceTablet
+	case containsAny(s, "mobile", "iphone", "ipod") || info.OS == OSAndroid:
+		info.Device = DeviceMobile
+	}
+	return info
+}
+
+// parseOS detects the operating system from a lowercased User-Agent.
+// Order matters: iOS and Android agents also mention "like Mac OS X" and "Linux".
+func parseOS(s string) string {
+	switch {
+	case containsAny(s, "iphone", "ipad", "ipod"):
+		return OSiOS
+	case strings.Contains(s, "android"):
+		return OSAndroid
+	case strings.Contains(s, "windows"):
+		return OSWindows
+	case strings.Contains(s, "cros "):
+		return OSChromeOS
+	case containsAny(s, "macintosh", "mac os x"):
+		return OSMacOS
+	case strings.Contains(s, "linux"):
+		return OSLinux
+	}
+	return OSOther
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+// Package view рендерит HTML-страницы из наборов шаблонов.
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/pkg/logger"
+)
+
+// ErrUnknownPage возвращается при рендеринге страницы без шаблона.
+var ErrUnknownPage = errors.New("unknown page")
+
+// Options - настройки Renderer.
+type Options struct {
+	// Layouts - общие файлы, входящие в набор каждой страницы. Первый из них
+	// - корневой шаблон, который выполняется при рендеринге.
+	Layouts []string
+	Funcs   template.FuncMap
+}
+
+// Renderer хранит наборы шаблонов, разобранные один раз при старте: на каждую
+// страницу свой набор из общих файлов и файла страницы, определяющего
+// шаблон "content". Имя страницы - имя её файла без расширения.
+type Renderer struct {
+	fsys fs.FS
+	opts Options
+
+	mu    sync.RWMutex
+	pages map[string]*template.Template
+	stamp string // отпечаток файлов на момент последнего разбора
+}
+
+// New разбирает шаблоны из fsys.
+func New(fsys fs.FS, opts Options) (*Renderer, error) {
+	if len(opts.Layouts) == 0 {
+		return nil, errors.New("view: no layouts")
+	}
+	r := &Renderer{fsys: fsys, opts: opts}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Render выполняет корневой шаблон страницы page.
+func (r *Renderer) Render(w io.Writer, page string, data any) error {
+	r.mu.RLock()
+	tmpl, ok := r.pages[page]
+	r.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownPage, page)
+	}
+	return tmpl.ExecuteTemplate(w, r.opts.Layouts[0], data)
+}
+
+// Watch раз в interval проверяет файлы шаблонов и перечитывает их при
+// изменении, пока не отменён ctx. Если новые шаблоны не разбираются,
+// ошибка логируется и остаются прежние. Предназначен для режима разработки.
+func (r *Renderer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamp, err := r.fingerprint()
+			if err != nil {
+				logger.Error("Не удалось проверить шаблоны", zap.Error(err))
+				continue
+			}
+			r.mu.RLock()
+			changed := stamp != r.stamp
+			r.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := r.load(); err != nil {
+				logger.Error("Шаблоны не перезагружены", zap.Error(err))
+				continue
+			}
+			logger.Info("Шаблоны перезагружены")
+		}
+	}
+}
+
+// load разбирает все наборы шаблонов и заменяет ими текущие.
+func (r *Renderer) load() error {
+	stamp, err := r.fingerprint()
+	if err != nil {
+		return err
+	}
+	files, err := fs.Glob(r.fsys, "*.html")
+	if err != nil {
+		return fmt.Errorf("view: %w", err)
+	}
+
+	pages := make(map[string]*template.Template)
+	for _, file := range files {
+		if slices.Contains(r.opts.Layouts, file) {
+			continue
+		}
+		patterns := append(slices.Clone(r.opts.Layouts), file)
+		tmpl, err := template.New(r.opts.Layouts[0]).Funcs(r.opts.Funcs).ParseFS(r.fsys, patterns...)
+		if err != nil {
+			return fmt.Errorf("view: %s: %w", file, err)
+		}
+		pages[strings.TrimSuffix(file, ".html")] = tmpl
+	}
+
+	r.mu.Lock()
+	r.pages = pages
+	r.stamp = stamp
+	r.mu.Unlock()
+	return nil
+}
+
+// fingerprint возвращает строку, меняющуюся при изменении любого шаблона.
+func (r *Renderer) fingerprint() (string, error) {
+	var b strings.Builder
+	err := fs.WalkDir(r.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("view: %w", err)
+	}
+	return b.String(), nil
+}
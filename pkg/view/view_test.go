@@ -0,0 +1,144 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go.uber.org/zap"
+
+	"shorty/pkg/logger"
+)
+
+func init() {
+	logger.Logger = zap.NewNop()
+}
+
+// syncFS lets a test replace files while Watch reads them.
+type syncFS struct {
+	mu   sync.Mutex
+	fsys fstest.MapFS
+}
+
+func (s *syncFS) Open(name string) (fs.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fsys.Open(name)
+}
+
+func (s *syncFS) write(name, data string, modTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fsys[name] = &fstest.MapFile{Data: []byte(data), ModTime: modTime}
+}
+
+func testTemplates() fstest.MapFS {
+	return fstest.MapFS{
+		"layout.html": {Data: []byte(`{{ define "layout.html" }}<title>{{ block "title" . }}default{{ end }}</title>{{ template "nav" . }}|{{ template "content" . }}{{ end }}`)},
+		"nav.html":    {Data: []byte(`{{ define "nav" }}<nav>{{ upper .Name }}</nav>{{ end }}`)},
+		"home.html":   {Data: []byte(`{{ define "title" }}Home{{ end }}{{ define "content" }}home {{ .Name }}{{ end }}`)},
+		"about.html":  {Data: []byte(`{{ define "content" }}about{{ end }}`)},
+	}
+}
+
+func newTestRenderer(t *testing.T, fsys fs.FS) *Renderer {
+	t.Helper()
+	r, err := New(fsys, Options{
+		Layouts: []string{"layout.html", "nav.html"},
+		Funcs:   template.FuncMap{"upper": strings.ToUpper},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return r
+}
+
+func render(t *testing.T, r *Renderer, page string) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.Render(&b, page, struct{ Name string }{"ann"}); err != nil {
+		t.Fatalf("Render(%s): %v", page, err)
+	}
+	return b.String()
+}
+
+func TestRenderPages(t *testing.T) {
+	r := newTestRenderer(t, testTemplates())
+
+	// Each page has its own "content" and "title"; one page does not leak into another.
+	tests := []struct {
+		page string
+		want string
+	}{
+		{page: "home", want: "<title>Home</title><nav>ANN</nav>|home ann"},
+		{page: "about", want: "<title>default</title><nav>ANN</nav>|about"},
+	}
+	for _, tt := range tests {
+		if got := render(t, r, tt.page); got != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.page, got, tt.want)
+		}
+	}
+
+	for _, page := range []string{"layout", "nav", "missing"} {
+		if err := r.Render(&strings.Builder{}, page, nil); !errors.Is(err, ErrUnknownPage) {
+			t.Errorf("Render(%s) = %v, want ErrUnknownPage", page, err)
+		}
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(testTemplates(), Options{}); err == nil {
+		t.Error("New without layouts succeeded")
+	}
+
+	broken := testTemplates()
+	broken["bad.html"] = &fstest.MapFile{Data: []byte(`{{ define "content" }}{{ .Name `)}
+	if _, err := New(broken, Options{Layouts: []string{"layout.html", "nav.html"}, Funcs: template.FuncMap{"upper": strings.ToUpper}}); err == nil || !strings.Contains(err.Error(), "bad.html") {
+		t.Errorf("New with a broken page = %v, want an error naming bad.html", err)
+	}
+}
+
+func TestWatchReloads(t *testing.T) {
+	fsys := &syncFS{fsys: testTemplates()}
+	r := newTestRenderer(t, fsys)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, time.Millisecond)
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if got := render(t, r, "about"); got == want {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("Render(about) = %q, want %q", render(t, r, "about"), want)
+	}
+
+	modTime := time.Now()
+	fsys.write("about.html", `{{ define "content" }}about us{{ end }}`, modTime)
+	waitFor("<title>default</title><nav>ANN</nav>|about us")
+
+	// A template that does not parse keeps the previous set.
+	fsys.write("about.html", `{{ define "content" }}{{ .Name `, modTime.Add(time.Second))
+	time.Sleep(20 * time.Millisecond)
+	if got := render(t, r, "about"); got != "<title>default</title><nav>ANN</nav>|about us" {
+		t.Errorf("Render(about) after a broken edit = %q", got)
+	}
+
+	// Layout changes apply to every page.
+	fsys.write("nav.html", `{{ define "nav" }}<nav>{{ .Name }}</nav>{{ end }}`, modTime.Add(2*time.Second))
+	fsys.write("about.html", `{{ define "content" }}about{{ end }}`, modTime.Add(2*time.Second))
+	waitFor("<title>default</title><nav>ann</nav>|about")
+	if got := render(t, r, "home"); got != "<title>Home</title><nav>ann</nav>|home ann" {
+		t.Errorf("Render(home) after the layout change = %q", got)
+	}
+}
@@ -0,0 +1,30 @@
+// Package web содержит шаблоны и статические файлы веб-интерфейса,
+// встроенные в бинарник.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed templates static
+var files embed.FS
+
+// Templates возвращает встроенные шаблоны страниц.
+func Templates() fs.FS {
+	return sub("templates")
+}
+
+// Static возвращает встроенные статические файлы.
+func Static() fs.FS {
+	return sub("static")
+}
+
+func sub(dir string) fs.FS {
+	fsys, err := fs.Sub(files, dir)
+	if err != nil {
+		// Каталоги встроены при сборке, ошибка здесь - ошибка программиста.
+		panic(err)
+	}
+	return fsys
+}
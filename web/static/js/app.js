@@ -1,54 +1,38 @@
-// ======= JWT парсинг из localStorage =======
-function parseJwt(token) {
-  try {
-    const payload = token.split(".")[1];
-    const json = atob(payload.replace(/-/g, "+").replace(/_/g, "/"));
-    return JSON.parse(
-      decodeURIComponent(
-        json
-          .split("")
-          .map((c) => "%" + ("00" + c.charCodeAt(0).toString(16)).slice(-2))
-          .join(""),
-      ),
-    );
-  } catch (e) {
-    console.error("[parseJwt] Invalid token", e);
-    return null;
-  }
+// ======= CSRF-токен cookie-сессии =======
+// Сервер кладёт токен в <meta name="csrf-token">, если пользователь вошёл.
+function csrfHeaders() {
+  const meta = document.querySelector('meta[name="csrf-token"]');
+  return meta ? { "X-CSRF-Token": meta.content } : {};
 }
 
-// ======= Отображение кнопок в шапке =======
-function setupAuthButtons() {
-  const authContainer = document.querySelector(".header-auth");
-  if (!authContainer) return;
-
-  const token = localStorage.getItem("jwt");
-  if (token) {
-    const claims = parseJwt(token);
-    if (claims?.role === "admin") {
-      authContainer.innerHTML = `
-        <a href="/stats" class="header-auth_link--l">Статистика</a>
-        <a href="/settings" class="header-auth_link--l">Настройки</a>
-        <a href="#" id="logout-btn" class="header-auth_link--r">Выйти</a>
-      `;
-    } else {
-      authContainer.innerHTML = `
-        <a href="#" id="logout-btn" class="header-auth_link--r">Выйти</a>
-      `;
-    }
-    document.getElementById("logout-btn").onclick = (e) => {
-      e.preventDefault();
-      localStorage.removeItem("jwt");
-      document.cookie = `jwt=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; SameSite=Lax`;
+// ======= Язык страницы =======
+// Сообщения об ошибках API уже переведены сервером, здесь - только запасные тексты.
+function localized(ru, en) {
+  return document.documentElement.lang === "en" ? en : ru;
+}
 
-      window.location.href = "/";
-    };
-  } else {
-    authContainer.innerHTML = `
-      <a href="/signin" class="header-auth_link--l">Войти</a>
-      <a href="/signup" class="header-auth_link--r">Регистрация</a>
-    `;
-  }
+// Ошибки API приходят в формате RFC 7807; ошибка поля точнее общего текста.
+function problemMessage(problem) {
+  const field = problem.errors && problem.errors[0];
+  return field ? `${problem.detail}: ${field.message}` : problem.detail;
+}
+
+// ======= Адрес возврата после входа =======
+function nextLocation() {
+  const next = new URLSearchParams(window.location.search).get("next");
+  // Разрешаем только относительные пути, чтобы не было open redirect.
+  return next && next.startsWith("/") && !next.startsWith("//") ? next : "/";
+}
+
+// ======= Выход =======
+function initLogoutForm() {
+  const form = document.getElementById("logout-form");
+  if (!form) return;
+
+  // Сессию завершает сервер, здесь чистим токен API.
+  form.addEventListener("submit", () => {
+    localStorage.removeItem("jwt");
+  });
 }
 
 // ======= Обработка формы входа =======
@@ -66,22 +50,21 @@ function initSigninForm() {
     try {
       const res = await fetch("/auth/signin", {
         method: "POST",
-        headers: { "Content-Type": "application/json" },
+        headers: { "Content-Type": "application/json", ...csrfHeaders() },
         body: JSON.stringify(data),
       });
       const json = await res.json();
       if (!res.ok) {
-        alert(json.error || "Ошибка авторизации");
+        alert(json.detail || localized("Ошибка авторизации", "Sign-in failed"));
         return;
       }
-      // 1) Сохраняем в localStorage
+      // Токен для API храним в localStorage, сессию веб-интерфейса
+      // сервер выставил в HttpOnly cookie.
       localStorage.setItem("jwt", json.token);
-      // 2) Ставим cookie, чтобы сервер мог его прочесть
-      document.cookie = `jwt=${json.token}; Path=/; SameSite=Lax`;
-      window.location.href = "/";
+      window.location.href = nextLocation();
     } catch (err) {
       console.error(err);
-      alert("Сетевая ошибка");
+      alert(localized("Сетевая ошибка", "Network error"));
     }
   });
 }
@@ -103,22 +86,21 @@ function initSignupForm() {
     try {
       const res = await fetch("/auth/signup", {
         method: "POST",
-        headers: { "Content-Type": "application/json" },
+        headers: { "Content-Type": "application/json", ...csrfHeaders() },
         body: JSON.stringify(data),
       });
       const json = await res.json();
       if (!res.ok) {
-        alert(json.error || "Ошибка регистрации");
+        alert(json.detail || localized("Ошибка регистрации", "Sign-up failed"));
         return;
       }
-      // 1) Сохраняем в localStorage
+      // Токен для API храним в localStorage, сессию веб-интерфейса
+      // сервер выставил в HttpOnly cookie.
       localStorage.setItem("jwt", json.token);
-      // 2) Ставим cookie для сервера
-      document.cookie = `jwt=${json.token}; Path=/; SameSite=Lax`;
       window.location.href = "/";
     } catch (err) {
       console.error(err);
-      alert("Сетевая ошибка");
+      alert(localized("Сетевая ошибка", "Network error"));
     }
   });
 }
@@ -134,7 +116,7 @@ function initShortenForm() {
     const urlValue = form.url.value.trim();
     if (!urlValue) return;
 
-    result.textContent = "Сокращаем…";
+    result.textContent = localized("Сокращаем…", "Shortening…");
 
     try {
       const token = localStorage.getItem("jwt");
@@ -142,13 +124,13 @@ function initShortenForm() {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
-          Authorization: `Bearer ${token}`,
+          ...(token ? { Authorization: `Bearer ${token}` } : csrfHeaders()),
         },
         body: JSON.stringify({ url: urlValue }),
       });
       const json = await res.json();
       if (!res.ok) {
-        result.textContent = json.error || "Ошибка";
+        result.textContent = problemMessage(json) || localized("Ошибка", "Error");
         return;
       }
 
@@ -156,7 +138,7 @@ function initShortenForm() {
       const shortLink = `/${hash}`;
 
       // Очищаем контейнер
-      result.textContent = "Короткая ссылка: ";
+      result.textContent = localized("Короткая ссылка: ", "Short link: ");
 
       // Создаём <a> через DOM API
       const a = document.createElement("a");
@@ -170,15 +152,39 @@ function initShortenForm() {
       form.url.value = "";
     } catch (err) {
       console.error(err);
-      result.textContent = "Сетевая ошибка";
+      result.textContent = localized("Сетевая ошибка", "Network error");
     }
   });
 }
 
 // ======= Запуск после загрузки =======
+// ======= Страница управления ссылками =======
+function initDashboard() {
+  document.querySelectorAll("[data-copy]").forEach((btn) => {
+    btn.addEventListener("click", async () => {
+      try {
+        await navigator.clipboard.writeText(btn.dataset.copy);
+        const label = btn.textContent;
+        btn.textContent = localized("Скопировано", "Copied");
+        setTimeout(() => (btn.textContent = label), 1500);
+      } catch (err) {
+        console.error(err);
+        alert(localized("Не удалось скопировать ссылку", "Could not copy the link"));
+      }
+    });
+  });
+
+  document.querySelectorAll("form[data-confirm]").forEach((form) => {
+    form.addEventListener("submit", (e) => {
+      if (!confirm(form.dataset.confirm)) e.preventDefault();
+    });
+  });
+}
+
 document.addEventListener("DOMContentLoaded", () => {
-  setupAuthButtons();
+  initLogoutForm();
   initSigninForm();
   initSignupForm();
   initShortenForm();
+  initDashboard();
 });